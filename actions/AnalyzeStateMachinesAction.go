@@ -0,0 +1,144 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/abenstex/laniakea/dataStructures"
+	"github.com/abenstex/laniakea/micro"
+	utils2 "github.com/abenstex/laniakea/utils"
+	"github.com/abenstex/orion.commons/app"
+	http2 "github.com/abenstex/orion.commons/http"
+	structs2 "github.com/abenstex/orion.commons/structs"
+	"github.com/abenstex/orion.commons/utils"
+	"net/http"
+	"orion.misc/structs"
+	"time"
+)
+
+type AnalyzeStateMachinesAction struct {
+	baseAction      micro.BaseAction
+	MetricsStore    *utils.MetricsStore
+	receivedRequest structs.AnalyzeStateMachinesRequest
+}
+
+func (action *AnalyzeStateMachinesAction) BeforeAction(ctx context.Context, request []byte) *micro.Exception {
+	dummy := structs.AnalyzeStateMachinesRequest{}
+	err := json.Unmarshal(request, &dummy)
+	if err != nil {
+		return micro.NewException(structs2.UnmarshalError, err)
+	}
+	err = app.DefaultHandleActionRequest(request, &dummy.Header, action, true)
+	if err != nil {
+		return micro.NewException(structs2.RequestHeaderInvalid, err)
+	}
+
+	return nil
+}
+
+func (action AnalyzeStateMachinesAction) BeforeActionAsync(ctx context.Context, request []byte) {
+
+}
+
+func (action AnalyzeStateMachinesAction) AfterAction(ctx context.Context, reply *micro.IReply, request *micro.IRequest) *micro.Exception {
+	return nil
+}
+
+func (action AnalyzeStateMachinesAction) AfterActionAsync(ctx context.Context, reply micro.IReply, request micro.IRequest) {
+
+}
+
+func (action AnalyzeStateMachinesAction) GetBaseAction() micro.BaseAction {
+	return action.baseAction
+}
+
+func (action *AnalyzeStateMachinesAction) SetHttpRequest(request *http.Request) {
+	action.baseAction.Request = request
+}
+
+func (action *AnalyzeStateMachinesAction) InitBaseAction(baseAction micro.BaseAction) {
+	action.baseAction = baseAction
+}
+
+func (action AnalyzeStateMachinesAction) SendEvents(request micro.IRequest) {
+
+}
+
+func (action AnalyzeStateMachinesAction) ProvideInformation() micro.ActionInformation {
+	var reply = "orion/server/misc/reply/state/analyze"
+	var error = "orion/server/misc/error/state/analyze"
+	var requestSample = dataStructures.StructToJsonString(structs.AnalyzeStateMachinesRequest{})
+	var replySample = dataStructures.StructToJsonString(structs.AnalyzeStateMachinesReply{})
+	info := micro.ActionInformation{
+		Name:            "AnalyzeStateMachinesAction",
+		Description:     "Reports unreachable, dead-end and dangling states as well as missing or ambiguous default states per referenced type",
+		RequestTopic:    "orion/server/misc/request/state/analyze",
+		ReplyTopic:      reply,
+		ErrorReplyTopic: error,
+		Version:         1,
+		ClientId:        action.baseAction.ID.String(),
+		HttpMethods:     []string{http.MethodPost, "OPTIONS"},
+		RequestSample:   &requestSample,
+		ReplySample:     &replySample,
+		IsScriptable:    false,
+	}
+
+	return info
+}
+
+func (action *AnalyzeStateMachinesAction) HandleWebRequest(writer http.ResponseWriter, request *http.Request) {
+	action.SetHttpRequest(request)
+	http2.HandleHttpRequest(writer, request, action)
+}
+
+func (action *AnalyzeStateMachinesAction) HeyHo(ctx context.Context, request []byte) (micro.IReply, micro.IRequest) {
+	start := time.Now()
+	defer action.MetricsStore.HandleActionMetric(start, action.GetBaseAction().Environment, action.ProvideInformation(), *action.baseAction.Token)
+
+	err := json.Unmarshal(request, &action.receivedRequest)
+	if err != nil {
+		return structs2.NewErrorReplyHeaderWithException(micro.NewException(structs2.UnmarshalError, err),
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	reply, myErr := action.analyze(ctx, action.receivedRequest)
+	if myErr != nil {
+		return structs2.NewErrorReplyHeaderWithOrionErr(myErr,
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	return reply, &action.receivedRequest
+}
+
+func (action AnalyzeStateMachinesAction) analyze(ctx context.Context, request structs.AnalyzeStateMachinesRequest) (structs.AnalyzeStateMachinesReply, *structs2.OrionError) {
+	definitions, myErr := loadAllStateMachineDefinitions(ctx, action.baseAction)
+	if myErr != nil {
+		return structs.AnalyzeStateMachinesReply{}, myErr
+	}
+
+	requestedTypes := make(map[string]bool, len(request.ReferencedTypes))
+	for _, referencedType := range request.ReferencedTypes {
+		requestedTypes[referencedType] = true
+	}
+	analyses := make([]structs.StateMachineAnalysis, 0, len(definitions))
+	for _, definition := range definitions {
+		if len(requestedTypes) > 0 && !requestedTypes[definition.ReferencedType] {
+			continue
+		}
+		analyses = append(analyses, definition.Analyze())
+	}
+
+	var reply = structs.AnalyzeStateMachinesReply{}
+	reply.Header = structs2.NewReplyHeader(action.ProvideInformation().ReplyTopic)
+	reply.Header.Timestamp = utils2.GetCurrentTimeStamp()
+	if len(analyses) == 0 {
+		errorMsg := "No state machines were found"
+		reply.Header.ErrorMessage = &errorMsg
+
+		return reply, structs2.NewOrionError(structs2.NoDataFound, errors.New(errorMsg))
+	}
+	reply.Header.Success = true
+	reply.Analyses = analyses
+
+	return reply, nil
+}
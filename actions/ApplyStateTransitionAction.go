@@ -0,0 +1,172 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/abenstex/laniakea/dataStructures"
+	"github.com/abenstex/laniakea/logging"
+	"github.com/abenstex/laniakea/micro"
+	utils2 "github.com/abenstex/laniakea/utils"
+	"github.com/abenstex/orion.commons/app"
+	http2 "github.com/abenstex/orion.commons/http"
+	structs2 "github.com/abenstex/orion.commons/structs"
+	"github.com/abenstex/orion.commons/utils"
+	"github.com/spf13/viper"
+	"net/http"
+	"orion.misc/statemachine"
+	"orion.misc/structs"
+	"time"
+)
+
+type ApplyStateTransitionAction struct {
+	baseAction        micro.BaseAction
+	MetricsStore      *utils.MetricsStore
+	receivedRequest   structs.ApplyStateTransitionRequest
+	appliedTransition *structs.ObjectStateTransition
+}
+
+func (action *ApplyStateTransitionAction) BeforeAction(ctx context.Context, request []byte) *micro.Exception {
+	dummy := structs.ApplyStateTransitionRequest{}
+	err := json.Unmarshal(request, &dummy)
+	if err != nil {
+		return micro.NewException(structs2.UnmarshalError, err)
+	}
+	err = app.DefaultHandleActionRequest(request, &dummy.Header, action, true)
+	if err != nil {
+		return micro.NewException(structs2.RequestHeaderInvalid, err)
+	}
+	if len(dummy.ObjectType) == 0 || len(dummy.ObjectId) == 0 || len(dummy.TargetState) == 0 {
+		return micro.NewException(structs2.MissingParameterError,
+			fmt.Errorf("not all parameters (object_type, object_id and target_state) were provided"))
+	}
+
+	return nil
+}
+
+func (action ApplyStateTransitionAction) BeforeActionAsync(ctx context.Context, request []byte) {
+
+}
+
+func (action ApplyStateTransitionAction) AfterAction(ctx context.Context, reply *micro.IReply, request *micro.IRequest) *micro.Exception {
+	return nil
+}
+
+func (action ApplyStateTransitionAction) AfterActionAsync(ctx context.Context, reply micro.IReply, request micro.IRequest) {
+
+}
+
+func (action ApplyStateTransitionAction) GetBaseAction() micro.BaseAction {
+	return action.baseAction
+}
+
+func (action *ApplyStateTransitionAction) SetHttpRequest(request *http.Request) {
+	action.baseAction.Request = request
+}
+
+func (action *ApplyStateTransitionAction) InitBaseAction(baseAction micro.BaseAction) {
+	action.baseAction = baseAction
+}
+
+func (action ApplyStateTransitionAction) SendEvents(request micro.IRequest) {
+	applyRequest := request.(*structs.ApplyStateTransitionRequest)
+	if !applyRequest.Header.WasExecutedSuccessfully || action.appliedTransition == nil {
+		logging.GetLogger("ApplyStateTransitionAction",
+			action.GetBaseAction().Environment,
+			true).Warn("RequestFailedEvent will be sent because the request was not successfully executed")
+		blerghEvent := structs2.NewRequestFailedEvent(applyRequest, action.ProvideInformation(), action.baseAction.ID.String(), "")
+		blerghEvent.Send(action.ProvideInformation().ErrorReplyTopic, byte(viper.GetInt("messageBus.publishEventQos")),
+			utils.GetDefaultMqttConnectionOptionsWithIdPrefix(action.ProvideInformation().Name))
+		return
+	}
+
+	sendStateChangedEvent(action.ProvideInformation(), applyRequest.Header.SenderId, action.baseAction.Environment, *action.appliedTransition)
+}
+
+func (action ApplyStateTransitionAction) ProvideInformation() micro.ActionInformation {
+	var reply = "orion/server/misc/reply/state/transition/apply"
+	var error = "orion/server/misc/error/state/transition/apply"
+	var requestSample = dataStructures.StructToJsonString(structs.ApplyStateTransitionRequest{})
+	var replySample = dataStructures.StructToJsonString(structs.ApplyStateTransitionReply{})
+	var eventSample = dataStructures.StructToJsonString(structs.StateChangedEvent{})
+	info := micro.ActionInformation{
+		Name:            "ApplyStateTransitionAction",
+		Description:     "Moves an object into a new state if the state transition rules allow it and records the transition",
+		RequestTopic:    "orion/server/misc/request/state/transition/apply",
+		ReplyTopic:      reply,
+		ErrorReplyTopic: error,
+		Version:         1,
+		ClientId:        action.baseAction.ID.String(),
+		HttpMethods:     []string{http.MethodPost, "OPTIONS"},
+		EventTopic:      StateChangedEventTopic,
+		RequestSample:   &requestSample,
+		ReplySample:     &replySample,
+		EventSample:     &eventSample,
+		IsScriptable:    true,
+	}
+
+	return info
+}
+
+func (action *ApplyStateTransitionAction) HandleWebRequest(writer http.ResponseWriter, request *http.Request) {
+	action.SetHttpRequest(request)
+	http2.HandleHttpRequest(writer, request, action)
+}
+
+func (action *ApplyStateTransitionAction) HeyHo(ctx context.Context, request []byte) (micro.IReply, micro.IRequest) {
+	start := time.Now()
+	defer action.MetricsStore.HandleActionMetric(start, action.GetBaseAction().Environment, action.ProvideInformation(), *action.baseAction.Token)
+	action.appliedTransition = nil
+
+	err := json.Unmarshal(request, &action.receivedRequest)
+	if err != nil {
+		return structs2.NewErrorReplyHeaderWithException(micro.NewException(structs2.UnmarshalError, err),
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	reply, myErr := action.applyTransition(ctx, action.receivedRequest)
+	if myErr != nil {
+		return structs2.NewErrorReplyHeaderWithOrionErr(myErr,
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	return reply, &action.receivedRequest
+}
+
+func (action *ApplyStateTransitionAction) applyTransition(ctx context.Context, request structs.ApplyStateTransitionRequest) (structs.ApplyStateTransitionReply, *structs2.OrionError) {
+	userRoles, myErr := getUserRoles(ctx, action.baseAction, request.Header.User)
+	if myErr != nil {
+		return structs.ApplyStateTransitionReply{}, myErr
+	}
+	transition, decision, myErr := applyObjectStateTransition(ctx, action.baseAction, request.ObjectType, request.ObjectId,
+		statemachine.TransitionInput{
+			TargetState: request.TargetState,
+			Object:      request.Object,
+			User:        request.Header.User,
+			UserRoles:   userRoles,
+			Comment:     request.Header.Comment,
+		}, utils2.GetCurrentTimeStamp())
+	if myErr != nil {
+		return structs.ApplyStateTransitionReply{}, myErr
+	}
+
+	var reply = structs.ApplyStateTransitionReply{}
+	reply.Header = structs2.NewReplyHeader(action.ProvideInformation().ReplyTopic)
+	reply.Header.Timestamp = utils2.GetCurrentTimeStamp()
+	reply.Allowed = decision.Allowed
+	reply.ReasonCode = decision.ReasonCode
+	reply.Reason = decision.Reason
+	reply.FailedGuards = decision.FailedGuards
+	if !decision.Allowed {
+		reply.Header = structs2.NewErrorReplyHeaderWithOrionErr(structs2.NewOrionError(structs.ValidationError, errors.New(decision.Reason)),
+			action.ProvideInformation().ErrorReplyTopic)
+
+		return reply, nil
+	}
+	reply.Header.Success = true
+	reply.Transition = transition
+	action.appliedTransition = transition
+
+	return reply, nil
+}
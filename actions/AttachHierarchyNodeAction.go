@@ -0,0 +1,227 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/abenstex/laniakea/dataStructures"
+	"github.com/abenstex/laniakea/logging"
+	"github.com/abenstex/laniakea/micro"
+	"github.com/abenstex/laniakea/mongodb"
+	utils2 "github.com/abenstex/laniakea/utils"
+	"github.com/abenstex/orion.commons/app"
+	http2 "github.com/abenstex/orion.commons/http"
+	structs2 "github.com/abenstex/orion.commons/structs"
+	"github.com/abenstex/orion.commons/utils"
+	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"net/http"
+	"orion.misc/hierarchy"
+	"orion.misc/structs"
+	"time"
+)
+
+type AttachHierarchyNodeAction struct {
+	baseAction      micro.BaseAction
+	MetricsStore    *utils.MetricsStore
+	receivedRequest structs.AttachHierarchyNodeRequest
+	event           *structs.HierarchyNodeChangedEvent
+}
+
+func (action *AttachHierarchyNodeAction) BeforeAction(ctx context.Context, request []byte) *micro.Exception {
+	dummy := structs.AttachHierarchyNodeRequest{}
+	err := json.Unmarshal(request, &dummy)
+	if err != nil {
+		return micro.NewException(structs2.UnmarshalError, err)
+	}
+	err = app.DefaultHandleActionRequest(request, &dummy.Header, action, true)
+	if err != nil {
+		return micro.NewException(structs2.RequestHeaderInvalid, err)
+	}
+	if len(dummy.HierarchyId) == 0 || len(dummy.ObjectType) == 0 || len(dummy.ObjectId) == 0 {
+		return micro.NewException(structs2.MissingParameterError,
+			fmt.Errorf("not all parameters (hierarchy_id, object_type and object_id) were provided"))
+	}
+
+	return nil
+}
+
+func (action AttachHierarchyNodeAction) BeforeActionAsync(ctx context.Context, request []byte) {
+
+}
+
+func (action AttachHierarchyNodeAction) AfterAction(ctx context.Context, reply *micro.IReply, request *micro.IRequest) *micro.Exception {
+	return nil
+}
+
+func (action AttachHierarchyNodeAction) AfterActionAsync(ctx context.Context, reply micro.IReply, request micro.IRequest) {
+
+}
+
+func (action AttachHierarchyNodeAction) GetBaseAction() micro.BaseAction {
+	return action.baseAction
+}
+
+func (action *AttachHierarchyNodeAction) SetHttpRequest(request *http.Request) {
+	action.baseAction.Request = request
+}
+
+func (action *AttachHierarchyNodeAction) InitBaseAction(baseAction micro.BaseAction) {
+	action.baseAction = baseAction
+}
+
+func (action AttachHierarchyNodeAction) SendEvents(request micro.IRequest) {
+	nodeRequest := request.(*structs.AttachHierarchyNodeRequest)
+	if !nodeRequest.Header.WasExecutedSuccessfully {
+		logging.GetLogger("AttachHierarchyNodeAction",
+			action.GetBaseAction().Environment,
+			true).Warn("RequestFailedEvent will be sent because the request was not successfully executed")
+		blerghEvent := structs2.NewRequestFailedEvent(nodeRequest, action.ProvideInformation(), action.baseAction.ID.String(), "")
+		blerghEvent.Send(action.ProvideInformation().ErrorReplyTopic, byte(viper.GetInt("messageBus.publishEventQos")),
+			utils.GetDefaultMqttConnectionOptionsWithIdPrefix(action.ProvideInformation().Name))
+		return
+	}
+	if action.event == nil {
+		return
+	}
+
+	sendHierarchyNodeChangedEvent(action.ProvideInformation(), nodeRequest.Header.SenderId, action.baseAction.Environment, *action.event)
+}
+
+func (action AttachHierarchyNodeAction) ProvideInformation() micro.ActionInformation {
+	var reply = "orion/server/misc/reply/hierarchy/node/attach"
+	var error = "orion/server/misc/error/hierarchy/node/attach"
+	var event = "orion/server/misc/event/hierarchy/node/attach"
+	var requestSample = dataStructures.StructToJsonString(structs.AttachHierarchyNodeRequest{})
+	var replySample = dataStructures.StructToJsonString(structs.AttachHierarchyNodeReply{})
+	var eventSample = dataStructures.StructToJsonString(structs.HierarchyNodeChangedEvent{})
+	info := micro.ActionInformation{
+		Name:            "AttachHierarchyNodeAction",
+		Description:     "Attaches an object to a tree of objects, either below a parent object or as a root",
+		RequestTopic:    "orion/server/misc/request/hierarchy/node/attach",
+		ReplyTopic:      reply,
+		ErrorReplyTopic: error,
+		Version:         1,
+		ClientId:        action.baseAction.ID.String(),
+		HttpMethods:     []string{http.MethodPost, "OPTIONS"},
+		EventTopic:      event,
+		RequestSample:   &requestSample,
+		ReplySample:     &replySample,
+		EventSample:     &eventSample,
+		IsScriptable:    false,
+	}
+
+	return info
+}
+
+func (action *AttachHierarchyNodeAction) HandleWebRequest(writer http.ResponseWriter, request *http.Request) {
+	action.SetHttpRequest(request)
+	http2.HandleHttpRequest(writer, request, action)
+}
+
+func (action *AttachHierarchyNodeAction) HeyHo(ctx context.Context, request []byte) (micro.IReply, micro.IRequest) {
+	start := time.Now()
+	defer action.MetricsStore.HandleActionMetric(start, action.GetBaseAction().Environment, action.ProvideInformation(), *action.baseAction.Token)
+	action.event = nil
+
+	err := json.Unmarshal(request, &action.receivedRequest)
+	if err != nil {
+		return structs2.NewErrorReplyHeaderWithException(micro.NewException(structs2.UnmarshalError, err),
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	reply, myErr := action.attachNode(ctx, action.receivedRequest)
+	if myErr != nil {
+		logging.GetLogger("AttachHierarchyNodeAction",
+			action.GetBaseAction().Environment,
+			true).WithError(myErr.Error).Error("Data could not be saved")
+		return structs2.NewErrorReplyHeaderWithOrionErr(myErr,
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	return reply, &action.receivedRequest
+}
+
+func (action *AttachHierarchyNodeAction) attachNode(ctx context.Context, request structs.AttachHierarchyNodeRequest) (structs.AttachHierarchyNodeReply, *structs2.OrionError) {
+	typeHierarchy, myErr := getHierarchyFromDb(ctx, action.baseAction, request.HierarchyId)
+	if myErr != nil {
+		return structs.AttachHierarchyNodeReply{}, myErr
+	}
+	if request.Parent == nil && len(hierarchy.Resolve([]structs.Hierarchy{*typeHierarchy}, request.ObjectType)) == 0 {
+		return structs.AttachHierarchyNodeReply{}, structs2.NewOrionError(structs.ValidationError,
+			fmt.Errorf("the object type %v is not part of the hierarchy %v", request.ObjectType, typeHierarchy.Info.Name))
+	}
+
+	// the node and its parent are read in the transaction, so the node is attached below the parent as it is stored
+	// when the node is inserted
+	var node structs.HierarchyNode
+	var rejection *structs2.OrionError
+	callback := func(sessCtx mongo.SessionContext) (interface{}, error) {
+		rejection = nil
+		existing, err := getHierarchyNodeFromDb(sessCtx, action.baseAction, request.HierarchyId, request.ObjectType, request.ObjectId)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			err = fmt.Errorf("%v %v is already part of the tree, it can only be moved", request.ObjectType, request.ObjectId)
+			rejection = structs2.NewOrionError(structs.ValidationError, err)
+			return nil, err
+		}
+		var parent *structs.HierarchyNode
+		if request.Parent != nil {
+			var myErr *structs2.OrionError
+			parent, myErr = findHierarchyNode(sessCtx, action.baseAction, request.HierarchyId, request.Parent.ObjectType, request.Parent.ObjectId)
+			if myErr != nil {
+				rejection = myErr
+				return nil, myErr.Error
+			}
+			if err := hierarchy.CheckParentType(*typeHierarchy, parent.ObjectType, request.ObjectType); err != nil {
+				rejection = structs2.NewOrionError(structs.ValidationError, err)
+				return nil, err
+			}
+		}
+
+		id := primitive.NewObjectID()
+		path := hierarchy.ChildPath(parent)
+		node = structs.HierarchyNode{
+			ID:          &id,
+			HierarchyId: request.HierarchyId,
+			ObjectType:  request.ObjectType,
+			ObjectId:    request.ObjectId,
+			Path:        path,
+			Depth:       len(path),
+			CreatedDate: utils2.GetCurrentTimeStamp(),
+			User:        &request.Header.User,
+			UserComment: &request.Header.Comment,
+		}
+		if parent != nil {
+			parentId := parent.ID.Hex()
+			node.ParentId = &parentId
+		}
+
+		return mongodb.InsertOne(sessCtx, action.baseAction.Environment.MongoDbConnection, "hierarchy_nodes", node)
+	}
+	_, err := mongodb.PerformQueriesInTransaction(ctx, action.baseAction.Environment.MongoDbConnection, callback)
+	if rejection != nil {
+		return structs.AttachHierarchyNodeReply{}, rejection
+	}
+	if err != nil {
+		return structs.AttachHierarchyNodeReply{}, structs2.NewOrionError(structs2.DatabaseError, fmt.Errorf("error executing queries in transaction: %v", err))
+	}
+	action.event = &structs.HierarchyNodeChangedEvent{
+		Change:      HierarchyNodeAttached,
+		HierarchyId: node.HierarchyId,
+		ObjectType:  node.ObjectType,
+		ObjectId:    node.ObjectId,
+		NewParentId: node.ParentId,
+		Nodes:       []structs.HierarchyNode{node},
+	}
+
+	var reply = structs.AttachHierarchyNodeReply{Node: node}
+	reply.Header = structs2.NewReplyHeader(action.ProvideInformation().ReplyTopic)
+	reply.Header.Timestamp = utils2.GetCurrentTimeStamp()
+	reply.Header.Success = true
+
+	return reply, nil
+}
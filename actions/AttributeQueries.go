@@ -0,0 +1,413 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"github.com/abenstex/laniakea/logging"
+	"github.com/abenstex/laniakea/micro"
+	"github.com/abenstex/laniakea/mongodb"
+	"github.com/abenstex/laniakea/mqtt"
+	utils2 "github.com/abenstex/laniakea/utils"
+	structs2 "github.com/abenstex/orion.commons/structs"
+	"github.com/abenstex/orion.commons/utils"
+	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"orion.misc/attributes"
+	"orion.misc/structs"
+)
+
+const AttributeValueChangedEventTopic = "orion/server/misc/event/attribute/value/save"
+
+func getAttributeDefinitionFromDb(ctx context.Context, baseAction micro.BaseAction, id string) (*structs.AttributeDefinition, *structs2.OrionError) {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, structs2.NewOrionError(structs2.NoDataFound, fmt.Errorf("attribute definition %v does not exist", id))
+	}
+	var definition structs.AttributeDefinition
+	err = baseAction.Environment.MongoDbConnection.Database().Collection("attribute_definitions").
+		FindOne(ctx, bson.M{"_id": objectId}).Decode(&definition)
+	if err == mongo.ErrNoDocuments {
+		return nil, structs2.NewOrionError(structs2.NoDataFound, fmt.Errorf("attribute definition %v does not exist", id))
+	}
+	if err != nil {
+		return nil, structs2.NewOrionError(structs2.DatabaseError, err)
+	}
+
+	return &definition, nil
+}
+
+func getAttributeDefinitionsFromDb(ctx context.Context, baseAction micro.BaseAction, filter bson.M) ([]structs.AttributeDefinition, *structs2.OrionError) {
+	cursor, err := baseAction.Environment.MongoDbConnection.Database().Collection("attribute_definitions").Find(ctx, filter)
+	if err != nil {
+		return nil, structs2.NewOrionError(structs2.DatabaseError, err)
+	}
+	objects := make([]structs.AttributeDefinition, 0)
+	if err = cursor.All(ctx, &objects); err != nil {
+		return nil, structs2.NewOrionError(structs2.DatabaseError, err)
+	}
+
+	return objects, nil
+}
+
+// validateAttributeValues validates the values against their attribute definitions, values of attributes
+// that are not defined are rejected as well. Values of dependent attributes are checked against the value of
+// their parent attribute, either from the same batch or from the stored values of the object. New values of
+// parent attributes are rejected if a stored dependent value that is not replaced in the batch would become invalid.
+func validateAttributeValues(ctx context.Context, baseAction micro.BaseAction, values []structs.AttributeValue) ([]structs.AttributeValidationError, *structs2.OrionError) {
+	definitions, orionErr := getAttributeDefinitionsFromDb(ctx, baseAction, bson.M{})
+	if orionErr != nil {
+		return nil, orionErr
+	}
+	stored, orionErr := getStoredParentValues(ctx, baseAction, definitions, values)
+	if orionErr != nil {
+		return nil, orionErr
+	}
+	storedDependents, orionErr := getStoredDependentValues(ctx, baseAction, definitions, values)
+	if orionErr != nil {
+		return nil, orionErr
+	}
+	pending := indexAttributeValues(values)
+
+	validationErrors := make([]structs.AttributeValidationError, 0)
+	for _, value := range values {
+		definition := attributes.FindDefinition(definitions, value.AttributeId)
+		if definition == nil || definition.ID.Hex() != value.AttributeId {
+			validationErrors = append(validationErrors, attributes.NewValidationError(value, attributes.ErrorUnknownAttribute,
+				fmt.Sprintf("attribute definition %v does not exist", value.AttributeId)))
+			continue
+		}
+		validationErrors = append(validationErrors, attributes.Validate(*definition, value)...)
+		validationErrors = append(validationErrors, validateDependency(definitions, *definition, value, pending, stored)...)
+		validationErrors = append(validationErrors, validateDependents(definitions, *definition, value, pending, storedDependents)...)
+	}
+
+	return validationErrors, nil
+}
+
+// validateDependency checks the value of a dependent attribute against the value of its parent attribute, a value
+// in pending takes precedence over the stored one
+func validateDependency(definitions []structs.AttributeDefinition, definition structs.AttributeDefinition, value structs.AttributeValue,
+	pending, stored map[string]structs.AttributeValue) []structs.AttributeValidationError {
+	if definition.DependsOn == nil {
+		return nil
+	}
+	var parentValues []string
+	if parent := attributes.FindDefinition(definitions, definition.DependsOn.ParentAttribute); parent != nil {
+		key := attributes.AttributeValueKey(value.ObjectType, value.ObjectId, parent.ID.Hex())
+		if candidate, ok := pending[key]; ok {
+			parentValues = attributes.ValueList(*parent, candidate)
+		} else if candidate, ok := stored[key]; ok {
+			parentValues = attributes.ValueList(*parent, candidate)
+		}
+	}
+
+	return attributes.ValidateDependency(definition, value, parentValues)
+}
+
+// validateDependents checks the stored values of the attributes that depend on the attribute of value against the new
+// value, dependent values in pending replace the stored ones and are checked by validateDependency
+func validateDependents(definitions []structs.AttributeDefinition, definition structs.AttributeDefinition, value structs.AttributeValue,
+	pending, storedDependents map[string]structs.AttributeValue) []structs.AttributeValidationError {
+	validationErrors := make([]structs.AttributeValidationError, 0)
+	for _, dependent := range attributes.Dependents(definitions, definition) {
+		key := attributes.AttributeValueKey(value.ObjectType, value.ObjectId, dependent.ID.Hex())
+		if _, ok := pending[key]; ok {
+			continue
+		}
+		if dependentValue, ok := storedDependents[key]; ok {
+			validationErrors = append(validationErrors, attributes.ValidateDependentValue(definition, value, dependent, dependentValue)...)
+		}
+	}
+
+	return validationErrors
+}
+
+// indexAttributeValues returns the values by the key of the object and attribute they belong to
+func indexAttributeValues(values []structs.AttributeValue) map[string]structs.AttributeValue {
+	indexed := make(map[string]structs.AttributeValue, len(values))
+	for _, value := range values {
+		indexed[attributes.AttributeValueKey(value.ObjectType, value.ObjectId, value.AttributeId)] = value
+	}
+
+	return indexed
+}
+
+// getStoredParentValues loads the stored values of the parent attributes of the dependent attributes in values with
+// a single query, the values are indexed like by indexAttributeValues
+func getStoredParentValues(ctx context.Context, baseAction micro.BaseAction, definitions []structs.AttributeDefinition,
+	values []structs.AttributeValue) (map[string]structs.AttributeValue, *structs2.OrionError) {
+	wanted := make(map[string]bool)
+	objects := make([]bson.M, 0)
+	for _, value := range values {
+		definition := attributes.FindDefinition(definitions, value.AttributeId)
+		if definition == nil || definition.DependsOn == nil {
+			continue
+		}
+		parent := attributes.FindDefinition(definitions, definition.DependsOn.ParentAttribute)
+		if parent == nil {
+			continue
+		}
+		key := attributes.AttributeValueKey(value.ObjectType, value.ObjectId, parent.ID.Hex())
+		if wanted[key] {
+			continue
+		}
+		wanted[key] = true
+		objects = append(objects, bson.M{"object_type": value.ObjectType, "object_id": value.ObjectId, "attribute_id": parent.ID.Hex()})
+	}
+	if len(objects) == 0 {
+		return map[string]structs.AttributeValue{}, nil
+	}
+
+	stored, orionErr := getAttributeValuesFromDb(ctx, baseAction, bson.M{"$or": objects})
+	if orionErr != nil {
+		return nil, orionErr
+	}
+
+	return indexAttributeValues(stored), nil
+}
+
+// getStoredDependentValues loads the stored values of the attributes that depend on the attributes of values with a
+// single query, the values are indexed like by indexAttributeValues
+func getStoredDependentValues(ctx context.Context, baseAction micro.BaseAction, definitions []structs.AttributeDefinition,
+	values []structs.AttributeValue) (map[string]structs.AttributeValue, *structs2.OrionError) {
+	wanted := make(map[string]bool)
+	objects := make([]bson.M, 0)
+	for _, value := range values {
+		definition := attributes.FindDefinition(definitions, value.AttributeId)
+		if definition == nil {
+			continue
+		}
+		for _, dependent := range attributes.Dependents(definitions, *definition) {
+			key := attributes.AttributeValueKey(value.ObjectType, value.ObjectId, dependent.ID.Hex())
+			if wanted[key] {
+				continue
+			}
+			wanted[key] = true
+			objects = append(objects, bson.M{"object_type": value.ObjectType, "object_id": value.ObjectId, "attribute_id": dependent.ID.Hex()})
+		}
+	}
+	if len(objects) == 0 {
+		return map[string]structs.AttributeValue{}, nil
+	}
+
+	stored, orionErr := getAttributeValuesFromDb(ctx, baseAction, bson.M{"$or": objects})
+	if orionErr != nil {
+		return nil, orionErr
+	}
+
+	return indexAttributeValues(stored), nil
+}
+
+func getHierarchiesFromDb(ctx context.Context, baseAction micro.BaseAction, filter bson.M) ([]structs.Hierarchy, *structs2.OrionError) {
+	cursor, err := baseAction.Environment.MongoDbConnection.Database().Collection("hierarchies").Find(ctx, filter)
+	if err != nil {
+		return nil, structs2.NewOrionError(structs2.DatabaseError, err)
+	}
+	objects := make([]structs.Hierarchy, 0)
+	if err = cursor.All(ctx, &objects); err != nil {
+		return nil, structs2.NewOrionError(structs2.DatabaseError, err)
+	}
+
+	return objects, nil
+}
+
+func getParametersFromDb(ctx context.Context, baseAction micro.BaseAction, filter bson.M) ([]structs.Parameter, *structs2.OrionError) {
+	cursor, err := baseAction.Environment.MongoDbConnection.Database().Collection("parameters").Find(ctx, filter)
+	if err != nil {
+		return nil, structs2.NewOrionError(structs2.DatabaseError, err)
+	}
+	objects := make([]structs.Parameter, 0)
+	if err = cursor.All(ctx, &objects); err != nil {
+		return nil, structs2.NewOrionError(structs2.DatabaseError, err)
+	}
+
+	return objects, nil
+}
+
+// getKnownObjectTypes returns the object types configured in objectTypes.known. The object types of ORION are not
+// registered anywhere this module could read them, so hierarchies are only checked against configured types.
+func getKnownObjectTypes() map[string]bool {
+	knownTypes := make(map[string]bool)
+	for _, objectType := range viper.GetStringSlice("objectTypes.known") {
+		knownTypes[objectType] = true
+	}
+
+	return knownTypes
+}
+
+func getAttributeGroupsFromDb(ctx context.Context, baseAction micro.BaseAction, filter bson.M) ([]structs.AttributeGroup, *structs2.OrionError) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "display_order", Value: 1}})
+	cursor, err := baseAction.Environment.MongoDbConnection.Database().Collection("attribute_groups").Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, structs2.NewOrionError(structs2.DatabaseError, err)
+	}
+	objects := make([]structs.AttributeGroup, 0)
+	if err = cursor.All(ctx, &objects); err != nil {
+		return nil, structs2.NewOrionError(structs2.DatabaseError, err)
+	}
+
+	return objects, nil
+}
+
+func getAttributeValuesFromDb(ctx context.Context, baseAction micro.BaseAction, filter bson.M) ([]structs.AttributeValue, *structs2.OrionError) {
+	cursor, err := baseAction.Environment.MongoDbConnection.Database().Collection("attribute_values").Find(ctx, filter)
+	if err != nil {
+		return nil, structs2.NewOrionError(structs2.DatabaseError, err)
+	}
+	objects := make([]structs.AttributeValue, 0)
+	if err = cursor.All(ctx, &objects); err != nil {
+		return nil, structs2.NewOrionError(structs2.DatabaseError, err)
+	}
+
+	return objects, nil
+}
+
+func getAttributeValueChangesFromDb(ctx context.Context, baseAction micro.BaseAction, filter bson.M) ([]structs.AttributeValueChange, *structs2.OrionError) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "timestamp", Value: 1}})
+	cursor, err := baseAction.Environment.MongoDbArchiveConnection.Database().Collection("attribute_value_changes").
+		Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, structs2.NewOrionError(structs2.DatabaseError, err)
+	}
+	objects := make([]structs.AttributeValueChange, 0)
+	if err = cursor.All(ctx, &objects); err != nil {
+		return nil, structs2.NewOrionError(structs2.DatabaseError, err)
+	}
+
+	return objects, nil
+}
+
+func getAttributeValueFromDb(ctx context.Context, baseAction micro.BaseAction, attributeId, objectType, objectId string) (*structs.AttributeValue, error) {
+	var value structs.AttributeValue
+	err := baseAction.Environment.MongoDbConnection.Database().Collection("attribute_values").
+		FindOne(ctx, bson.M{"attribute_id": attributeId, "object_type": objectType, "object_id": objectId}).Decode(&value)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &value, nil
+}
+
+// saveAttributeValues inserts or replaces the values in a single transaction and archives a change record for
+// every value that was actually changed. Values that are equal to the stored ones are left untouched, with keepStored
+// only values that don't exist yet are inserted.
+func saveAttributeValues(ctx context.Context, baseAction micro.BaseAction, values []structs.AttributeValue, user, comment string,
+	keepStored bool) ([]structs.AttributeChange, *structs2.OrionError) {
+	definitions, orionErr := getAttributeDefinitionsFromDb(ctx, baseAction, bson.M{})
+	if orionErr != nil {
+		return nil, orionErr
+	}
+	definitionsById := make(map[string]structs.AttributeDefinition, len(definitions))
+	for _, definition := range definitions {
+		definitionsById[definition.ID.Hex()] = definition
+	}
+
+	changes := make([]structs.AttributeChange, 0, len(values))
+	valueChanges := make([]structs.AttributeValueChange, 0, len(values))
+	callback := func(sessCtx mongo.SessionContext) (interface{}, error) {
+		// the callback runs again if the transaction is retried
+		changes = changes[:0]
+		valueChanges = valueChanges[:0]
+		now := utils2.GetCurrentTimeStamp()
+		for _, received := range values {
+			value, err := attributes.NormalizeValue(definitionsById[received.AttributeId], received)
+			if err != nil {
+				return nil, err
+			}
+			stored, err := getAttributeValueFromDb(sessCtx, baseAction, value.AttributeId, value.ObjectType, value.ObjectId)
+			if err != nil {
+				return nil, err
+			}
+			value.User = &user
+			value.UserComment = &comment
+			var originalValue *string
+			if stored == nil {
+				value.ID = nil
+				value.Version = 1
+				value.CreatedDate = now
+				value.ChangeDate = nil
+				_, err = mongodb.InsertOne(sessCtx, baseAction.Environment.MongoDbConnection, "attribute_values", value)
+			} else {
+				if keepStored || stored.Value == value.Value {
+					continue
+				}
+				originalValue = &stored.Value
+				value.ID = stored.ID
+				value.Version = stored.Version + 1
+				value.CreatedDate = stored.CreatedDate
+				value.ChangeDate = &now
+				_, err = mongodb.ReplaceAndFindOneById(sessCtx, baseAction.Environment.MongoDbConnection, "attribute_values", stored.ID.Hex(), value)
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			newValue := value.Value
+			valueChanges = append(valueChanges, structs.AttributeValueChange{
+				AttributeId:   value.AttributeId,
+				ObjectType:    value.ObjectType,
+				ObjectId:      value.ObjectId,
+				ObjectVersion: value.Version,
+				OriginalValue: originalValue,
+				NewValue:      &newValue,
+				User:          user,
+				Comment:       comment,
+				Timestamp:     now,
+			})
+			change := structs.AttributeChange{
+				AttributeId:   value.AttributeId,
+				ObjectType:    value.ObjectType,
+				ObjectId:      value.ObjectId,
+				ObjectVersion: value.Version,
+				NewValue:      value.Value,
+			}
+			if originalValue != nil {
+				change.OriginalValue = *originalValue
+			}
+			changes = append(changes, change)
+		}
+
+		return nil, nil
+	}
+	_, err := mongodb.PerformQueriesInTransaction(ctx, baseAction.Environment.MongoDbConnection, callback)
+	if err != nil {
+		return nil, structs2.NewOrionError(structs2.DatabaseError, fmt.Errorf("error executing queries in transaction: %v", err))
+	}
+	archiveAttributeValueChanges(ctx, baseAction, valueChanges)
+
+	return changes, nil
+}
+
+// archiveAttributeValueChanges records the history of committed changes. The archive is a different database, so it
+// is only written once the transaction succeeded; a failure is logged because the values are already changed.
+func archiveAttributeValueChanges(ctx context.Context, baseAction micro.BaseAction, changes []structs.AttributeValueChange) {
+	for _, change := range changes {
+		_, err := mongodb.InsertOne(ctx, baseAction.Environment.MongoDbArchiveConnection, "attribute_value_changes", change)
+		if err != nil {
+			logging.GetLogger(ApplicationName, baseAction.Environment, true).WithError(err).
+				Errorf("Could not archive the change of attribute %v of %v %v", change.AttributeId, change.ObjectType, change.ObjectId)
+		}
+	}
+}
+
+func sendAttributeValueChangedEvent(actionInfo micro.ActionInformation, senderId string, environment utils2.Environment, changes []structs.AttributeChange) {
+	event := structs.AttributeValueChangedEvent{
+		Header:           *micro.NewEventHeaderForAction(actionInfo, senderId, ""),
+		AttributeChanges: changes,
+	}
+
+	json, err := event.ToJsonString()
+	if err != nil {
+		logging.GetLogger(actionInfo.Name, environment, true).WithError(err).Error("Could not send events")
+
+		return
+	}
+	mqtt.Publish(AttributeValueChangedEventTopic, json, byte(viper.GetInt("messageBus.publishEventQos")),
+		utils.GetDefaultMqttConnectionOptionsWithIdPrefix(actionInfo.Name))
+}
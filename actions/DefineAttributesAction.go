@@ -14,8 +14,10 @@ import (
 	"github.com/abenstex/orion.commons/structs"
 	"github.com/abenstex/orion.commons/utils"
 	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"net/http"
+	"orion.misc/attributes"
 	structs2 "orion.misc/structs"
 	"time"
 )
@@ -23,7 +25,7 @@ import (
 type DefineAttributesAction struct {
 	baseAction   micro.BaseAction
 	MetricsStore *utils.MetricsStore
-	savedObjects []structs.AttributeDefinition
+	savedObjects []structs2.AttributeDefinition
 	startedTime  int64
 }
 
@@ -75,9 +77,13 @@ func (action DefineAttributesAction) SendEvents(request micro.IRequest) {
 			utils.GetDefaultMqttConnectionOptionsWithIdPrefix(action.ProvideInformation().Name))
 		return
 	}
+	definitions := make([]structs.AttributeDefinition, 0, len(action.savedObjects))
+	for _, savedObject := range action.savedObjects {
+		definitions = append(definitions, savedObject.AttributeDefinition)
+	}
 	event := structs2.AttributeDefinitionSavedEvent{
 		Header:               *micro.NewEventHeaderForAction(action.ProvideInformation(), saveRequest.Header.SenderId, ""),
-		AttributeDefinitions: action.savedObjects,
+		AttributeDefinitions: definitions,
 		ObjectType:           "AttributeDefinition",
 	}
 
@@ -133,7 +139,13 @@ func (action *DefineAttributesAction) HeyHo(ctx context.Context, request []byte)
 			action.ProvideInformation().ErrorReplyTopic), &saveRequest
 	}
 
-	orionErr := action.saveObjects(ctx, saveRequest.UpdatedAttributeDefinitions, saveRequest.Header.Comment, saveRequest.Header.User)
+	orionErr := action.checkDefinitions(ctx, saveRequest.UpdatedAttributeDefinitions)
+	if orionErr != nil {
+		return structs.NewErrorReplyHeaderWithOrionErr(orionErr,
+			action.ProvideInformation().ErrorReplyTopic), &saveRequest
+	}
+
+	orionErr = action.saveObjects(ctx, saveRequest.UpdatedAttributeDefinitions, saveRequest.Header.Comment, saveRequest.Header.User)
 	if orionErr != nil {
 		logging.GetLogger("DefineAttributesAction",
 			action.GetBaseAction().Environment,
@@ -148,46 +160,117 @@ func (action *DefineAttributesAction) HeyHo(ctx context.Context, request []byte)
 	return reply, &saveRequest
 }
 
-func (action *DefineAttributesAction) archiveAndReplaceObject(ctx context.Context, object structs.AttributeDefinition) error {
-	var objectToArchive structs.AttributeDefinition
+// checkDefinitions validates the expressions of computed attributes and the value dependencies against the stored
+// definitions replaced by the updated ones, so that no attribute ends up depending on itself. The cardinality and
+// the localizations of the updated definitions are checked as well.
+func (action *DefineAttributesAction) checkDefinitions(ctx context.Context, updatedObjects []structs2.AttributeDefinition) *structs.OrionError {
+	storedObjects, orionErr := getAttributeDefinitionsFromDb(ctx, action.baseAction, bson.M{})
+	if orionErr != nil {
+		return orionErr
+	}
+	updatedIds := make(map[string]bool, len(updatedObjects))
+	for _, object := range updatedObjects {
+		if object.ID != nil && !object.ID.IsZero() {
+			updatedIds[object.ID.Hex()] = true
+		}
+	}
+	storedNames := make(map[string]string, len(storedObjects))
+	definitions := make([]structs2.AttributeDefinition, 0, len(storedObjects)+len(updatedObjects))
+	for _, object := range storedObjects {
+		if object.ID == nil || !updatedIds[object.ID.Hex()] {
+			definitions = append(definitions, object)
+		} else {
+			storedNames[object.ID.Hex()] = object.Info.Name
+		}
+	}
+	definitions = append(definitions, updatedObjects...)
+	// only new and renamed attributes must have a unique name, names that were used twice before must not
+	// prevent other changes of these attributes
+	named := make([]structs2.AttributeDefinition, 0, len(updatedObjects))
+	for _, object := range updatedObjects {
+		if object.ID == nil || object.ID.IsZero() || storedNames[object.ID.Hex()] != object.Info.Name {
+			named = append(named, object)
+		}
+	}
+
+	if err := attributes.CheckComputedDefinitions(definitions, named); err != nil {
+		return structs.NewOrionError(structs2.ValidationError, err)
+	}
+	if err := attributes.CheckValueDependencies(definitions); err != nil {
+		return structs.NewOrionError(structs2.ValidationError, err)
+	}
+	if err := attributes.CheckDatatypes(updatedObjects); err != nil {
+		return structs.NewOrionError(structs2.ValidationError, err)
+	}
+	if err := attributes.CheckCardinalities(updatedObjects); err != nil {
+		return structs.NewOrionError(structs2.ValidationError, err)
+	}
+	if err := attributes.CheckLocalizations(updatedObjects); err != nil {
+		return structs.NewOrionError(structs2.ValidationError, err)
+	}
+	if err := attributes.CheckCreationQueries(updatedObjects); err != nil {
+		return structs.NewOrionError(structs2.ValidationError, err)
+	}
+
+	return nil
+}
+
+// archiveAndReplaceObject replaces the stored definition and archives it, the replaced definition is returned
+func (action *DefineAttributesAction) archiveAndReplaceObject(ctx context.Context, object structs2.AttributeDefinition) (structs2.AttributeDefinition, error) {
+	var objectToArchive structs2.AttributeDefinition
 	result, err := mongodb.ReplaceAndFindOneById(ctx, action.baseAction.Environment.MongoDbConnection, "attribute_definitions", object.ID.Hex(), object)
 	if err != nil {
-		return err
+		return objectToArchive, err
 	}
 	err = result.Decode(&objectToArchive)
 	if err != nil {
-		return err
+		return objectToArchive, err
 	}
 	objectToArchive.Info.ChangeDate = &action.startedTime
 	objectToArchive.ID = nil
 	_, err = mongodb.InsertOne(context.Background(), action.baseAction.Environment.MongoDbArchiveConnection, "attribute_definitions", objectToArchive)
 
-	return err
+	return objectToArchive, err
 }
 
-func (action *DefineAttributesAction) saveObjects(ctx context.Context, updatedObjects []structs.AttributeDefinition, comment, user string) *structs.OrionError {
+// saveObjects inserts new and replaces changed definitions. The typed values of an attribute whose datatype or
+// cardinality changed are removed in the same transaction and computed again once it is committed, values that
+// are left without one by a failure are filled when the service starts.
+func (action *DefineAttributesAction) saveObjects(ctx context.Context, updatedObjects []structs2.AttributeDefinition, comment, user string) *structs.OrionError {
 	newCtx := context.WithValue(ctx, "objects", updatedObjects)
+	retyped := make([]string, 0)
 
 	callback := func(sessCtx mongo.SessionContext) (interface{}, error) {
-		objects := sessCtx.Value("objects").([]structs.AttributeDefinition)
+		retyped = retyped[:0]
+		objects := sessCtx.Value("objects").([]structs2.AttributeDefinition)
 		for _, object := range objects {
 			if object.Info.CreatedDate == 0 {
 				object.Info.CreatedDate = laniakea.GetCurrentTimeStamp()
 			}
 			if object.ID == nil || object.ID.IsZero() {
-				_, err := mongodb.InsertOne(sessCtx, action.baseAction.Environment.MongoDbConnection, "attribute_definitions", object)
+				result, err := mongodb.InsertOne(sessCtx, action.baseAction.Environment.MongoDbConnection, "attribute_definitions", object)
 				if err != nil {
 					return nil, err
 				}
+				object.ID = insertedObjectId(result)
 			} else {
 				object.Info.UserComment = &comment
 				object.Info.User = &user
 				object.Info.ChangeDate = &action.startedTime
 
-				err := action.archiveAndReplaceObject(sessCtx, object)
+				original, err := action.archiveAndReplaceObject(sessCtx, object)
 				if err != nil {
 					return nil, err
 				}
+				if attributes.NormalizeDatatype(original.DataType) != attributes.NormalizeDatatype(object.DataType) ||
+					attributes.IsMultiValued(original) != attributes.IsMultiValued(object) {
+					_, err = action.baseAction.Environment.MongoDbConnection.Database().Collection("attribute_values").
+						UpdateMany(sessCtx, bson.M{"attribute_id": object.ID.Hex()}, bson.M{"$unset": bson.M{"typed_value": ""}})
+					if err != nil {
+						return nil, err
+					}
+					retyped = append(retyped, object.ID.Hex())
+				}
 			}
 			action.savedObjects = append(action.savedObjects, object)
 		}
@@ -198,6 +281,13 @@ func (action *DefineAttributesAction) saveObjects(ctx context.Context, updatedOb
 	if err != nil {
 		return structs.NewOrionError(structs.DatabaseError, fmt.Errorf("error executing queries in transaction: %v", err))
 	}
+	if len(retyped) > 0 {
+		_, err = fillTypedValues(ctx, action.baseAction.Environment, bson.M{"attribute_id": bson.M{"$in": retyped}})
+		if err != nil {
+			logging.GetLogger("DefineAttributesAction", action.GetBaseAction().Environment, true).WithError(err).
+				Error("Could not compute the typed values of attributes whose datatype changed, they are computed on the next start")
+		}
+	}
 
 	return nil
 }
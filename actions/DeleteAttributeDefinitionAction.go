@@ -17,6 +17,7 @@ import (
 	"github.com/spf13/viper"
 	"go.mongodb.org/mongo-driver/mongo"
 	"net/http"
+	structs2 "orion.misc/structs"
 	"time"
 )
 
@@ -154,7 +155,7 @@ func (action *DeleteAttributeDefinitionAction) deleteObject(ctx context.Context,
 		if err != nil {
 			return nil, err
 		}
-		var objectToArchive structs.AttributeDefinition
+		var objectToArchive structs2.AttributeDefinition
 		err = result.Decode(&objectToArchive)
 		if err != nil {
 			return nil, err
@@ -0,0 +1,231 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/abenstex/laniakea/dataStructures"
+	"github.com/abenstex/laniakea/logging"
+	"github.com/abenstex/laniakea/micro"
+	"github.com/abenstex/laniakea/mongodb"
+	"github.com/abenstex/laniakea/mqtt"
+	utils2 "github.com/abenstex/laniakea/utils"
+	"github.com/abenstex/orion.commons/app"
+	http2 "github.com/abenstex/orion.commons/http"
+	structs2 "github.com/abenstex/orion.commons/structs"
+	"github.com/abenstex/orion.commons/utils"
+	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"net/http"
+	"orion.misc/attributes"
+	"orion.misc/structs"
+	"time"
+)
+
+var errAttributeValueNotFound = errors.New("the attribute value does not exist")
+
+type DeleteAttributeValueAction struct {
+	baseAction      micro.BaseAction
+	MetricsStore    *utils.MetricsStore
+	receivedRequest structs.DeleteAttributeValueRequest
+}
+
+func (action *DeleteAttributeValueAction) BeforeAction(ctx context.Context, request []byte) *micro.Exception {
+	dummy := structs.DeleteAttributeValueRequest{}
+	err := json.Unmarshal(request, &dummy)
+	if err != nil {
+		return micro.NewException(structs2.UnmarshalError, err)
+	}
+	err = app.DefaultHandleActionRequest(request, &dummy.Header, action, true)
+	if err != nil {
+		return micro.NewException(structs2.RequestHeaderInvalid, err)
+	}
+	if len(dummy.AttributeId) == 0 || len(dummy.ObjectType) == 0 || len(dummy.ObjectId) == 0 {
+		return micro.NewException(structs2.MissingParameterError,
+			fmt.Errorf("not all parameters (attribute_id, object_type and object_id) were provided"))
+	}
+
+	return nil
+}
+
+func (action DeleteAttributeValueAction) BeforeActionAsync(ctx context.Context, request []byte) {
+
+}
+
+func (action DeleteAttributeValueAction) AfterAction(ctx context.Context, reply *micro.IReply, request *micro.IRequest) *micro.Exception {
+	return nil
+}
+
+func (action DeleteAttributeValueAction) AfterActionAsync(ctx context.Context, reply micro.IReply, request micro.IRequest) {
+
+}
+
+func (action DeleteAttributeValueAction) GetBaseAction() micro.BaseAction {
+	return action.baseAction
+}
+
+func (action *DeleteAttributeValueAction) SetHttpRequest(request *http.Request) {
+	action.baseAction.Request = request
+}
+
+func (action *DeleteAttributeValueAction) InitBaseAction(baseAction micro.BaseAction) {
+	action.baseAction = baseAction
+}
+
+func (action DeleteAttributeValueAction) SendEvents(request micro.IRequest) {
+	deleteRequest := request.(*structs.DeleteAttributeValueRequest)
+	if !deleteRequest.Header.WasExecutedSuccessfully {
+		logging.GetLogger("DeleteAttributeValueAction",
+			action.GetBaseAction().Environment,
+			true).Warn("RequestFailedEvent will be sent because the request was not successfully executed")
+		blerghEvent := structs2.NewRequestFailedEvent(deleteRequest, action.ProvideInformation(), action.baseAction.ID.String(), "")
+		blerghEvent.Send(action.ProvideInformation().ErrorReplyTopic, byte(viper.GetInt("messageBus.publishEventQos")),
+			utils.GetDefaultMqttConnectionOptionsWithIdPrefix(action.ProvideInformation().Name))
+		return
+	}
+	event := structs.AttributeValueDeletedEvent{
+		Header:      *micro.NewEventHeaderForAction(action.ProvideInformation(), deleteRequest.Header.SenderId, ""),
+		AttributeId: deleteRequest.AttributeId,
+		ObjectType:  deleteRequest.ObjectType,
+		ObjectId:    deleteRequest.ObjectId,
+	}
+
+	json, err := event.ToJsonString()
+	if err != nil {
+		logging.GetLogger("DeleteAttributeValueAction", action.GetBaseAction().Environment, true).WithError(err).Error("Could not send events")
+
+		return
+	}
+	mqtt.Publish(action.ProvideInformation().EventTopic, json, byte(viper.GetInt("messageBus.publishEventQos")),
+		utils.GetDefaultMqttConnectionOptionsWithIdPrefix(action.ProvideInformation().Name))
+}
+
+func (action DeleteAttributeValueAction) ProvideInformation() micro.ActionInformation {
+	var reply = "orion/server/misc/reply/attribute/value/delete"
+	var error = "orion/server/misc/error/attribute/value/delete"
+	var event = "orion/server/misc/event/attribute/value/delete"
+	var requestSample = dataStructures.StructToJsonString(structs.DeleteAttributeValueRequest{})
+	var replySample = dataStructures.StructToJsonString(micro.ReplyHeader{})
+	var eventSample = dataStructures.StructToJsonString(structs.AttributeValueDeletedEvent{})
+	info := micro.ActionInformation{
+		Name:            "DeleteAttributeValueAction",
+		Description:     "Deletes the value of an attribute from an object",
+		RequestTopic:    "orion/server/misc/request/attribute/value/delete",
+		ReplyTopic:      reply,
+		ErrorReplyTopic: error,
+		Version:         1,
+		ClientId:        action.baseAction.ID.String(),
+		HttpMethods:     []string{http.MethodPost, "OPTIONS"},
+		EventTopic:      event,
+		RequestSample:   &requestSample,
+		ReplySample:     &replySample,
+		EventSample:     &eventSample,
+		IsScriptable:    true,
+	}
+
+	return info
+}
+
+func (action *DeleteAttributeValueAction) HandleWebRequest(writer http.ResponseWriter, request *http.Request) {
+	action.SetHttpRequest(request)
+	http2.HandleHttpRequest(writer, request, action)
+}
+
+func (action *DeleteAttributeValueAction) HeyHo(ctx context.Context, request []byte) (micro.IReply, micro.IRequest) {
+	start := time.Now()
+	defer action.MetricsStore.HandleActionMetric(start, action.GetBaseAction().Environment, action.ProvideInformation(), *action.baseAction.Token)
+
+	err := json.Unmarshal(request, &action.receivedRequest)
+	if err != nil {
+		return structs2.NewErrorReplyHeaderWithException(micro.NewException(structs2.UnmarshalError, err),
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	myErr := action.deleteObject(ctx, action.receivedRequest)
+	if myErr != nil {
+		logging.GetLogger("DeleteAttributeValueAction", action.baseAction.Environment, true).
+			WithError(myErr.Error).
+			Error("attribute value could not be deleted from the database")
+
+		return structs2.NewErrorReplyHeaderWithOrionErr(myErr,
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	reply := structs2.NewReplyHeader(action.ProvideInformation().ReplyTopic)
+	reply.Success = true
+
+	return reply, &action.receivedRequest
+}
+
+// deleteObject deletes the value of an attribute. The value of a parent attribute is only deleted if the object has no
+// values of the dependent attributes, they would not be valid anymore.
+func (action *DeleteAttributeValueAction) deleteObject(ctx context.Context, request structs.DeleteAttributeValueRequest) *structs2.OrionError {
+	definitions, myErr := getAttributeDefinitionsFromDb(ctx, action.baseAction, bson.M{})
+	if myErr != nil {
+		return myErr
+	}
+	dependentIds := make([]string, 0)
+	if definition := attributes.FindDefinition(definitions, request.AttributeId); definition != nil {
+		for _, dependent := range attributes.Dependents(definitions, *definition) {
+			dependentIds = append(dependentIds, dependent.ID.Hex())
+		}
+	}
+
+	notFound := false
+	var rejection *structs2.OrionError
+	var valueChange structs.AttributeValueChange
+	callback := func(sessCtx mongo.SessionContext) (interface{}, error) {
+		rejection = nil
+		collection := action.baseAction.Environment.MongoDbConnection.Database().Collection("attribute_values")
+		if len(dependentIds) > 0 {
+			count, err := collection.CountDocuments(sessCtx, bson.M{"object_type": request.ObjectType, "object_id": request.ObjectId,
+				"attribute_id": bson.M{"$in": dependentIds}})
+			if err != nil {
+				return nil, err
+			}
+			if count > 0 {
+				rejection = structs2.NewOrionError(structs.ReferentialIntegrityError,
+					fmt.Errorf("the object has %d value(s) of attributes that depend on the value", count))
+				return nil, rejection.Error
+			}
+		}
+		var deleted structs.AttributeValue
+		err := collection.FindOneAndDelete(sessCtx, bson.M{"attribute_id": request.AttributeId, "object_type": request.ObjectType,
+			"object_id": request.ObjectId}).Decode(&deleted)
+		if err == mongo.ErrNoDocuments {
+			notFound = true
+			return nil, errAttributeValueNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		valueChange = structs.AttributeValueChange{
+			AttributeId:   deleted.AttributeId,
+			ObjectType:    deleted.ObjectType,
+			ObjectId:      deleted.ObjectId,
+			ObjectVersion: deleted.Version,
+			OriginalValue: &deleted.Value,
+			User:          request.Header.User,
+			Comment:       request.Header.Comment,
+			Timestamp:     utils2.GetCurrentTimeStamp(),
+		}
+
+		return nil, nil
+	}
+	_, err := mongodb.PerformQueriesInTransaction(ctx, action.baseAction.Environment.MongoDbConnection, callback)
+	if notFound {
+		return structs2.NewOrionError(structs2.NoDataFound, errAttributeValueNotFound)
+	}
+	if rejection != nil {
+		return rejection
+	}
+	if err != nil {
+		return structs2.NewOrionError(structs2.DatabaseError, fmt.Errorf("error executing queries in transaction: %v", err))
+	}
+	archiveAttributeValueChanges(ctx, action.baseAction, []structs.AttributeValueChange{valueChange})
+
+	return nil
+}
@@ -3,6 +3,7 @@ package actions
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/abenstex/laniakea/dataStructures"
 	"github.com/abenstex/laniakea/logging"
@@ -15,6 +16,8 @@ import (
 	"github.com/abenstex/orion.commons/structs"
 	"github.com/abenstex/orion.commons/utils"
 	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"net/http"
 	structs2 "orion.misc/structs"
@@ -22,12 +25,19 @@ import (
 )
 
 type DeleteStateAction struct {
-	baseAction    micro.BaseAction
-	MetricsStore  *utils.MetricsStore
-	deleteRequest structs.DeleteRequest
-	objectName    string
+	baseAction         micro.BaseAction
+	MetricsStore       *utils.MetricsStore
+	deleteRequest      structs2.DeleteStateRequest
+	objectName         string
+	blockingReferences []structs2.StateReference
+	cascadedRules      []string
+	deletedRules       []structs2.StateTransitionRule
+	stateNotFound      bool
+	startedTime        int64
 }
 
+const StateTransitionRuleDeletedEventTopic = "orion/server/misc/event/statetransitionrule/delete"
+
 func (action *DeleteStateAction) BeforeAction(ctx context.Context, request []byte) *micro.Exception {
 	err := json.Unmarshal(request, &action.deleteRequest)
 	if err != nil {
@@ -67,7 +77,7 @@ func (action *DeleteStateAction) InitBaseAction(baseAction micro.BaseAction) {
 }
 
 func (action DeleteStateAction) SendEvents(request micro.IRequest) {
-	delRequest := request.(*structs.DeleteRequest)
+	delRequest := request.(*structs2.DeleteStateRequest)
 	if !delRequest.Header.WasExecutedSuccessfully {
 		logging.GetLogger("DeleteStateAction",
 			action.GetBaseAction().Environment,
@@ -91,15 +101,31 @@ func (action DeleteStateAction) SendEvents(request micro.IRequest) {
 		return
 	}
 	mqtt.Publish(action.ProvideInformation().EventTopic, json, byte(viper.GetInt("messageBus.publishEventQos")), utils.GetDefaultMqttConnectionOptionsWithIdPrefix(action.ProvideInformation().Name))
+
+	for _, rule := range action.deletedRules {
+		ruleEvent := structs.DeletedEvent{
+			Header:     *micro.NewEventHeaderForAction(action.ProvideInformation(), delRequest.Header.SenderId, ""),
+			ObjectId:   rule.ID.Hex(),
+			ObjectType: "STATE_TRANSITION_RULE",
+			ObjectName: rule.Info.Name,
+		}
+		json, err := ruleEvent.ToJsonString()
+		if err != nil {
+			logging.GetLogger("DeleteStateAction", action.GetBaseAction().Environment, false).WithError(err).Error("Could not send events")
+
+			continue
+		}
+		mqtt.Publish(StateTransitionRuleDeletedEventTopic, json, byte(viper.GetInt("messageBus.publishEventQos")), utils.GetDefaultMqttConnectionOptionsWithIdPrefix(action.ProvideInformation().Name))
+	}
 }
 
 func (action DeleteStateAction) ProvideInformation() micro.ActionInformation {
-	var requestSample = dataStructures.StructToJsonString(structs.DeleteRequest{})
-	var replySample = dataStructures.StructToJsonString(micro.ReplyHeader{})
+	var requestSample = dataStructures.StructToJsonString(structs2.DeleteStateRequest{})
+	var replySample = dataStructures.StructToJsonString(structs2.DeleteStateReply{})
 	var eventSample = dataStructures.StructToJsonString(structs.DeletedEvent{})
 	info := micro.ActionInformation{
 		Name:            "DeleteStateAction",
-		Description:     "Delete a state from the database",
+		Description:     "Delete a state from the database if no state transition rule uses it anymore or cascade the deletion to the rules",
 		RequestTopic:    "orion/server/misc/request/state/delete",
 		ReplyTopic:      "orion/server/misc/reply/state/delete",
 		ErrorReplyTopic: "orion/server/misc/error/state/delete",
@@ -124,6 +150,11 @@ func (action *DeleteStateAction) HandleWebRequest(writer http.ResponseWriter, re
 func (action *DeleteStateAction) HeyHo(ctx context.Context, request []byte) (micro.IReply, micro.IRequest) {
 	start := time.Now()
 	defer action.MetricsStore.HandleActionMetric(start, action.GetBaseAction().Environment, action.ProvideInformation(), *action.baseAction.Token)
+	action.startedTime = utils2.GetCurrentTimeStamp()
+	action.blockingReferences = nil
+	action.cascadedRules = nil
+	action.deletedRules = nil
+	action.stateNotFound = false
 
 	err := json.Unmarshal(request, &action.deleteRequest)
 	if err != nil {
@@ -131,43 +162,140 @@ func (action *DeleteStateAction) HeyHo(ctx context.Context, request []byte) (mic
 			action.ProvideInformation().ErrorReplyTopic), &action.deleteRequest
 	}
 
-	orionErr := action.deleteObject(ctx, action.deleteRequest.ObjectId)
+	reply := structs2.DeleteStateReply{}
+	orionErr := action.deleteObject(ctx, action.deleteRequest.ObjectId, action.deleteRequest.Cascade)
 	if orionErr != nil {
-		return structs.NewErrorReplyHeaderWithOrionErr(orionErr,
-			action.ProvideInformation().ErrorReplyTopic), &action.deleteRequest
+		reply.Header = structs.NewErrorReplyHeaderWithOrionErr(orionErr,
+			action.ProvideInformation().ErrorReplyTopic)
+		reply.BlockingReferences = action.blockingReferences
+
+		return reply, &action.deleteRequest
 	}
 
-	reply := structs.NewReplyHeader(action.ProvideInformation().ReplyTopic)
-	reply.Success = true
+	reply.Header = structs.NewReplyHeader(action.ProvideInformation().ReplyTopic)
+	reply.Header.Success = true
+	reply.CascadedRules = action.cascadedRules
 
 	return reply, &action.deleteRequest
 }
 
-func (action *DeleteStateAction) deleteObject(ctx context.Context, id string) *structs.OrionError {
+// checkReferences collects the references to the state and cascades them to the transition rules if requested.
+// It returns an error if the state must not be deleted.
+func (action *DeleteStateAction) checkReferences(sessCtx mongo.SessionContext, id string, cascade bool) error {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	states, orionErr := getStateDefinitionsFromDb(sessCtx, action.baseAction, bson.M{"_id": objectId})
+	if orionErr != nil {
+		return orionErr.Error
+	}
+	if len(states) == 0 {
+		action.stateNotFound = true
+		return fmt.Errorf("no state with id %v exists", id)
+	}
+	definition, orionErr := loadStateMachineDefinition(sessCtx, action.baseAction, states[0].ReferencedType)
+	if orionErr != nil {
+		return orionErr.Error
+	}
+
+	for _, reference := range definition.ReferencesTo(id) {
+		if !cascade || !reference.Cascadable {
+			action.blockingReferences = append(action.blockingReferences, reference)
+		}
+	}
+	if len(action.blockingReferences) > 0 {
+		return errStateStillReferenced
+	}
+	if !cascade {
+		return nil
+	}
+
+	deletedRules, updatedRules := definition.RemoveState(id)
+	for _, rule := range deletedRules {
+		result, err := mongodb.DeleteAndFindOneById(sessCtx, action.baseAction.Environment.MongoDbConnection, "state_transition_rules", rule.ID.Hex())
+		if err != nil {
+			return err
+		}
+		var ruleToArchive structs2.StateTransitionRule
+		err = result.Decode(&ruleToArchive)
+		if err != nil {
+			return err
+		}
+		ruleToArchive.Info.DeletionDate = &action.startedTime
+		ruleToArchive.ID = nil
+		_, err = mongodb.InsertOne(context.Background(), action.baseAction.Environment.MongoDbArchiveConnection, "state_transition_rules", ruleToArchive)
+		if err != nil {
+			return err
+		}
+		action.cascadedRules = append(action.cascadedRules, rule.ID.Hex())
+		action.deletedRules = append(action.deletedRules, rule)
+	}
+	for _, rule := range updatedRules {
+		rule.Info.ChangeDate = &action.startedTime
+		result, err := mongodb.ReplaceAndFindOneById(sessCtx, action.baseAction.Environment.MongoDbConnection, "state_transition_rules", rule.ID.Hex(), rule)
+		if err != nil {
+			return err
+		}
+		var ruleToArchive structs2.StateTransitionRule
+		err = result.Decode(&ruleToArchive)
+		if err != nil {
+			return err
+		}
+		ruleToArchive.Info.ChangeDate = &action.startedTime
+		ruleToArchive.ID = nil
+		_, err = mongodb.InsertOne(context.Background(), action.baseAction.Environment.MongoDbArchiveConnection, "state_transition_rules", ruleToArchive)
+		if err != nil {
+			return err
+		}
+		action.cascadedRules = append(action.cascadedRules, rule.ID.Hex())
+	}
+
+	return nil
+}
+
+func (action *DeleteStateAction) deleteObject(ctx context.Context, id string, cascade bool) *structs.OrionError {
 	newCtx := context.WithValue(ctx, "id", id)
 	callback := func(sessCtx mongo.SessionContext) (interface{}, error) {
 		callbackId := fmt.Sprintf("%v", newCtx.Value("id"))
+		// the callback runs again if the transaction is retried
+		action.blockingReferences = nil
+		action.cascadedRules = nil
+		action.deletedRules = nil
+		action.stateNotFound = false
+		err := action.checkReferences(sessCtx, callbackId, cascade)
+		if err != nil {
+			return nil, err
+		}
 		result, err := mongodb.DeleteAndFindOneById(sessCtx, action.baseAction.Environment.MongoDbConnection, "states", callbackId)
 		if err != nil {
 			return nil, err
 		}
-		var objectToArchive structs2.Category
+		var objectToArchive structs.State
 		err = result.Decode(&objectToArchive)
 		if err != nil {
 			return nil, err
 		}
-		time := utils2.GetCurrentTimeStamp()
-		objectToArchive.Info.DeletionDate = &time
+		objectToArchive.Info.DeletionDate = &action.startedTime
 		objectToArchive.ID = nil
 
 		_, err = mongodb.InsertOne(context.Background(), action.baseAction.Environment.MongoDbArchiveConnection, "states", objectToArchive)
 
-		return nil, nil
+		return nil, err
 	}
 	_, err := mongodb.PerformQueriesInTransaction(newCtx, action.baseAction.Environment.MongoDbConnection, callback)
+	if action.stateNotFound {
+		return structs.NewOrionError(structs.NoDataFound, fmt.Errorf("no state with id %v exists", id))
+	}
+	if len(action.blockingReferences) > 0 {
+		return structs.NewOrionError(structs2.ReferentialIntegrityError,
+			fmt.Errorf("the state is still referenced %d time(s) and was not deleted", len(action.blockingReferences)))
+	}
 	if err != nil {
 		return structs.NewOrionError(structs.DatabaseError, fmt.Errorf("error executing queries in transaction: %v", err))
 	}
 
 	return nil
 }
+
+var errStateStillReferenced = errors.New("the state is still referenced")
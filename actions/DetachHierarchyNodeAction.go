@@ -0,0 +1,213 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/abenstex/laniakea/dataStructures"
+	"github.com/abenstex/laniakea/logging"
+	"github.com/abenstex/laniakea/micro"
+	"github.com/abenstex/laniakea/mongodb"
+	utils2 "github.com/abenstex/laniakea/utils"
+	"github.com/abenstex/orion.commons/app"
+	http2 "github.com/abenstex/orion.commons/http"
+	structs2 "github.com/abenstex/orion.commons/structs"
+	"github.com/abenstex/orion.commons/utils"
+	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"net/http"
+	"orion.misc/structs"
+	"time"
+)
+
+type DetachHierarchyNodeAction struct {
+	baseAction      micro.BaseAction
+	MetricsStore    *utils.MetricsStore
+	receivedRequest structs.DetachHierarchyNodeRequest
+	event           *structs.HierarchyNodeChangedEvent
+}
+
+func (action *DetachHierarchyNodeAction) BeforeAction(ctx context.Context, request []byte) *micro.Exception {
+	dummy := structs.DetachHierarchyNodeRequest{}
+	err := json.Unmarshal(request, &dummy)
+	if err != nil {
+		return micro.NewException(structs2.UnmarshalError, err)
+	}
+	err = app.DefaultHandleActionRequest(request, &dummy.Header, action, true)
+	if err != nil {
+		return micro.NewException(structs2.RequestHeaderInvalid, err)
+	}
+	if len(dummy.HierarchyId) == 0 || len(dummy.ObjectType) == 0 || len(dummy.ObjectId) == 0 {
+		return micro.NewException(structs2.MissingParameterError,
+			fmt.Errorf("not all parameters (hierarchy_id, object_type and object_id) were provided"))
+	}
+
+	return nil
+}
+
+func (action DetachHierarchyNodeAction) BeforeActionAsync(ctx context.Context, request []byte) {
+
+}
+
+func (action DetachHierarchyNodeAction) AfterAction(ctx context.Context, reply *micro.IReply, request *micro.IRequest) *micro.Exception {
+	return nil
+}
+
+func (action DetachHierarchyNodeAction) AfterActionAsync(ctx context.Context, reply micro.IReply, request micro.IRequest) {
+
+}
+
+func (action DetachHierarchyNodeAction) GetBaseAction() micro.BaseAction {
+	return action.baseAction
+}
+
+func (action *DetachHierarchyNodeAction) SetHttpRequest(request *http.Request) {
+	action.baseAction.Request = request
+}
+
+func (action *DetachHierarchyNodeAction) InitBaseAction(baseAction micro.BaseAction) {
+	action.baseAction = baseAction
+}
+
+func (action DetachHierarchyNodeAction) SendEvents(request micro.IRequest) {
+	nodeRequest := request.(*structs.DetachHierarchyNodeRequest)
+	if !nodeRequest.Header.WasExecutedSuccessfully {
+		logging.GetLogger("DetachHierarchyNodeAction",
+			action.GetBaseAction().Environment,
+			true).Warn("RequestFailedEvent will be sent because the request was not successfully executed")
+		blerghEvent := structs2.NewRequestFailedEvent(nodeRequest, action.ProvideInformation(), action.baseAction.ID.String(), "")
+		blerghEvent.Send(action.ProvideInformation().ErrorReplyTopic, byte(viper.GetInt("messageBus.publishEventQos")),
+			utils.GetDefaultMqttConnectionOptionsWithIdPrefix(action.ProvideInformation().Name))
+		return
+	}
+	if action.event == nil {
+		return
+	}
+
+	sendHierarchyNodeChangedEvent(action.ProvideInformation(), nodeRequest.Header.SenderId, action.baseAction.Environment, *action.event)
+}
+
+func (action DetachHierarchyNodeAction) ProvideInformation() micro.ActionInformation {
+	var reply = "orion/server/misc/reply/hierarchy/node/detach"
+	var error = "orion/server/misc/error/hierarchy/node/detach"
+	var event = "orion/server/misc/event/hierarchy/node/detach"
+	var requestSample = dataStructures.StructToJsonString(structs.DetachHierarchyNodeRequest{})
+	var replySample = dataStructures.StructToJsonString(micro.ReplyHeader{})
+	var eventSample = dataStructures.StructToJsonString(structs.HierarchyNodeChangedEvent{})
+	info := micro.ActionInformation{
+		Name:            "DetachHierarchyNodeAction",
+		Description:     "Detaches an object and all of its descendants from a tree of objects",
+		RequestTopic:    "orion/server/misc/request/hierarchy/node/detach",
+		ReplyTopic:      reply,
+		ErrorReplyTopic: error,
+		Version:         1,
+		ClientId:        action.baseAction.ID.String(),
+		HttpMethods:     []string{http.MethodPost, "OPTIONS"},
+		EventTopic:      event,
+		RequestSample:   &requestSample,
+		ReplySample:     &replySample,
+		EventSample:     &eventSample,
+		IsScriptable:    false,
+	}
+
+	return info
+}
+
+func (action *DetachHierarchyNodeAction) HandleWebRequest(writer http.ResponseWriter, request *http.Request) {
+	action.SetHttpRequest(request)
+	http2.HandleHttpRequest(writer, request, action)
+}
+
+func (action *DetachHierarchyNodeAction) HeyHo(ctx context.Context, request []byte) (micro.IReply, micro.IRequest) {
+	start := time.Now()
+	defer action.MetricsStore.HandleActionMetric(start, action.GetBaseAction().Environment, action.ProvideInformation(), *action.baseAction.Token)
+	action.event = nil
+
+	err := json.Unmarshal(request, &action.receivedRequest)
+	if err != nil {
+		return structs2.NewErrorReplyHeaderWithException(micro.NewException(structs2.UnmarshalError, err),
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	reply, myErr := action.detachNode(ctx, action.receivedRequest)
+	if myErr != nil {
+		logging.GetLogger("DetachHierarchyNodeAction",
+			action.GetBaseAction().Environment,
+			true).WithError(myErr.Error).Error("Data could not be saved")
+		return structs2.NewErrorReplyHeaderWithOrionErr(myErr,
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	return reply, &action.receivedRequest
+}
+
+// detachNode removes the node and its subtree from the tree. The subtree is read and deleted by its path in the same
+// transaction, so nodes that are attached or moved into it concurrently are either detached as well or the
+// transaction fails. The removed nodes are archived once the transaction is committed.
+func (action *DetachHierarchyNodeAction) detachNode(ctx context.Context, request structs.DetachHierarchyNodeRequest) (micro.ReplyHeader, *structs2.OrionError) {
+	var node *structs.HierarchyNode
+	var rejection *structs2.OrionError
+	nodes := make([]structs.HierarchyNode, 0)
+	callback := func(sessCtx mongo.SessionContext) (interface{}, error) {
+		// the callback runs again if the transaction is retried
+		node, rejection = nil, nil
+		nodes = nodes[:0]
+		current, myErr := findHierarchyNode(sessCtx, action.baseAction, request.HierarchyId, request.ObjectType, request.ObjectId)
+		if myErr != nil {
+			rejection = myErr
+			return nil, myErr.Error
+		}
+		descendants, myErr := getHierarchyNodesFromDb(sessCtx, action.baseAction, bson.M{"hierarchy_id": request.HierarchyId, "path": current.ID.Hex()})
+		if myErr != nil {
+			return nil, myErr.Error
+		}
+		result, err := action.baseAction.Environment.MongoDbConnection.Database().Collection("hierarchy_nodes").DeleteMany(sessCtx,
+			bson.M{"hierarchy_id": request.HierarchyId, "$or": bson.A{bson.M{"_id": current.ID}, bson.M{"path": current.ID.Hex()}}})
+		if err != nil {
+			return nil, err
+		}
+		if result.DeletedCount != int64(len(descendants)+1) {
+			err = fmt.Errorf("the subtree of %v %v was changed concurrently, it was not detached", current.ObjectType, current.ObjectId)
+			rejection = structs2.NewOrionError(structs.ValidationError, err)
+			return nil, err
+		}
+		node = current
+		nodes = append(nodes, *current)
+		nodes = append(nodes, descendants...)
+
+		return nil, nil
+	}
+	_, err := mongodb.PerformQueriesInTransaction(ctx, action.baseAction.Environment.MongoDbConnection, callback)
+	if rejection != nil {
+		return micro.ReplyHeader{}, rejection
+	}
+	if err != nil {
+		return micro.ReplyHeader{}, structs2.NewOrionError(structs2.DatabaseError, fmt.Errorf("error executing queries in transaction: %v", err))
+	}
+	// the archive is a different database, it is only written once the nodes are deleted
+	now := utils2.GetCurrentTimeStamp()
+	for _, detached := range nodes {
+		detached.DeletionDate = &now
+		detached.User = &request.Header.User
+		detached.UserComment = &request.Header.Comment
+		if err := archiveHierarchyNode(action.baseAction, detached); err != nil {
+			logging.GetLogger("DetachHierarchyNodeAction", action.GetBaseAction().Environment, true).WithError(err).
+				Error("Could not archive a detached node")
+		}
+	}
+	action.event = &structs.HierarchyNodeChangedEvent{
+		Change:           HierarchyNodeDetached,
+		HierarchyId:      node.HierarchyId,
+		ObjectType:       node.ObjectType,
+		ObjectId:         node.ObjectId,
+		OriginalParentId: node.ParentId,
+		Nodes:            nodes,
+	}
+
+	reply := structs2.NewReplyHeader(action.ProvideInformation().ReplyTopic)
+	reply.Timestamp = utils2.GetCurrentTimeStamp()
+	reply.Success = true
+
+	return reply, nil
+}
@@ -10,8 +10,10 @@ import (
 	http2 "github.com/abenstex/orion.commons/http"
 	structs2 "github.com/abenstex/orion.commons/structs"
 	"github.com/abenstex/orion.commons/utils"
-	"github.com/lib/pq"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"net/http"
+	"orion.misc/attributes"
 	"orion.misc/structs"
 	"time"
 )
@@ -21,17 +23,6 @@ type EvaluateAttributeAction struct {
 	MetricsStore *utils.MetricsStore
 }
 
-type AttributeToEvaluate struct {
-	DataType      string
-	Overwriteable bool
-	Query         *string
-	ObjectType    string
-	DefaultValue  *string
-	ListOfValues  *[]string
-	Value         *string
-	HierarchyId   *int64
-}
-
 func (action EvaluateAttributeAction) BeforeAction(ctx context.Context, request []byte) *micro.Exception {
 	dummy := structs.EvaluateAttributeRequest{}
 	err := json.Unmarshal(request, &dummy)
@@ -39,12 +30,15 @@ func (action EvaluateAttributeAction) BeforeAction(ctx context.Context, request
 		return micro.NewException(structs2.UnmarshalError, err)
 	}
 	err = app.DefaultHandleActionRequest(request, &dummy.Header, &action, true)
-
-	if dummy.AttributeId <= 0 || dummy.ObjectId <= 0 {
-		return micro.NewException(structs2.MissingParameterError, fmt.Errorf("not all parameters (attribute_id and object_id) were provided"))
+	if err != nil {
+		return micro.NewException(structs2.RequestHeaderInvalid, err)
+	}
+	if len(dummy.AttributeId) == 0 || len(dummy.ObjectType) == 0 || len(dummy.ObjectId) == 0 {
+		return micro.NewException(structs2.MissingParameterError,
+			fmt.Errorf("not all parameters (attribute_id, object_type and object_id) were provided"))
 	}
 
-	return micro.NewException(structs2.RequestHeaderInvalid, err)
+	return nil
 }
 
 func (action EvaluateAttributeAction) BeforeActionAsync(ctx context.Context, request []byte) {
@@ -107,31 +101,104 @@ func (action *EvaluateAttributeAction) HeyHo(ctx context.Context, request []byte
 	defer action.MetricsStore.HandleActionMetric(start, action.GetBaseAction().Environment, action.ProvideInformation(), *action.baseAction.Token)
 
 	evaluateRequest := structs.EvaluateAttributeRequest{}
-	json.Unmarshal(request, &evaluateRequest)
+	err := json.Unmarshal(request, &evaluateRequest)
+	if err != nil {
+		return structs2.NewErrorReplyHeaderWithException(micro.NewException(structs2.UnmarshalError, err),
+			action.ProvideInformation().ErrorReplyTopic), &evaluateRequest
+	}
+
+	evaluation, orionErr := action.evaluateAttribute(ctx, evaluateRequest)
+	if orionErr != nil {
+		return structs2.NewErrorReplyHeaderWithOrionErr(orionErr,
+			action.ProvideInformation().ErrorReplyTopic), &evaluateRequest
+	}
 
-	return nil, nil
+	reply := structs.EvaluateAttributeReply{
+		Header: structs2.NewReplyHeader(action.ProvideInformation().ReplyTopic),
+		Value:  evaluation.Value,
+		Source: evaluation.Source,
+		Level:  evaluation.Level,
+	}
+	reply.Header.Success = true
+
+	return reply, &evaluateRequest
 }
 
-func (action *EvaluateAttributeAction) evaluateAttribute(request structs.EvaluateAttributeRequest) (string, *micro.Exception) {
-	query := "SELECT a.datatype, a.overwriteable, a.query, b.object_type, a.default_value, a.list_of_values," +
-		" b.attr_value, c.hierarchy_id" +
-		" FROM attributes a LEFT OUTER JOIN ref_attributes_objects b ON a.id = b.attr_id" +
-		" LEFT OUTER JOIN ref_hierarchies_types c ON b.object_type=c.object_type" +
-		" WHERE a.id=$1 and b.object_id=$2"
+func (action *EvaluateAttributeAction) evaluateAttribute(ctx context.Context, request structs.EvaluateAttributeRequest) (attributes.Evaluation, *structs2.OrionError) {
+	definition, orionErr := getAttributeDefinitionFromDb(ctx, action.baseAction, request.AttributeId)
+	if orionErr != nil {
+		return attributes.Evaluation{}, orionErr
+	}
 
-	rows, err := action.GetBaseAction().Environment.Database.Query(query, request.AttributeId, request.ObjectId)
+	hierarchy, orionErr := action.findHierarchy(ctx, request)
+	if orionErr != nil {
+		return attributes.Evaluation{}, orionErr
+	}
+	levels := attributes.Levels(hierarchy, request.ObjectType, request.ObjectId, request.Ancestors)
+
+	definitions := []structs.AttributeDefinition{*definition}
+	if attributes.IsComputed(*definition) {
+		definitions, orionErr = getAttributeDefinitionsFromDb(ctx, action.baseAction, bson.M{})
+		if orionErr != nil {
+			return attributes.Evaluation{}, orionErr
+		}
+	}
+	var storedErr *structs2.OrionError
+	evaluator := attributes.NewEvaluator(definitions, func(stored structs.AttributeDefinition) (attributes.Evaluation, error) {
+		evaluation, orionErr := action.evaluateStoredAttribute(ctx, stored, levels)
+		if orionErr != nil {
+			storedErr = orionErr
+			return attributes.Evaluation{}, orionErr.Error
+		}
+		return evaluation, nil
+	})
+	evaluation, err := evaluator.Evaluate(*definition)
+	if storedErr != nil {
+		return attributes.Evaluation{}, storedErr
+	}
 	if err != nil {
-		return "", micro.NewException(structs2.DatabaseError, err)
+		return attributes.Evaluation{}, structs2.NewOrionError(structs.ValidationError, err)
+	}
+
+	return evaluation, nil
+}
+
+func (action *EvaluateAttributeAction) evaluateStoredAttribute(ctx context.Context, definition structs.AttributeDefinition,
+	levels []structs.HierarchyLevel) (attributes.Evaluation, *structs2.OrionError) {
+	objectFilters := make(bson.A, 0, len(levels))
+	for _, level := range levels {
+		objectFilters = append(objectFilters, bson.M{"object_type": level.ObjectType, "object_id": level.ObjectId})
+	}
+	storedValues, orionErr := getAttributeValuesFromDb(ctx, action.baseAction,
+		bson.M{"attribute_id": definition.ID.Hex(), "$or": objectFilters})
+	if orionErr != nil {
+		return attributes.Evaluation{}, orionErr
+	}
+	values := make(map[string]string, len(storedValues))
+	for _, value := range storedValues {
+		values[attributes.ValueKey(value.ObjectType, value.ObjectId)] = value.Value
 	}
-	defer rows.Close()
 
-	attribute := AttributeToEvaluate{}
-	err = rows.Scan(&attribute.DataType, &attribute.Overwriteable, &attribute.Query, &attribute.ObjectType,
-		&attribute.DefaultValue, pq.Array(&attribute.ListOfValues), &attribute.Value, &attribute.HierarchyId)
+	return attributes.Evaluate(definition, levels, values), nil
+}
 
-	if !attribute.Overwriteable && attribute.Value != nil {
-		return *attribute.Value, nil
+func (action *EvaluateAttributeAction) findHierarchy(ctx context.Context, request structs.EvaluateAttributeRequest) (*structs.Hierarchy, *structs2.OrionError) {
+	filter := bson.M{"entries.object_type": request.ObjectType}
+	if request.HierarchyId != nil {
+		hierarchyId, err := primitive.ObjectIDFromHex(*request.HierarchyId)
+		if err != nil {
+			return nil, structs2.NewOrionError(structs2.NoDataFound, fmt.Errorf("hierarchy %v does not exist", *request.HierarchyId))
+		}
+		filter["_id"] = hierarchyId
+	}
+	hierarchies, orionErr := getHierarchiesFromDb(ctx, action.baseAction, filter)
+	if orionErr != nil {
+		return nil, orionErr
+	}
+	if request.HierarchyId != nil && len(hierarchies) == 0 {
+		return nil, structs2.NewOrionError(structs2.NoDataFound,
+			fmt.Errorf("hierarchy %v does not contain the object type %v", *request.HierarchyId, request.ObjectType))
 	}
 
-	return "", nil
+	return attributes.FindHierarchy(hierarchies, request.ObjectType), nil
 }
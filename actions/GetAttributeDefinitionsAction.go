@@ -11,8 +11,10 @@ import (
 	http2 "github.com/abenstex/orion.commons/http"
 	structs2 "github.com/abenstex/orion.commons/structs"
 	"github.com/abenstex/orion.commons/utils"
+	"github.com/spf13/viper"
 	"go.mongodb.org/mongo-driver/bson"
 	"net/http"
+	"orion.misc/attributes"
 	"orion.misc/structs"
 	"time"
 )
@@ -72,7 +74,7 @@ func (action GetAttributeDefinitionsAction) ProvideInformation() micro.ActionInf
 	var replySample = dataStructures.StructToJsonString(structs.GetAttributeDefinitionsReply{})
 	info := micro.ActionInformation{
 		Name:            "GetAttributeDefinitionsAction",
-		Description:     "Get attribute definitions based on conditions or all if no conditions were sent in the request",
+		Description:     "Get attribute definitions based on conditions or all if no conditions were sent in the request, labels are resolved for the locale of the request",
 		RequestTopic:    "orion/server/misc/request/attributedefinition/get",
 		ReplyTopic:      reply,
 		ErrorReplyTopic: errorTopic,
@@ -92,7 +94,7 @@ func (action *GetAttributeDefinitionsAction) HandleWebRequest(writer http.Respon
 	http2.HandleHttpRequest(writer, request, action)
 }
 
-func (action GetAttributeDefinitionsAction) createGetAttributeDefinitionsReply(definitions []structs2.AttributeDefinition) (structs.GetAttributeDefinitionsReply, *structs2.OrionError) {
+func (action GetAttributeDefinitionsAction) createGetAttributeDefinitionsReply(definitions []structs.AttributeDefinition) (structs.GetAttributeDefinitionsReply, *structs2.OrionError) {
 	var reply = structs.GetAttributeDefinitionsReply{}
 	reply.Header = structs2.NewReplyHeader(action.ProvideInformation().ReplyTopic)
 	reply.Header.Timestamp = utils2.GetCurrentTimeStamp()
@@ -132,21 +134,28 @@ func (action GetAttributeDefinitionsAction) HeyHo(ctx context.Context, request [
 }
 
 func (action GetAttributeDefinitionsAction) getAttributeDefinitions(ctx context.Context, request structs.GetAttributeDefinitionsRequest) (structs.GetAttributeDefinitionsReply, *structs2.OrionError) {
-	attributes, myErr := action.getAttributeDefinitionsFromDb(ctx, request)
+	definitions, myErr := action.getAttributeDefinitionsFromDb(ctx, request)
 
 	if myErr != nil {
 		return structs.GetAttributeDefinitionsReply{}, myErr
 	}
+	if request.Locale != nil {
+		defaultLocale := viper.GetString("attributes.defaultLocale")
+		for idx, definition := range definitions {
+			labels := attributes.Localize(definition, *request.Locale, defaultLocale)
+			definitions[idx].Labels = &labels
+		}
+	}
 
-	return action.createGetAttributeDefinitionsReply(attributes)
+	return action.createGetAttributeDefinitionsReply(definitions)
 }
 
-func (action GetAttributeDefinitionsAction) getAttributeDefinitionsFromDb(ctx context.Context, request structs.GetAttributeDefinitionsRequest) ([]structs2.AttributeDefinition, *structs2.OrionError) {
+func (action GetAttributeDefinitionsAction) getAttributeDefinitionsFromDb(ctx context.Context, request structs.GetAttributeDefinitionsRequest) ([]structs.AttributeDefinition, *structs2.OrionError) {
 	cursor, err := action.baseAction.Environment.MongoDbConnection.Database().Collection("attribute_definitions").Find(ctx, bson.M{})
 	if err != nil {
 		return nil, structs2.NewOrionError(structs2.DatabaseError, err)
 	}
-	var objects []structs2.AttributeDefinition
+	var objects []structs.AttributeDefinition
 	if err = cursor.All(ctx, &objects); err != nil {
 		return nil, structs2.NewOrionError(structs2.DatabaseError, err)
 	}
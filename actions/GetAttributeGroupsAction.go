@@ -0,0 +1,148 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/abenstex/laniakea/dataStructures"
+	"github.com/abenstex/laniakea/micro"
+	utils2 "github.com/abenstex/laniakea/utils"
+	"github.com/abenstex/orion.commons/app"
+	http2 "github.com/abenstex/orion.commons/http"
+	structs2 "github.com/abenstex/orion.commons/structs"
+	"github.com/abenstex/orion.commons/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"net/http"
+	"orion.misc/structs"
+	"time"
+)
+
+type GetAttributeGroupsAction struct {
+	baseAction      micro.BaseAction
+	MetricsStore    *utils.MetricsStore
+	receivedRequest structs.GetAttributeGroupsRequest
+}
+
+func (action *GetAttributeGroupsAction) BeforeAction(ctx context.Context, request []byte) *micro.Exception {
+	dummy := structs.GetAttributeGroupsRequest{}
+	err := json.Unmarshal(request, &dummy)
+	if err != nil {
+		return micro.NewException(structs2.UnmarshalError, err)
+	}
+	err = app.DefaultHandleActionRequest(request, &dummy.Header, action, true)
+	if err != nil {
+		return micro.NewException(structs2.RequestHeaderInvalid, err)
+	}
+	return nil
+}
+
+func (action GetAttributeGroupsAction) BeforeActionAsync(ctx context.Context, request []byte) {
+
+}
+
+func (action GetAttributeGroupsAction) AfterAction(ctx context.Context, reply *micro.IReply, request *micro.IRequest) *micro.Exception {
+	return nil
+}
+
+func (action GetAttributeGroupsAction) AfterActionAsync(ctx context.Context, reply micro.IReply, request micro.IRequest) {
+
+}
+
+func (action GetAttributeGroupsAction) GetBaseAction() micro.BaseAction {
+	return action.baseAction
+}
+
+func (action *GetAttributeGroupsAction) SetHttpRequest(request *http.Request) {
+	action.baseAction.Request = request
+}
+
+func (action *GetAttributeGroupsAction) InitBaseAction(baseAction micro.BaseAction) {
+	action.baseAction = baseAction
+}
+
+func (action GetAttributeGroupsAction) SendEvents(request micro.IRequest) {
+
+}
+
+func (action GetAttributeGroupsAction) ProvideInformation() micro.ActionInformation {
+	var reply = "orion/server/misc/reply/attributegroup/get"
+	var error = "orion/server/misc/error/attributegroup/get"
+	var requestSample = dataStructures.StructToJsonString(structs.GetAttributeGroupsRequest{})
+	var replySample = dataStructures.StructToJsonString(structs.GetAttributeGroupsReply{})
+	info := micro.ActionInformation{
+		Name:            "GetAttributeGroupsAction",
+		Description:     "Gets the attribute groups ordered by their display order, optionally only the ones that apply to an object type",
+		RequestTopic:    "orion/server/misc/request/attributegroup/get",
+		ReplyTopic:      reply,
+		ErrorReplyTopic: error,
+		Version:         1,
+		ClientId:        action.baseAction.ID.String(),
+		HttpMethods:     []string{http.MethodPost, "OPTIONS"},
+		RequestSample:   &requestSample,
+		ReplySample:     &replySample,
+		IsScriptable:    false,
+	}
+
+	return info
+}
+
+func (action *GetAttributeGroupsAction) HandleWebRequest(writer http.ResponseWriter, request *http.Request) {
+	action.SetHttpRequest(request)
+	http2.HandleHttpRequest(writer, request, action)
+}
+
+func (action *GetAttributeGroupsAction) HeyHo(ctx context.Context, request []byte) (micro.IReply, micro.IRequest) {
+	start := time.Now()
+	defer action.MetricsStore.HandleActionMetric(start, action.GetBaseAction().Environment, action.ProvideInformation(), *action.baseAction.Token)
+
+	err := json.Unmarshal(request, &action.receivedRequest)
+	if err != nil {
+		return structs2.NewErrorReplyHeaderWithException(micro.NewException(structs2.UnmarshalError, err),
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	groups, myErr := getAttributeGroupsFromDb(ctx, action.baseAction, action.createFilter(action.receivedRequest))
+	if myErr != nil {
+		return structs2.NewErrorReplyHeaderWithOrionErr(myErr,
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	reply, myErr := action.createGetAttributeGroupsReply(groups)
+	if myErr != nil {
+		return structs2.NewErrorReplyHeaderWithOrionErr(myErr,
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	return reply, &action.receivedRequest
+}
+
+func (action GetAttributeGroupsAction) createGetAttributeGroupsReply(objects []structs.AttributeGroup) (structs.GetAttributeGroupsReply, *structs2.OrionError) {
+	var reply = structs.GetAttributeGroupsReply{}
+	reply.Header = structs2.NewReplyHeader(action.ProvideInformation().ReplyTopic)
+	reply.Header.Timestamp = utils2.GetCurrentTimeStamp()
+	if len(objects) > 0 {
+		reply.Header.Success = true
+		reply.AttributeGroups = objects
+		return reply, nil
+	}
+	reply.Header.Success = false
+	errorMsg := "No attribute groups were found"
+	reply.Header.ErrorMessage = &errorMsg
+
+	err := errors.New(errorMsg)
+
+	return reply, structs2.NewOrionError(structs2.NoDataFound, err)
+}
+
+func (action GetAttributeGroupsAction) createFilter(request structs.GetAttributeGroupsRequest) bson.M {
+	filter := bson.M{}
+	if request.ObjectType != nil {
+		filter["$or"] = bson.A{
+			bson.M{"object_types": *request.ObjectType},
+			bson.M{"object_types": bson.M{"$size": 0}},
+			bson.M{"object_types": nil},
+		}
+	}
+
+	return filter
+}
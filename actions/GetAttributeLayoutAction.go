@@ -0,0 +1,143 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/abenstex/laniakea/dataStructures"
+	"github.com/abenstex/laniakea/micro"
+	utils2 "github.com/abenstex/laniakea/utils"
+	"github.com/abenstex/orion.commons/app"
+	http2 "github.com/abenstex/orion.commons/http"
+	structs2 "github.com/abenstex/orion.commons/structs"
+	"github.com/abenstex/orion.commons/utils"
+	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/bson"
+	"net/http"
+	"orion.misc/attributes"
+	"orion.misc/structs"
+	"time"
+)
+
+type GetAttributeLayoutAction struct {
+	baseAction      micro.BaseAction
+	MetricsStore    *utils.MetricsStore
+	receivedRequest structs.GetAttributeLayoutRequest
+}
+
+func (action *GetAttributeLayoutAction) BeforeAction(ctx context.Context, request []byte) *micro.Exception {
+	dummy := structs.GetAttributeLayoutRequest{}
+	err := json.Unmarshal(request, &dummy)
+	if err != nil {
+		return micro.NewException(structs2.UnmarshalError, err)
+	}
+	err = app.DefaultHandleActionRequest(request, &dummy.Header, action, true)
+	if err != nil {
+		return micro.NewException(structs2.RequestHeaderInvalid, err)
+	}
+	if len(dummy.ObjectType) == 0 {
+		return micro.NewException(structs2.MissingParameterError, fmt.Errorf("the parameter object_type was not provided"))
+	}
+
+	return nil
+}
+
+func (action GetAttributeLayoutAction) BeforeActionAsync(ctx context.Context, request []byte) {
+
+}
+
+func (action GetAttributeLayoutAction) AfterAction(ctx context.Context, reply *micro.IReply, request *micro.IRequest) *micro.Exception {
+	return nil
+}
+
+func (action GetAttributeLayoutAction) AfterActionAsync(ctx context.Context, reply micro.IReply, request micro.IRequest) {
+
+}
+
+func (action GetAttributeLayoutAction) GetBaseAction() micro.BaseAction {
+	return action.baseAction
+}
+
+func (action *GetAttributeLayoutAction) SetHttpRequest(request *http.Request) {
+	action.baseAction.Request = request
+}
+
+func (action *GetAttributeLayoutAction) InitBaseAction(baseAction micro.BaseAction) {
+	action.baseAction = baseAction
+}
+
+func (action GetAttributeLayoutAction) SendEvents(request micro.IRequest) {
+
+}
+
+func (action GetAttributeLayoutAction) ProvideInformation() micro.ActionInformation {
+	var reply = "orion/server/misc/reply/attributegroup/layout/get"
+	var error = "orion/server/misc/error/attributegroup/layout/get"
+	var requestSample = dataStructures.StructToJsonString(structs.GetAttributeLayoutRequest{})
+	var replySample = dataStructures.StructToJsonString(structs.GetAttributeLayoutReply{})
+	info := micro.ActionInformation{
+		Name:            "GetAttributeLayoutAction",
+		Description:     "Gets the grouped and ordered attributes of an object type for display",
+		RequestTopic:    "orion/server/misc/request/attributegroup/layout/get",
+		ReplyTopic:      reply,
+		ErrorReplyTopic: error,
+		Version:         1,
+		ClientId:        action.baseAction.ID.String(),
+		HttpMethods:     []string{http.MethodPost, "OPTIONS"},
+		RequestSample:   &requestSample,
+		ReplySample:     &replySample,
+		IsScriptable:    false,
+	}
+
+	return info
+}
+
+func (action *GetAttributeLayoutAction) HandleWebRequest(writer http.ResponseWriter, request *http.Request) {
+	action.SetHttpRequest(request)
+	http2.HandleHttpRequest(writer, request, action)
+}
+
+func (action *GetAttributeLayoutAction) HeyHo(ctx context.Context, request []byte) (micro.IReply, micro.IRequest) {
+	start := time.Now()
+	defer action.MetricsStore.HandleActionMetric(start, action.GetBaseAction().Environment, action.ProvideInformation(), *action.baseAction.Token)
+
+	err := json.Unmarshal(request, &action.receivedRequest)
+	if err != nil {
+		return structs2.NewErrorReplyHeaderWithException(micro.NewException(structs2.UnmarshalError, err),
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	reply, myErr := action.getLayout(ctx, action.receivedRequest)
+	if myErr != nil {
+		return structs2.NewErrorReplyHeaderWithOrionErr(myErr,
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	return reply, &action.receivedRequest
+}
+
+func (action GetAttributeLayoutAction) getLayout(ctx context.Context, request structs.GetAttributeLayoutRequest) (structs.GetAttributeLayoutReply, *structs2.OrionError) {
+	definitions, myErr := getAttributeDefinitionsFromDb(ctx, action.baseAction, bson.M{})
+	if myErr != nil {
+		return structs.GetAttributeLayoutReply{}, myErr
+	}
+	if request.Locale != nil {
+		defaultLocale := viper.GetString("attributes.defaultLocale")
+		for idx, definition := range definitions {
+			labels := attributes.Localize(definition, *request.Locale, defaultLocale)
+			definitions[idx].Labels = &labels
+		}
+	}
+	groups, myErr := getAttributeGroupsFromDb(ctx, action.baseAction, bson.M{})
+	if myErr != nil {
+		return structs.GetAttributeLayoutReply{}, myErr
+	}
+
+	var reply = structs.GetAttributeLayoutReply{}
+	reply.Header = structs2.NewReplyHeader(action.ProvideInformation().ReplyTopic)
+	reply.Header.Timestamp = utils2.GetCurrentTimeStamp()
+	reply.Header.Success = true
+	reply.Layout = attributes.Layout(request.ObjectType, groups, definitions)
+
+	return reply, nil
+}
@@ -0,0 +1,159 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/abenstex/laniakea/dataStructures"
+	"github.com/abenstex/laniakea/micro"
+	utils2 "github.com/abenstex/laniakea/utils"
+	"github.com/abenstex/orion.commons/app"
+	http2 "github.com/abenstex/orion.commons/http"
+	structs2 "github.com/abenstex/orion.commons/structs"
+	"github.com/abenstex/orion.commons/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"net/http"
+	"orion.misc/structs"
+	"time"
+)
+
+type GetAttributeValueChangeHistoryAction struct {
+	baseAction      micro.BaseAction
+	MetricsStore    *utils.MetricsStore
+	receivedRequest structs.GetAttributeValueChangeHistoryRequest
+}
+
+func (action *GetAttributeValueChangeHistoryAction) BeforeAction(ctx context.Context, request []byte) *micro.Exception {
+	dummy := structs.GetAttributeValueChangeHistoryRequest{}
+	err := json.Unmarshal(request, &dummy)
+	if err != nil {
+		return micro.NewException(structs2.UnmarshalError, err)
+	}
+	err = app.DefaultHandleActionRequest(request, &dummy.Header, action, true)
+	if err != nil {
+		return micro.NewException(structs2.RequestHeaderInvalid, err)
+	}
+	if len(dummy.ObjectType) == 0 || len(dummy.ObjectId) == 0 {
+		return micro.NewException(structs2.MissingParameterError, fmt.Errorf("not all parameters (object_type and object_id) were provided"))
+	}
+
+	return nil
+}
+
+func (action GetAttributeValueChangeHistoryAction) BeforeActionAsync(ctx context.Context, request []byte) {
+
+}
+
+func (action GetAttributeValueChangeHistoryAction) AfterAction(ctx context.Context, reply *micro.IReply, request *micro.IRequest) *micro.Exception {
+	return nil
+}
+
+func (action GetAttributeValueChangeHistoryAction) AfterActionAsync(ctx context.Context, reply micro.IReply, request micro.IRequest) {
+
+}
+
+func (action GetAttributeValueChangeHistoryAction) GetBaseAction() micro.BaseAction {
+	return action.baseAction
+}
+
+func (action *GetAttributeValueChangeHistoryAction) SetHttpRequest(request *http.Request) {
+	action.baseAction.Request = request
+}
+
+func (action *GetAttributeValueChangeHistoryAction) InitBaseAction(baseAction micro.BaseAction) {
+	action.baseAction = baseAction
+}
+
+func (action GetAttributeValueChangeHistoryAction) SendEvents(request micro.IRequest) {
+
+}
+
+func (action GetAttributeValueChangeHistoryAction) ProvideInformation() micro.ActionInformation {
+	var reply = "orion/server/misc/reply/attribute/value/history/get"
+	var error = "orion/server/misc/error/attribute/value/history/get"
+	var requestSample = dataStructures.StructToJsonString(structs.GetAttributeValueChangeHistoryRequest{})
+	var replySample = dataStructures.StructToJsonString(structs.GetAttributeValueChangeHistoryReply{})
+	info := micro.ActionInformation{
+		Name:            "GetAttributeValueChangeHistoryAction",
+		Description:     "Gets the timeline of attribute value changes of an object from the archive",
+		RequestTopic:    "orion/server/misc/request/attribute/value/history/get",
+		ReplyTopic:      reply,
+		ErrorReplyTopic: error,
+		Version:         1,
+		ClientId:        action.baseAction.ID.String(),
+		HttpMethods:     []string{http.MethodPost, "OPTIONS"},
+		RequestSample:   &requestSample,
+		ReplySample:     &replySample,
+		IsScriptable:    false,
+	}
+
+	return info
+}
+
+func (action *GetAttributeValueChangeHistoryAction) HandleWebRequest(writer http.ResponseWriter, request *http.Request) {
+	action.SetHttpRequest(request)
+	http2.HandleHttpRequest(writer, request, action)
+}
+
+func (action *GetAttributeValueChangeHistoryAction) HeyHo(ctx context.Context, request []byte) (micro.IReply, micro.IRequest) {
+	start := time.Now()
+	defer action.MetricsStore.HandleActionMetric(start, action.GetBaseAction().Environment, action.ProvideInformation(), *action.baseAction.Token)
+
+	err := json.Unmarshal(request, &action.receivedRequest)
+	if err != nil {
+		return structs2.NewErrorReplyHeaderWithException(micro.NewException(structs2.UnmarshalError, err),
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	changes, myErr := getAttributeValueChangesFromDb(ctx, action.baseAction, action.createFilter(action.receivedRequest))
+	if myErr != nil {
+		return structs2.NewErrorReplyHeaderWithOrionErr(myErr,
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	reply, myErr := action.createGetAttributeValueChangeHistoryReply(changes)
+	if myErr != nil {
+		return structs2.NewErrorReplyHeaderWithOrionErr(myErr,
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	return reply, &action.receivedRequest
+}
+
+func (action GetAttributeValueChangeHistoryAction) createGetAttributeValueChangeHistoryReply(objects []structs.AttributeValueChange) (structs.GetAttributeValueChangeHistoryReply, *structs2.OrionError) {
+	var reply = structs.GetAttributeValueChangeHistoryReply{}
+	reply.Header = structs2.NewReplyHeader(action.ProvideInformation().ReplyTopic)
+	reply.Header.Timestamp = utils2.GetCurrentTimeStamp()
+	if len(objects) > 0 {
+		reply.Header.Success = true
+		reply.Changes = objects
+		return reply, nil
+	}
+	reply.Header.Success = false
+	errorMsg := "No attribute value changes were found"
+	reply.Header.ErrorMessage = &errorMsg
+
+	err := errors.New(errorMsg)
+
+	return reply, structs2.NewOrionError(structs2.NoDataFound, err)
+}
+
+func (action GetAttributeValueChangeHistoryAction) createFilter(request structs.GetAttributeValueChangeHistoryRequest) bson.M {
+	filter := bson.M{"object_type": request.ObjectType, "object_id": request.ObjectId}
+	if request.AttributeId != nil {
+		filter["attribute_id"] = *request.AttributeId
+	}
+	timeRange := bson.M{}
+	if request.From != nil {
+		timeRange["$gte"] = *request.From
+	}
+	if request.To != nil {
+		timeRange["$lte"] = *request.To
+	}
+	if len(timeRange) > 0 {
+		filter["timestamp"] = timeRange
+	}
+
+	return filter
+}
@@ -0,0 +1,155 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/abenstex/laniakea/dataStructures"
+	"github.com/abenstex/laniakea/micro"
+	utils2 "github.com/abenstex/laniakea/utils"
+	"github.com/abenstex/orion.commons/app"
+	http2 "github.com/abenstex/orion.commons/http"
+	structs2 "github.com/abenstex/orion.commons/structs"
+	"github.com/abenstex/orion.commons/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"net/http"
+	"orion.misc/structs"
+	"time"
+)
+
+type GetAttributeValuesAction struct {
+	baseAction      micro.BaseAction
+	MetricsStore    *utils.MetricsStore
+	receivedRequest structs.GetAttributeValuesRequest
+}
+
+func (action *GetAttributeValuesAction) BeforeAction(ctx context.Context, request []byte) *micro.Exception {
+	dummy := structs.GetAttributeValuesRequest{}
+	err := json.Unmarshal(request, &dummy)
+	if err != nil {
+		return micro.NewException(structs2.UnmarshalError, err)
+	}
+	err = app.DefaultHandleActionRequest(request, &dummy.Header, action, true)
+	if err != nil {
+		return micro.NewException(structs2.RequestHeaderInvalid, err)
+	}
+	if dummy.AttributeId == nil && dummy.ObjectId == nil {
+		return micro.NewException(structs2.MissingParameterError, fmt.Errorf("either attribute_id or object_id must be provided"))
+	}
+
+	return nil
+}
+
+func (action GetAttributeValuesAction) BeforeActionAsync(ctx context.Context, request []byte) {
+
+}
+
+func (action GetAttributeValuesAction) AfterAction(ctx context.Context, reply *micro.IReply, request *micro.IRequest) *micro.Exception {
+	return nil
+}
+
+func (action GetAttributeValuesAction) AfterActionAsync(ctx context.Context, reply micro.IReply, request micro.IRequest) {
+
+}
+
+func (action GetAttributeValuesAction) GetBaseAction() micro.BaseAction {
+	return action.baseAction
+}
+
+func (action *GetAttributeValuesAction) SetHttpRequest(request *http.Request) {
+	action.baseAction.Request = request
+}
+
+func (action *GetAttributeValuesAction) InitBaseAction(baseAction micro.BaseAction) {
+	action.baseAction = baseAction
+}
+
+func (action GetAttributeValuesAction) SendEvents(request micro.IRequest) {
+
+}
+
+func (action GetAttributeValuesAction) ProvideInformation() micro.ActionInformation {
+	var reply = "orion/server/misc/reply/attribute/value/get"
+	var error = "orion/server/misc/error/attribute/value/get"
+	var requestSample = dataStructures.StructToJsonString(structs.GetAttributeValuesRequest{})
+	var replySample = dataStructures.StructToJsonString(structs.GetAttributeValuesReply{})
+	info := micro.ActionInformation{
+		Name:            "GetAttributeValuesAction",
+		Description:     "Gets the attribute values set on objects, filtered by attribute and/or object",
+		RequestTopic:    "orion/server/misc/request/attribute/value/get",
+		ReplyTopic:      reply,
+		ErrorReplyTopic: error,
+		Version:         1,
+		ClientId:        action.baseAction.ID.String(),
+		HttpMethods:     []string{http.MethodPost, "OPTIONS"},
+		RequestSample:   &requestSample,
+		ReplySample:     &replySample,
+		IsScriptable:    false,
+	}
+
+	return info
+}
+
+func (action *GetAttributeValuesAction) HandleWebRequest(writer http.ResponseWriter, request *http.Request) {
+	action.SetHttpRequest(request)
+	http2.HandleHttpRequest(writer, request, action)
+}
+
+func (action *GetAttributeValuesAction) HeyHo(ctx context.Context, request []byte) (micro.IReply, micro.IRequest) {
+	start := time.Now()
+	defer action.MetricsStore.HandleActionMetric(start, action.GetBaseAction().Environment, action.ProvideInformation(), *action.baseAction.Token)
+
+	err := json.Unmarshal(request, &action.receivedRequest)
+	if err != nil {
+		return structs2.NewErrorReplyHeaderWithException(micro.NewException(structs2.UnmarshalError, err),
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	values, myErr := getAttributeValuesFromDb(ctx, action.baseAction, action.createFilter(action.receivedRequest))
+	if myErr != nil {
+		return structs2.NewErrorReplyHeaderWithOrionErr(myErr,
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	reply, myErr := action.createGetAttributeValuesReply(values)
+	if myErr != nil {
+		return structs2.NewErrorReplyHeaderWithOrionErr(myErr,
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	return reply, &action.receivedRequest
+}
+
+func (action GetAttributeValuesAction) createGetAttributeValuesReply(objects []structs.AttributeValue) (structs.GetAttributeValuesReply, *structs2.OrionError) {
+	var reply = structs.GetAttributeValuesReply{}
+	reply.Header = structs2.NewReplyHeader(action.ProvideInformation().ReplyTopic)
+	reply.Header.Timestamp = utils2.GetCurrentTimeStamp()
+	if len(objects) > 0 {
+		reply.Header.Success = true
+		reply.Attributes = objects
+		return reply, nil
+	}
+	reply.Header.Success = false
+	errorMsg := "No attribute values were found"
+	reply.Header.ErrorMessage = &errorMsg
+
+	err := errors.New(errorMsg)
+
+	return reply, structs2.NewOrionError(structs2.NoDataFound, err)
+}
+
+func (action GetAttributeValuesAction) createFilter(request structs.GetAttributeValuesRequest) bson.M {
+	filter := bson.M{}
+	if request.AttributeId != nil {
+		filter["attribute_id"] = *request.AttributeId
+	}
+	if request.ObjectType != nil {
+		filter["object_type"] = *request.ObjectType
+	}
+	if request.ObjectId != nil {
+		filter["object_id"] = *request.ObjectId
+	}
+
+	return filter
+}
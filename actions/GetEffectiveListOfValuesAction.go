@@ -0,0 +1,172 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/abenstex/laniakea/dataStructures"
+	"github.com/abenstex/laniakea/micro"
+	utils2 "github.com/abenstex/laniakea/utils"
+	"github.com/abenstex/orion.commons/app"
+	http2 "github.com/abenstex/orion.commons/http"
+	structs2 "github.com/abenstex/orion.commons/structs"
+	"github.com/abenstex/orion.commons/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"net/http"
+	"orion.misc/attributes"
+	"orion.misc/structs"
+	"time"
+)
+
+type GetEffectiveListOfValuesAction struct {
+	baseAction      micro.BaseAction
+	MetricsStore    *utils.MetricsStore
+	receivedRequest structs.GetEffectiveListOfValuesRequest
+}
+
+func (action *GetEffectiveListOfValuesAction) BeforeAction(ctx context.Context, request []byte) *micro.Exception {
+	dummy := structs.GetEffectiveListOfValuesRequest{}
+	err := json.Unmarshal(request, &dummy)
+	if err != nil {
+		return micro.NewException(structs2.UnmarshalError, err)
+	}
+	err = app.DefaultHandleActionRequest(request, &dummy.Header, action, true)
+	if err != nil {
+		return micro.NewException(structs2.RequestHeaderInvalid, err)
+	}
+	if len(dummy.AttributeId) == 0 {
+		return micro.NewException(structs2.MissingParameterError, fmt.Errorf("the parameter attribute_id was not provided"))
+	}
+
+	return nil
+}
+
+func (action GetEffectiveListOfValuesAction) BeforeActionAsync(ctx context.Context, request []byte) {
+
+}
+
+func (action GetEffectiveListOfValuesAction) AfterAction(ctx context.Context, reply *micro.IReply, request *micro.IRequest) *micro.Exception {
+	return nil
+}
+
+func (action GetEffectiveListOfValuesAction) AfterActionAsync(ctx context.Context, reply micro.IReply, request micro.IRequest) {
+
+}
+
+func (action GetEffectiveListOfValuesAction) GetBaseAction() micro.BaseAction {
+	return action.baseAction
+}
+
+func (action *GetEffectiveListOfValuesAction) SetHttpRequest(request *http.Request) {
+	action.baseAction.Request = request
+}
+
+func (action *GetEffectiveListOfValuesAction) InitBaseAction(baseAction micro.BaseAction) {
+	action.baseAction = baseAction
+}
+
+func (action GetEffectiveListOfValuesAction) SendEvents(request micro.IRequest) {
+
+}
+
+func (action GetEffectiveListOfValuesAction) ProvideInformation() micro.ActionInformation {
+	var reply = "orion/server/misc/reply/attribute/listofvalues/get"
+	var error = "orion/server/misc/error/attribute/listofvalues/get"
+	var requestSample = dataStructures.StructToJsonString(structs.GetEffectiveListOfValuesRequest{})
+	var replySample = dataStructures.StructToJsonString(structs.GetEffectiveListOfValuesReply{})
+	info := micro.ActionInformation{
+		Name:            "GetEffectiveListOfValuesAction",
+		Description:     "Gets the values that can be chosen for an attribute given the values chosen so far for the object",
+		RequestTopic:    "orion/server/misc/request/attribute/listofvalues/get",
+		ReplyTopic:      reply,
+		ErrorReplyTopic: error,
+		Version:         1,
+		ClientId:        action.baseAction.ID.String(),
+		HttpMethods:     []string{http.MethodPost, "OPTIONS"},
+		RequestSample:   &requestSample,
+		ReplySample:     &replySample,
+		IsScriptable:    false,
+	}
+
+	return info
+}
+
+func (action *GetEffectiveListOfValuesAction) HandleWebRequest(writer http.ResponseWriter, request *http.Request) {
+	action.SetHttpRequest(request)
+	http2.HandleHttpRequest(writer, request, action)
+}
+
+func (action *GetEffectiveListOfValuesAction) HeyHo(ctx context.Context, request []byte) (micro.IReply, micro.IRequest) {
+	start := time.Now()
+	defer action.MetricsStore.HandleActionMetric(start, action.GetBaseAction().Environment, action.ProvideInformation(), *action.baseAction.Token)
+
+	err := json.Unmarshal(request, &action.receivedRequest)
+	if err != nil {
+		return structs2.NewErrorReplyHeaderWithException(micro.NewException(structs2.UnmarshalError, err),
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	reply, myErr := action.getEffectiveListOfValues(ctx, action.receivedRequest)
+	if myErr != nil {
+		return structs2.NewErrorReplyHeaderWithOrionErr(myErr,
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	return reply, &action.receivedRequest
+}
+
+func (action GetEffectiveListOfValuesAction) getEffectiveListOfValues(ctx context.Context, request structs.GetEffectiveListOfValuesRequest) (structs.GetEffectiveListOfValuesReply, *structs2.OrionError) {
+	definitions, myErr := getAttributeDefinitionsFromDb(ctx, action.baseAction, bson.M{})
+	if myErr != nil {
+		return structs.GetEffectiveListOfValuesReply{}, myErr
+	}
+	definition := attributes.FindDefinition(definitions, request.AttributeId)
+	if definition == nil {
+		return structs.GetEffectiveListOfValuesReply{}, structs2.NewOrionError(structs2.NoDataFound,
+			fmt.Errorf("attribute definition %v does not exist", request.AttributeId))
+	}
+
+	var reply = structs.GetEffectiveListOfValuesReply{}
+	reply.Header = structs2.NewReplyHeader(action.ProvideInformation().ReplyTopic)
+	reply.Header.Timestamp = utils2.GetCurrentTimeStamp()
+	var parentValue *string
+	var parentValues []string
+	if definition.DependsOn != nil {
+		reply.ParentAttribute = &definition.DependsOn.ParentAttribute
+		parentValue, parentValues, myErr = action.findParentValue(ctx, request, definitions, *definition)
+		if myErr != nil {
+			return structs.GetEffectiveListOfValuesReply{}, myErr
+		}
+	}
+	reply.Values, reply.Restricted = attributes.EffectiveListOfValues(*definition, parentValues)
+	reply.ParentValue = parentValue
+	reply.Header.Success = true
+
+	return reply, nil
+}
+
+// findParentValue returns the value of the parent attribute as it was sent or stored and its single values
+func (action GetEffectiveListOfValuesAction) findParentValue(ctx context.Context, request structs.GetEffectiveListOfValuesRequest,
+	definitions []structs.AttributeDefinition, definition structs.AttributeDefinition) (*string, []string, *structs2.OrionError) {
+	parent := attributes.FindDefinition(definitions, definition.DependsOn.ParentAttribute)
+	if parent == nil {
+		return nil, nil, nil
+	}
+	for _, key := range []string{parent.ID.Hex(), parent.Info.Name} {
+		if value, ok := request.Object[key]; ok {
+			return &value, attributes.ValueList(*parent, attributes.NewValue(*parent, "", "", value)), nil
+		}
+	}
+	if request.ObjectType == nil || request.ObjectId == nil {
+		return nil, nil, nil
+	}
+	stored, err := getAttributeValueFromDb(ctx, action.baseAction, parent.ID.Hex(), *request.ObjectType, *request.ObjectId)
+	if err != nil {
+		return nil, nil, structs2.NewOrionError(structs2.DatabaseError, err)
+	}
+	if stored == nil {
+		return nil, nil, nil
+	}
+
+	return &stored.Value, attributes.ValueList(*parent, *stored), nil
+}
@@ -0,0 +1,146 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/abenstex/laniakea/dataStructures"
+	"github.com/abenstex/laniakea/micro"
+	utils2 "github.com/abenstex/laniakea/utils"
+	"github.com/abenstex/orion.commons/app"
+	http2 "github.com/abenstex/orion.commons/http"
+	structs2 "github.com/abenstex/orion.commons/structs"
+	"github.com/abenstex/orion.commons/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"net/http"
+	"orion.misc/structs"
+	"time"
+)
+
+type GetHierarchyAncestorsAction struct {
+	baseAction      micro.BaseAction
+	MetricsStore    *utils.MetricsStore
+	receivedRequest structs.GetHierarchyAncestorsRequest
+}
+
+func (action *GetHierarchyAncestorsAction) BeforeAction(ctx context.Context, request []byte) *micro.Exception {
+	dummy := structs.GetHierarchyAncestorsRequest{}
+	err := json.Unmarshal(request, &dummy)
+	if err != nil {
+		return micro.NewException(structs2.UnmarshalError, err)
+	}
+	err = app.DefaultHandleActionRequest(request, &dummy.Header, action, true)
+	if err != nil {
+		return micro.NewException(structs2.RequestHeaderInvalid, err)
+	}
+	if len(dummy.HierarchyId) == 0 || len(dummy.ObjectType) == 0 || len(dummy.ObjectId) == 0 {
+		return micro.NewException(structs2.MissingParameterError,
+			fmt.Errorf("not all parameters (hierarchy_id, object_type and object_id) were provided"))
+	}
+
+	return nil
+}
+
+func (action GetHierarchyAncestorsAction) BeforeActionAsync(ctx context.Context, request []byte) {
+
+}
+
+func (action GetHierarchyAncestorsAction) AfterAction(ctx context.Context, reply *micro.IReply, request *micro.IRequest) *micro.Exception {
+	return nil
+}
+
+func (action GetHierarchyAncestorsAction) AfterActionAsync(ctx context.Context, reply micro.IReply, request micro.IRequest) {
+
+}
+
+func (action GetHierarchyAncestorsAction) GetBaseAction() micro.BaseAction {
+	return action.baseAction
+}
+
+func (action *GetHierarchyAncestorsAction) SetHttpRequest(request *http.Request) {
+	action.baseAction.Request = request
+}
+
+func (action *GetHierarchyAncestorsAction) InitBaseAction(baseAction micro.BaseAction) {
+	action.baseAction = baseAction
+}
+
+func (action GetHierarchyAncestorsAction) SendEvents(request micro.IRequest) {
+
+}
+
+func (action GetHierarchyAncestorsAction) ProvideInformation() micro.ActionInformation {
+	var reply = "orion/server/misc/reply/hierarchy/node/ancestors/get"
+	var error = "orion/server/misc/error/hierarchy/node/ancestors/get"
+	var requestSample = dataStructures.StructToJsonString(structs.GetHierarchyAncestorsRequest{})
+	var replySample = dataStructures.StructToJsonString(structs.GetHierarchyAncestorsReply{})
+	info := micro.ActionInformation{
+		Name:            "GetHierarchyAncestorsAction",
+		Description:     "Gets the path of ancestors of an object within a tree of objects, from the root down to its parent",
+		RequestTopic:    "orion/server/misc/request/hierarchy/node/ancestors/get",
+		ReplyTopic:      reply,
+		ErrorReplyTopic: error,
+		Version:         1,
+		ClientId:        action.baseAction.ID.String(),
+		HttpMethods:     []string{http.MethodPost, "OPTIONS"},
+		RequestSample:   &requestSample,
+		ReplySample:     &replySample,
+		IsScriptable:    false,
+	}
+
+	return info
+}
+
+func (action *GetHierarchyAncestorsAction) HandleWebRequest(writer http.ResponseWriter, request *http.Request) {
+	action.SetHttpRequest(request)
+	http2.HandleHttpRequest(writer, request, action)
+}
+
+func (action *GetHierarchyAncestorsAction) HeyHo(ctx context.Context, request []byte) (micro.IReply, micro.IRequest) {
+	start := time.Now()
+	defer action.MetricsStore.HandleActionMetric(start, action.GetBaseAction().Environment, action.ProvideInformation(), *action.baseAction.Token)
+
+	err := json.Unmarshal(request, &action.receivedRequest)
+	if err != nil {
+		return structs2.NewErrorReplyHeaderWithException(micro.NewException(structs2.UnmarshalError, err),
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	reply, myErr := action.getAncestors(ctx, action.receivedRequest)
+	if myErr != nil {
+		return structs2.NewErrorReplyHeaderWithOrionErr(myErr,
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	return reply, &action.receivedRequest
+}
+
+func (action GetHierarchyAncestorsAction) getAncestors(ctx context.Context, request structs.GetHierarchyAncestorsRequest) (structs.GetHierarchyAncestorsReply, *structs2.OrionError) {
+	node, myErr := findHierarchyNode(ctx, action.baseAction, request.HierarchyId, request.ObjectType, request.ObjectId)
+	if myErr != nil {
+		return structs.GetHierarchyAncestorsReply{}, myErr
+	}
+	ids := make(bson.A, 0, len(node.Path))
+	for _, ancestorId := range node.Path {
+		objectId, err := primitive.ObjectIDFromHex(ancestorId)
+		if err != nil {
+			return structs.GetHierarchyAncestorsReply{}, structs2.NewOrionError(structs2.DatabaseError, err)
+		}
+		ids = append(ids, objectId)
+	}
+	ancestors := make([]structs.HierarchyNode, 0)
+	if len(ids) > 0 {
+		ancestors, myErr = getHierarchyNodesFromDb(ctx, action.baseAction, bson.M{"_id": bson.M{"$in": ids}})
+		if myErr != nil {
+			return structs.GetHierarchyAncestorsReply{}, myErr
+		}
+	}
+
+	var reply = structs.GetHierarchyAncestorsReply{Ancestors: ancestors}
+	reply.Header = structs2.NewReplyHeader(action.ProvideInformation().ReplyTopic)
+	reply.Header.Timestamp = utils2.GetCurrentTimeStamp()
+	reply.Header.Success = true
+
+	return reply, nil
+}
@@ -0,0 +1,147 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/abenstex/laniakea/dataStructures"
+	"github.com/abenstex/laniakea/micro"
+	utils2 "github.com/abenstex/laniakea/utils"
+	"github.com/abenstex/orion.commons/app"
+	http2 "github.com/abenstex/orion.commons/http"
+	structs2 "github.com/abenstex/orion.commons/structs"
+	"github.com/abenstex/orion.commons/utils"
+	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/bson"
+	"net/http"
+	"orion.misc/hierarchy"
+	"orion.misc/structs"
+	"time"
+)
+
+const defaultMaxSubtreeDepth = 10
+
+type GetHierarchySubtreeAction struct {
+	baseAction      micro.BaseAction
+	MetricsStore    *utils.MetricsStore
+	receivedRequest structs.GetHierarchySubtreeRequest
+}
+
+func (action *GetHierarchySubtreeAction) BeforeAction(ctx context.Context, request []byte) *micro.Exception {
+	dummy := structs.GetHierarchySubtreeRequest{}
+	err := json.Unmarshal(request, &dummy)
+	if err != nil {
+		return micro.NewException(structs2.UnmarshalError, err)
+	}
+	err = app.DefaultHandleActionRequest(request, &dummy.Header, action, true)
+	if err != nil {
+		return micro.NewException(structs2.RequestHeaderInvalid, err)
+	}
+	if len(dummy.HierarchyId) == 0 || len(dummy.ObjectType) == 0 || len(dummy.ObjectId) == 0 {
+		return micro.NewException(structs2.MissingParameterError,
+			fmt.Errorf("not all parameters (hierarchy_id, object_type and object_id) were provided"))
+	}
+
+	return nil
+}
+
+func (action GetHierarchySubtreeAction) BeforeActionAsync(ctx context.Context, request []byte) {
+
+}
+
+func (action GetHierarchySubtreeAction) AfterAction(ctx context.Context, reply *micro.IReply, request *micro.IRequest) *micro.Exception {
+	return nil
+}
+
+func (action GetHierarchySubtreeAction) AfterActionAsync(ctx context.Context, reply micro.IReply, request micro.IRequest) {
+
+}
+
+func (action GetHierarchySubtreeAction) GetBaseAction() micro.BaseAction {
+	return action.baseAction
+}
+
+func (action *GetHierarchySubtreeAction) SetHttpRequest(request *http.Request) {
+	action.baseAction.Request = request
+}
+
+func (action *GetHierarchySubtreeAction) InitBaseAction(baseAction micro.BaseAction) {
+	action.baseAction = baseAction
+}
+
+func (action GetHierarchySubtreeAction) SendEvents(request micro.IRequest) {
+
+}
+
+func (action GetHierarchySubtreeAction) ProvideInformation() micro.ActionInformation {
+	var reply = "orion/server/misc/reply/hierarchy/node/subtree/get"
+	var error = "orion/server/misc/error/hierarchy/node/subtree/get"
+	var requestSample = dataStructures.StructToJsonString(structs.GetHierarchySubtreeRequest{})
+	var replySample = dataStructures.StructToJsonString(structs.GetHierarchySubtreeReply{})
+	info := micro.ActionInformation{
+		Name:            "GetHierarchySubtreeAction",
+		Description:     "Gets the subtree below an object of a tree of objects down to a maximum depth",
+		RequestTopic:    "orion/server/misc/request/hierarchy/node/subtree/get",
+		ReplyTopic:      reply,
+		ErrorReplyTopic: error,
+		Version:         1,
+		ClientId:        action.baseAction.ID.String(),
+		HttpMethods:     []string{http.MethodPost, "OPTIONS"},
+		RequestSample:   &requestSample,
+		ReplySample:     &replySample,
+		IsScriptable:    false,
+	}
+
+	return info
+}
+
+func (action *GetHierarchySubtreeAction) HandleWebRequest(writer http.ResponseWriter, request *http.Request) {
+	action.SetHttpRequest(request)
+	http2.HandleHttpRequest(writer, request, action)
+}
+
+func (action *GetHierarchySubtreeAction) HeyHo(ctx context.Context, request []byte) (micro.IReply, micro.IRequest) {
+	start := time.Now()
+	defer action.MetricsStore.HandleActionMetric(start, action.GetBaseAction().Environment, action.ProvideInformation(), *action.baseAction.Token)
+
+	err := json.Unmarshal(request, &action.receivedRequest)
+	if err != nil {
+		return structs2.NewErrorReplyHeaderWithException(micro.NewException(structs2.UnmarshalError, err),
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	reply, myErr := action.getSubtree(ctx, action.receivedRequest)
+	if myErr != nil {
+		return structs2.NewErrorReplyHeaderWithOrionErr(myErr,
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	return reply, &action.receivedRequest
+}
+
+func (action GetHierarchySubtreeAction) getSubtree(ctx context.Context, request structs.GetHierarchySubtreeRequest) (structs.GetHierarchySubtreeReply, *structs2.OrionError) {
+	maxDepth := viper.GetInt("hierarchyNodes.maxSubtreeDepth")
+	if maxDepth <= 0 {
+		maxDepth = defaultMaxSubtreeDepth
+	}
+	if request.MaxDepth > 0 && request.MaxDepth < maxDepth {
+		maxDepth = request.MaxDepth
+	}
+
+	root, myErr := findHierarchyNode(ctx, action.baseAction, request.HierarchyId, request.ObjectType, request.ObjectId)
+	if myErr != nil {
+		return structs.GetHierarchySubtreeReply{}, myErr
+	}
+	descendants, myErr := getHierarchyNodesFromDb(ctx, action.baseAction, bson.M{"hierarchy_id": request.HierarchyId,
+		"path": root.ID.Hex(), "depth": bson.M{"$lte": root.Depth + maxDepth}})
+	if myErr != nil {
+		return structs.GetHierarchySubtreeReply{}, myErr
+	}
+
+	var reply = structs.GetHierarchySubtreeReply{Tree: hierarchy.BuildTree(*root, descendants, maxDepth)}
+	reply.Header = structs2.NewReplyHeader(action.ProvideInformation().ReplyTopic)
+	reply.Header.Timestamp = utils2.GetCurrentTimeStamp()
+	reply.Header.Success = true
+
+	return reply, nil
+}
@@ -0,0 +1,141 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/abenstex/laniakea/dataStructures"
+	"github.com/abenstex/laniakea/micro"
+	utils2 "github.com/abenstex/laniakea/utils"
+	"github.com/abenstex/orion.commons/app"
+	http2 "github.com/abenstex/orion.commons/http"
+	structs2 "github.com/abenstex/orion.commons/structs"
+	"github.com/abenstex/orion.commons/utils"
+	"net/http"
+	"orion.misc/structs"
+	"time"
+)
+
+type GetObjectStateAction struct {
+	baseAction      micro.BaseAction
+	MetricsStore    *utils.MetricsStore
+	receivedRequest structs.GetObjectStateRequest
+}
+
+func (action *GetObjectStateAction) BeforeAction(ctx context.Context, request []byte) *micro.Exception {
+	dummy := structs.GetObjectStateRequest{}
+	err := json.Unmarshal(request, &dummy)
+	if err != nil {
+		return micro.NewException(structs2.UnmarshalError, err)
+	}
+	err = app.DefaultHandleActionRequest(request, &dummy.Header, action, true)
+	if err != nil {
+		return micro.NewException(structs2.RequestHeaderInvalid, err)
+	}
+	if len(dummy.ObjectType) == 0 || len(dummy.ObjectId) == 0 {
+		return micro.NewException(structs2.MissingParameterError,
+			fmt.Errorf("not all parameters (object_type and object_id) were provided"))
+	}
+
+	return nil
+}
+
+func (action GetObjectStateAction) BeforeActionAsync(ctx context.Context, request []byte) {
+
+}
+
+func (action GetObjectStateAction) AfterAction(ctx context.Context, reply *micro.IReply, request *micro.IRequest) *micro.Exception {
+	return nil
+}
+
+func (action GetObjectStateAction) AfterActionAsync(ctx context.Context, reply micro.IReply, request micro.IRequest) {
+
+}
+
+func (action GetObjectStateAction) GetBaseAction() micro.BaseAction {
+	return action.baseAction
+}
+
+func (action *GetObjectStateAction) SetHttpRequest(request *http.Request) {
+	action.baseAction.Request = request
+}
+
+func (action *GetObjectStateAction) InitBaseAction(baseAction micro.BaseAction) {
+	action.baseAction = baseAction
+}
+
+func (action GetObjectStateAction) SendEvents(request micro.IRequest) {
+
+}
+
+func (action GetObjectStateAction) ProvideInformation() micro.ActionInformation {
+	var reply = "orion/server/misc/reply/state/object/get"
+	var error = "orion/server/misc/error/state/object/get"
+	var requestSample = dataStructures.StructToJsonString(structs.GetObjectStateRequest{})
+	var replySample = dataStructures.StructToJsonString(structs.GetObjectStateReply{})
+	info := micro.ActionInformation{
+		Name:            "GetObjectStateAction",
+		Description:     "Gets the current state of an object and optionally its transition history",
+		RequestTopic:    "orion/server/misc/request/state/object/get",
+		ReplyTopic:      reply,
+		ErrorReplyTopic: error,
+		Version:         1,
+		ClientId:        action.baseAction.ID.String(),
+		HttpMethods:     []string{http.MethodPost, "OPTIONS"},
+		RequestSample:   &requestSample,
+		ReplySample:     &replySample,
+		IsScriptable:    true,
+	}
+
+	return info
+}
+
+func (action *GetObjectStateAction) HandleWebRequest(writer http.ResponseWriter, request *http.Request) {
+	action.SetHttpRequest(request)
+	http2.HandleHttpRequest(writer, request, action)
+}
+
+func (action *GetObjectStateAction) HeyHo(ctx context.Context, request []byte) (micro.IReply, micro.IRequest) {
+	start := time.Now()
+	defer action.MetricsStore.HandleActionMetric(start, action.GetBaseAction().Environment, action.ProvideInformation(), *action.baseAction.Token)
+
+	err := json.Unmarshal(request, &action.receivedRequest)
+	if err != nil {
+		return structs2.NewErrorReplyHeaderWithException(micro.NewException(structs2.UnmarshalError, err),
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	reply, myErr := action.getObjectState(ctx, action.receivedRequest)
+	if myErr != nil {
+		return structs2.NewErrorReplyHeaderWithOrionErr(myErr,
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	return reply, &action.receivedRequest
+}
+
+func (action GetObjectStateAction) getObjectState(ctx context.Context, request structs.GetObjectStateRequest) (structs.GetObjectStateReply, *structs2.OrionError) {
+	objectState, myErr := getObjectStateFromDb(ctx, action.baseAction, request.ObjectType, request.ObjectId)
+	if myErr != nil {
+		return structs.GetObjectStateReply{}, myErr
+	}
+	if objectState == nil {
+		return structs.GetObjectStateReply{}, structs2.NewOrionError(structs2.NoDataFound,
+			fmt.Errorf("no state is tracked for %v %v", request.ObjectType, request.ObjectId))
+	}
+
+	var reply = structs.GetObjectStateReply{}
+	reply.Header = structs2.NewReplyHeader(action.ProvideInformation().ReplyTopic)
+	reply.Header.Timestamp = utils2.GetCurrentTimeStamp()
+	reply.CurrentState = objectState
+	if request.IncludeHistory {
+		history, myErr := getObjectStateHistoryFromDb(ctx, action.baseAction, request.ObjectType, request.ObjectId)
+		if myErr != nil {
+			return structs.GetObjectStateReply{}, myErr
+		}
+		reply.History = history
+	}
+	reply.Header.Success = true
+
+	return reply, nil
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"github.com/abenstex/laniakea/dataStructures"
+	"github.com/abenstex/laniakea/logging"
 	"github.com/abenstex/laniakea/micro"
 	utils2 "github.com/abenstex/laniakea/utils"
 	"github.com/abenstex/orion.commons/app"
@@ -13,6 +14,7 @@ import (
 	"github.com/abenstex/orion.commons/utils"
 	"go.mongodb.org/mongo-driver/bson"
 	"net/http"
+	"orion.misc/parameters"
 	"orion.misc/structs"
 	"time"
 )
@@ -141,8 +143,15 @@ func (action GetParametersAction) getParameters(ctx context.Context, request str
 	return action.createGetParametersReply(parameters)
 }
 
+// getParametersFromDb returns the GLOBAL values that apply to every environment, plain GLOBAL scopes are stored
+// without a scope. The values of all other scopes, GLOBAL values of an environment included, are only returned with
+// include_overrides.
 func (action GetParametersAction) getParametersFromDb(ctx context.Context, request structs.GetParametersRequest) ([]structs.Parameter, *structs2.OrionError) {
-	cursor, err := action.baseAction.Environment.MongoDbConnection.Database().Collection("parameters").Find(ctx, bson.M{})
+	filter := bson.M{"$or": bson.A{bson.M{"scope": nil}, bson.M{"scope.level": parameters.ScopeGlobal, "scope.environment": nil}}}
+	if request.IncludeOverrides {
+		filter = bson.M{}
+	}
+	cursor, err := action.baseAction.Environment.MongoDbConnection.Database().Collection("parameters").Find(ctx, filter)
 	if err != nil {
 		return nil, structs2.NewOrionError(structs2.DatabaseError, err)
 	}
@@ -150,6 +159,15 @@ func (action GetParametersAction) getParametersFromDb(ctx context.Context, reque
 	if err = cursor.All(ctx, &objects); err != nil {
 		return nil, structs2.NewOrionError(structs2.DatabaseError, err)
 	}
+	for idx, object := range objects {
+		typedValue, err := parameters.TypedValue(object)
+		if err != nil {
+			logging.GetLogger(action.ProvideInformation().Name, action.baseAction.Environment, true).WithError(err).
+				WithField("parameter", object.Info.Name).Warn("The value of the parameter does not match its type")
+			continue
+		}
+		objects[idx].TypedValue = typedValue
+	}
 
 	return objects, nil
 }
@@ -0,0 +1,160 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/abenstex/laniakea/dataStructures"
+	"github.com/abenstex/laniakea/micro"
+	utils2 "github.com/abenstex/laniakea/utils"
+	"github.com/abenstex/orion.commons/app"
+	http2 "github.com/abenstex/orion.commons/http"
+	structs2 "github.com/abenstex/orion.commons/structs"
+	"github.com/abenstex/orion.commons/utils"
+	"net/http"
+	"orion.misc/statemachine"
+	"orion.misc/structs"
+	"strings"
+	"time"
+)
+
+type GetStateMachineGraphAction struct {
+	baseAction      micro.BaseAction
+	MetricsStore    *utils.MetricsStore
+	receivedRequest structs.GetStateMachineGraphRequest
+}
+
+func (action *GetStateMachineGraphAction) BeforeAction(ctx context.Context, request []byte) *micro.Exception {
+	dummy := structs.GetStateMachineGraphRequest{}
+	err := json.Unmarshal(request, &dummy)
+	if err != nil {
+		return micro.NewException(structs2.UnmarshalError, err)
+	}
+	err = app.DefaultHandleActionRequest(request, &dummy.Header, action, true)
+	if err != nil {
+		return micro.NewException(structs2.RequestHeaderInvalid, err)
+	}
+	if len(dummy.ReferencedType) == 0 {
+		return micro.NewException(structs2.MissingParameterError, fmt.Errorf("the parameter referenced_type was not provided"))
+	}
+
+	return nil
+}
+
+func (action GetStateMachineGraphAction) BeforeActionAsync(ctx context.Context, request []byte) {
+
+}
+
+func (action GetStateMachineGraphAction) AfterAction(ctx context.Context, reply *micro.IReply, request *micro.IRequest) *micro.Exception {
+	return nil
+}
+
+func (action GetStateMachineGraphAction) AfterActionAsync(ctx context.Context, reply micro.IReply, request micro.IRequest) {
+
+}
+
+func (action GetStateMachineGraphAction) GetBaseAction() micro.BaseAction {
+	return action.baseAction
+}
+
+func (action *GetStateMachineGraphAction) SetHttpRequest(request *http.Request) {
+	action.baseAction.Request = request
+}
+
+func (action *GetStateMachineGraphAction) InitBaseAction(baseAction micro.BaseAction) {
+	action.baseAction = baseAction
+}
+
+func (action GetStateMachineGraphAction) SendEvents(request micro.IRequest) {
+
+}
+
+func (action GetStateMachineGraphAction) ProvideInformation() micro.ActionInformation {
+	var reply = "orion/server/misc/reply/state/graph/get"
+	var error = "orion/server/misc/error/state/graph/get"
+	var requestSample = dataStructures.StructToJsonString(structs.GetStateMachineGraphRequest{})
+	var replySample = dataStructures.StructToJsonString(structs.GetStateMachineGraphReply{})
+	info := micro.ActionInformation{
+		Name:            "GetStateMachineGraphAction",
+		Description:     "Exports the states and state transition rules of a referenced type as a graph (JSON, DOT or Mermaid)",
+		RequestTopic:    "orion/server/misc/request/state/graph/get",
+		ReplyTopic:      reply,
+		ErrorReplyTopic: error,
+		Version:         1,
+		ClientId:        action.baseAction.ID.String(),
+		HttpMethods:     []string{http.MethodPost, "OPTIONS"},
+		RequestSample:   &requestSample,
+		ReplySample:     &replySample,
+		IsScriptable:    false,
+	}
+
+	return info
+}
+
+func (action *GetStateMachineGraphAction) HandleWebRequest(writer http.ResponseWriter, request *http.Request) {
+	action.SetHttpRequest(request)
+	http2.HandleHttpRequest(writer, request, action)
+}
+
+func (action *GetStateMachineGraphAction) HeyHo(ctx context.Context, request []byte) (micro.IReply, micro.IRequest) {
+	start := time.Now()
+	defer action.MetricsStore.HandleActionMetric(start, action.GetBaseAction().Environment, action.ProvideInformation(), *action.baseAction.Token)
+
+	err := json.Unmarshal(request, &action.receivedRequest)
+	if err != nil {
+		return structs2.NewErrorReplyHeaderWithException(micro.NewException(structs2.UnmarshalError, err),
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	reply, myErr := action.getGraph(ctx, action.receivedRequest)
+	if myErr != nil {
+		return structs2.NewErrorReplyHeaderWithOrionErr(myErr,
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	return reply, &action.receivedRequest
+}
+
+func (action GetStateMachineGraphAction) getGraph(ctx context.Context, request structs.GetStateMachineGraphRequest) (structs.GetStateMachineGraphReply, *structs2.OrionError) {
+	definition, myErr := loadStateMachineDefinition(ctx, action.baseAction, request.ReferencedType)
+	if myErr != nil {
+		return structs.GetStateMachineGraphReply{}, myErr
+	}
+	if len(definition.States) == 0 && len(definition.Rules) == 0 {
+		return structs.GetStateMachineGraphReply{}, structs2.NewOrionError(structs2.NoDataFound,
+			errors.New("no states or state transition rules were found for "+request.ReferencedType))
+	}
+
+	graph := definition.BuildGraph()
+	var reply = structs.GetStateMachineGraphReply{}
+	reply.Header = structs2.NewReplyHeader(action.ProvideInformation().ReplyTopic)
+	reply.Header.Timestamp = utils2.GetCurrentTimeStamp()
+	reply.Header.Success = true
+	if wantsGraphFormat(request.Formats, statemachine.GraphFormatJson) {
+		reply.Graph = &graph
+	}
+	if wantsGraphFormat(request.Formats, statemachine.GraphFormatDot) {
+		dot := statemachine.RenderDot(graph)
+		reply.Dot = &dot
+	}
+	if wantsGraphFormat(request.Formats, statemachine.GraphFormatMermaid) {
+		mermaid := statemachine.RenderMermaid(graph)
+		reply.Mermaid = &mermaid
+	}
+
+	return reply, nil
+}
+
+func wantsGraphFormat(formats []string, format string) bool {
+	if len(formats) == 0 {
+		return true
+	}
+	for _, requested := range formats {
+		if strings.EqualFold(requested, format) {
+			return true
+		}
+	}
+
+	return false
+}
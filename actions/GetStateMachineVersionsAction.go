@@ -0,0 +1,139 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/abenstex/laniakea/dataStructures"
+	"github.com/abenstex/laniakea/micro"
+	utils2 "github.com/abenstex/laniakea/utils"
+	"github.com/abenstex/orion.commons/app"
+	http2 "github.com/abenstex/orion.commons/http"
+	structs2 "github.com/abenstex/orion.commons/structs"
+	"github.com/abenstex/orion.commons/utils"
+	"net/http"
+	"orion.misc/structs"
+	"time"
+)
+
+type GetStateMachineVersionsAction struct {
+	baseAction      micro.BaseAction
+	MetricsStore    *utils.MetricsStore
+	receivedRequest structs.GetStateMachineVersionsRequest
+}
+
+func (action *GetStateMachineVersionsAction) BeforeAction(ctx context.Context, request []byte) *micro.Exception {
+	dummy := structs.GetStateMachineVersionsRequest{}
+	err := json.Unmarshal(request, &dummy)
+	if err != nil {
+		return micro.NewException(structs2.UnmarshalError, err)
+	}
+	err = app.DefaultHandleActionRequest(request, &dummy.Header, action, true)
+	if err != nil {
+		return micro.NewException(structs2.RequestHeaderInvalid, err)
+	}
+	if len(dummy.ReferencedType) == 0 {
+		return micro.NewException(structs2.MissingParameterError, fmt.Errorf("the parameter referenced_type was not provided"))
+	}
+
+	return nil
+}
+
+func (action GetStateMachineVersionsAction) BeforeActionAsync(ctx context.Context, request []byte) {
+
+}
+
+func (action GetStateMachineVersionsAction) AfterAction(ctx context.Context, reply *micro.IReply, request *micro.IRequest) *micro.Exception {
+	return nil
+}
+
+func (action GetStateMachineVersionsAction) AfterActionAsync(ctx context.Context, reply micro.IReply, request micro.IRequest) {
+
+}
+
+func (action GetStateMachineVersionsAction) GetBaseAction() micro.BaseAction {
+	return action.baseAction
+}
+
+func (action *GetStateMachineVersionsAction) SetHttpRequest(request *http.Request) {
+	action.baseAction.Request = request
+}
+
+func (action *GetStateMachineVersionsAction) InitBaseAction(baseAction micro.BaseAction) {
+	action.baseAction = baseAction
+}
+
+func (action GetStateMachineVersionsAction) SendEvents(request micro.IRequest) {
+
+}
+
+func (action GetStateMachineVersionsAction) ProvideInformation() micro.ActionInformation {
+	var reply = "orion/server/misc/reply/state/version/get"
+	var error = "orion/server/misc/error/state/version/get"
+	var requestSample = dataStructures.StructToJsonString(structs.GetStateMachineVersionsRequest{})
+	var replySample = dataStructures.StructToJsonString(structs.GetStateMachineVersionsReply{})
+	info := micro.ActionInformation{
+		Name:            "GetStateMachineVersionsAction",
+		Description:     "Gets all published state machine versions of a referenced type",
+		RequestTopic:    "orion/server/misc/request/state/version/get",
+		ReplyTopic:      reply,
+		ErrorReplyTopic: error,
+		Version:         1,
+		ClientId:        action.baseAction.ID.String(),
+		HttpMethods:     []string{http.MethodPost, "OPTIONS"},
+		RequestSample:   &requestSample,
+		ReplySample:     &replySample,
+		IsScriptable:    false,
+	}
+
+	return info
+}
+
+func (action *GetStateMachineVersionsAction) HandleWebRequest(writer http.ResponseWriter, request *http.Request) {
+	action.SetHttpRequest(request)
+	http2.HandleHttpRequest(writer, request, action)
+}
+
+func (action *GetStateMachineVersionsAction) HeyHo(ctx context.Context, request []byte) (micro.IReply, micro.IRequest) {
+	start := time.Now()
+	defer action.MetricsStore.HandleActionMetric(start, action.GetBaseAction().Environment, action.ProvideInformation(), *action.baseAction.Token)
+
+	err := json.Unmarshal(request, &action.receivedRequest)
+	if err != nil {
+		return structs2.NewErrorReplyHeaderWithException(micro.NewException(structs2.UnmarshalError, err),
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	versions, myErr := getStateMachineVersionsFromDb(ctx, action.baseAction, action.receivedRequest.ReferencedType)
+	if myErr != nil {
+		return structs2.NewErrorReplyHeaderWithOrionErr(myErr,
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	reply, myErr := action.createGetStateMachineVersionsReply(versions)
+	if myErr != nil {
+		return structs2.NewErrorReplyHeaderWithOrionErr(myErr,
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	return reply, &action.receivedRequest
+}
+
+func (action GetStateMachineVersionsAction) createGetStateMachineVersionsReply(objects []structs.StateMachineVersion) (structs.GetStateMachineVersionsReply, *structs2.OrionError) {
+	var reply = structs.GetStateMachineVersionsReply{}
+	reply.Header = structs2.NewReplyHeader(action.ProvideInformation().ReplyTopic)
+	reply.Header.Timestamp = utils2.GetCurrentTimeStamp()
+	if len(objects) > 0 {
+		reply.Header.Success = true
+		reply.Versions = objects
+		return reply, nil
+	}
+	reply.Header.Success = false
+	errorMsg := "No versions were found"
+	reply.Header.ErrorMessage = &errorMsg
+
+	err := errors.New(errorMsg)
+
+	return reply, structs2.NewOrionError(structs2.NoDataFound, err)
+}
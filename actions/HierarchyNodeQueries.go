@@ -0,0 +1,105 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"github.com/abenstex/laniakea/logging"
+	"github.com/abenstex/laniakea/micro"
+	"github.com/abenstex/laniakea/mongodb"
+	"github.com/abenstex/laniakea/mqtt"
+	utils2 "github.com/abenstex/laniakea/utils"
+	structs2 "github.com/abenstex/orion.commons/structs"
+	"github.com/abenstex/orion.commons/utils"
+	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"orion.misc/structs"
+)
+
+const (
+	HierarchyNodeAttached = "ATTACHED"
+	HierarchyNodeDetached = "DETACHED"
+	HierarchyNodeMoved    = "MOVED"
+)
+
+func getHierarchyFromDb(ctx context.Context, baseAction micro.BaseAction, id string) (*structs.Hierarchy, *structs2.OrionError) {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, structs2.NewOrionError(structs2.NoDataFound, fmt.Errorf("hierarchy %v does not exist", id))
+	}
+	hierarchies, orionErr := getHierarchiesFromDb(ctx, baseAction, bson.M{"_id": objectId})
+	if orionErr != nil {
+		return nil, orionErr
+	}
+	if len(hierarchies) == 0 {
+		return nil, structs2.NewOrionError(structs2.NoDataFound, fmt.Errorf("hierarchy %v does not exist", id))
+	}
+
+	return &hierarchies[0], nil
+}
+
+// getHierarchyNodeFromDb returns the node of an object within a tree or nil if the object is not part of it
+func getHierarchyNodeFromDb(ctx context.Context, baseAction micro.BaseAction, hierarchyId, objectType, objectId string) (*structs.HierarchyNode, error) {
+	var node structs.HierarchyNode
+	err := baseAction.Environment.MongoDbConnection.Database().Collection("hierarchy_nodes").
+		FindOne(ctx, bson.M{"hierarchy_id": hierarchyId, "object_type": objectType, "object_id": objectId}).Decode(&node)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &node, nil
+}
+
+// findHierarchyNode is like getHierarchyNodeFromDb but treats a missing node as error
+func findHierarchyNode(ctx context.Context, baseAction micro.BaseAction, hierarchyId, objectType, objectId string) (*structs.HierarchyNode, *structs2.OrionError) {
+	node, err := getHierarchyNodeFromDb(ctx, baseAction, hierarchyId, objectType, objectId)
+	if err != nil {
+		return nil, structs2.NewOrionError(structs2.DatabaseError, err)
+	}
+	if node == nil {
+		return nil, structs2.NewOrionError(structs2.NoDataFound,
+			fmt.Errorf("%v %v is not part of the tree of hierarchy %v", objectType, objectId, hierarchyId))
+	}
+
+	return node, nil
+}
+
+// getHierarchyNodesFromDb returns the nodes ordered from the root downwards
+func getHierarchyNodesFromDb(ctx context.Context, baseAction micro.BaseAction, filter bson.M) ([]structs.HierarchyNode, *structs2.OrionError) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "depth", Value: 1}})
+	cursor, err := baseAction.Environment.MongoDbConnection.Database().Collection("hierarchy_nodes").Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, structs2.NewOrionError(structs2.DatabaseError, err)
+	}
+	objects := make([]structs.HierarchyNode, 0)
+	if err = cursor.All(ctx, &objects); err != nil {
+		return nil, structs2.NewOrionError(structs2.DatabaseError, err)
+	}
+
+	return objects, nil
+}
+
+func archiveHierarchyNode(baseAction micro.BaseAction, node structs.HierarchyNode) error {
+	node.ID = nil
+	_, err := mongodb.InsertOne(context.Background(), baseAction.Environment.MongoDbArchiveConnection, "hierarchy_nodes", node)
+
+	return err
+}
+
+func sendHierarchyNodeChangedEvent(actionInfo micro.ActionInformation, senderId string, environment utils2.Environment, event structs.HierarchyNodeChangedEvent) {
+	event.Header = *micro.NewEventHeaderForAction(actionInfo, senderId, "")
+
+	json, err := event.ToJsonString()
+	if err != nil {
+		logging.GetLogger(actionInfo.Name, environment, true).WithError(err).Error("Could not send events")
+
+		return
+	}
+	mqtt.Publish(actionInfo.EventTopic, json, byte(viper.GetInt("messageBus.publishEventQos")),
+		utils.GetDefaultMqttConnectionOptionsWithIdPrefix(actionInfo.Name))
+}
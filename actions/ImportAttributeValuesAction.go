@@ -0,0 +1,369 @@
+package actions
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/abenstex/laniakea/dataStructures"
+	"github.com/abenstex/laniakea/logging"
+	"github.com/abenstex/laniakea/micro"
+	utils2 "github.com/abenstex/laniakea/utils"
+	"github.com/abenstex/orion.commons/app"
+	http2 "github.com/abenstex/orion.commons/http"
+	structs2 "github.com/abenstex/orion.commons/structs"
+	"github.com/abenstex/orion.commons/utils"
+	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/bson"
+	"io/ioutil"
+	"net/http"
+	"orion.misc/attributes"
+	"orion.misc/structs"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultImportBatchSize = 500
+	maxImportUploadSize    = 32 << 20
+)
+
+type ImportAttributeValuesAction struct {
+	baseAction      micro.BaseAction
+	MetricsStore    *utils.MetricsStore
+	receivedRequest structs.ImportAttributeValuesRequest
+	changes         []structs.AttributeChange
+}
+
+func (action *ImportAttributeValuesAction) BeforeAction(ctx context.Context, request []byte) *micro.Exception {
+	dummy := structs.ImportAttributeValuesRequest{}
+	err := json.Unmarshal(request, &dummy)
+	if err != nil {
+		return micro.NewException(structs2.UnmarshalError, err)
+	}
+	err = app.DefaultHandleActionRequest(request, &dummy.Header, action, true)
+	if err != nil {
+		return micro.NewException(structs2.RequestHeaderInvalid, err)
+	}
+	if len(strings.TrimSpace(dummy.Csv)) == 0 {
+		return micro.NewException(structs2.MissingParameterError, fmt.Errorf("the parameter csv was not provided"))
+	}
+
+	return nil
+}
+
+func (action ImportAttributeValuesAction) BeforeActionAsync(ctx context.Context, request []byte) {
+
+}
+
+func (action ImportAttributeValuesAction) AfterAction(ctx context.Context, reply *micro.IReply, request *micro.IRequest) *micro.Exception {
+	return nil
+}
+
+func (action ImportAttributeValuesAction) AfterActionAsync(ctx context.Context, reply micro.IReply, request micro.IRequest) {
+
+}
+
+func (action ImportAttributeValuesAction) GetBaseAction() micro.BaseAction {
+	return action.baseAction
+}
+
+func (action *ImportAttributeValuesAction) SetHttpRequest(request *http.Request) {
+	action.baseAction.Request = request
+}
+
+func (action *ImportAttributeValuesAction) InitBaseAction(baseAction micro.BaseAction) {
+	action.baseAction = baseAction
+}
+
+func (action ImportAttributeValuesAction) SendEvents(request micro.IRequest) {
+	saveRequest := request.(*structs.ImportAttributeValuesRequest)
+	if !saveRequest.Header.WasExecutedSuccessfully {
+		logging.GetLogger("ImportAttributeValuesAction",
+			action.GetBaseAction().Environment,
+			true).Warn("RequestFailedEvent will be sent because the request was not successfully executed")
+		blerghEvent := structs2.NewRequestFailedEvent(saveRequest, action.ProvideInformation(), action.baseAction.ID.String(), "")
+		blerghEvent.Send(action.ProvideInformation().ErrorReplyTopic, byte(viper.GetInt("messageBus.publishEventQos")),
+			utils.GetDefaultMqttConnectionOptionsWithIdPrefix(action.ProvideInformation().Name))
+		return
+	}
+	if len(action.changes) == 0 {
+		return
+	}
+
+	sendAttributeValueChangedEvent(action.ProvideInformation(), saveRequest.Header.SenderId, action.baseAction.Environment, action.changes)
+}
+
+func (action ImportAttributeValuesAction) ProvideInformation() micro.ActionInformation {
+	var reply = "orion/server/misc/reply/attribute/value/import"
+	var error = "orion/server/misc/error/attribute/value/import"
+	var requestSample = dataStructures.StructToJsonString(structs.ImportAttributeValuesRequest{})
+	var replySample = dataStructures.StructToJsonString(structs.ImportAttributeValuesReply{})
+	var eventSample = dataStructures.StructToJsonString(structs.AttributeValueChangedEvent{})
+	info := micro.ActionInformation{
+		Name:            "ImportAttributeValuesAction",
+		Description:     "Imports attribute values from CSV rows of object type, object id, attribute name or id and value and reports the result of every row",
+		RequestTopic:    "orion/server/misc/request/attribute/value/import",
+		ReplyTopic:      reply,
+		ErrorReplyTopic: error,
+		Version:         1,
+		ClientId:        action.baseAction.ID.String(),
+		HttpMethods:     []string{http.MethodPost, "OPTIONS"},
+		EventTopic:      AttributeValueChangedEventTopic,
+		RequestSample:   &requestSample,
+		ReplySample:     &replySample,
+		EventSample:     &eventSample,
+		IsScriptable:    false,
+	}
+
+	return info
+}
+
+// HandleWebRequest accepts the JSON request as well as a multipart upload with the CSV data in the part "file",
+// the request header as JSON in "header" and optionally "validate_only"
+func (action *ImportAttributeValuesAction) HandleWebRequest(writer http.ResponseWriter, request *http.Request) {
+	action.SetHttpRequest(request)
+	if strings.HasPrefix(request.Header.Get("Content-Type"), "multipart/form-data") {
+		body, err := action.createRequestFromUpload(request)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		request.Body = ioutil.NopCloser(bytes.NewReader(body))
+		request.ContentLength = int64(len(body))
+		request.Header.Set("Content-Type", "application/json")
+	}
+	http2.HandleHttpRequest(writer, request, action)
+}
+
+func (action *ImportAttributeValuesAction) createRequestFromUpload(request *http.Request) ([]byte, error) {
+	if err := request.ParseMultipartForm(maxImportUploadSize); err != nil {
+		return nil, err
+	}
+	importRequest := structs.ImportAttributeValuesRequest{}
+	if header := request.FormValue("header"); len(header) > 0 {
+		if err := json.Unmarshal([]byte(header), &importRequest.Header); err != nil {
+			return nil, fmt.Errorf("the header could not be read: %v", err)
+		}
+	}
+	if validateOnly := request.FormValue("validate_only"); len(validateOnly) > 0 {
+		parsed, err := strconv.ParseBool(validateOnly)
+		if err != nil {
+			return nil, fmt.Errorf("validate_only must be true or false")
+		}
+		importRequest.ValidateOnly = parsed
+	}
+	file, _, err := request.FormFile("file")
+	if err != nil {
+		return nil, fmt.Errorf("the CSV file could not be read: %v", err)
+	}
+	defer file.Close()
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("the CSV file could not be read: %v", err)
+	}
+	importRequest.Csv = string(data)
+
+	return json.Marshal(importRequest)
+}
+
+func (action *ImportAttributeValuesAction) HeyHo(ctx context.Context, request []byte) (micro.IReply, micro.IRequest) {
+	start := time.Now()
+	defer action.MetricsStore.HandleActionMetric(start, action.GetBaseAction().Environment, action.ProvideInformation(), *action.baseAction.Token)
+	action.changes = nil
+
+	err := json.Unmarshal(request, &action.receivedRequest)
+	if err != nil {
+		return structs2.NewErrorReplyHeaderWithException(micro.NewException(structs2.UnmarshalError, err),
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	reply, myErr := action.importAttributeValues(ctx, action.receivedRequest)
+	if myErr != nil {
+		logging.GetLogger("ImportAttributeValuesAction",
+			action.GetBaseAction().Environment,
+			true).WithError(myErr.Error).Error("Attribute values could not be imported")
+		return structs2.NewErrorReplyHeaderWithOrionErr(myErr,
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	return reply, &action.receivedRequest
+}
+
+func (action *ImportAttributeValuesAction) importAttributeValues(ctx context.Context, request structs.ImportAttributeValuesRequest) (structs.ImportAttributeValuesReply, *structs2.OrionError) {
+	rows, err := attributes.ParseImportRows(request.Csv)
+	if err != nil {
+		return structs.ImportAttributeValuesReply{}, structs2.NewOrionError(structs.ValidationError, fmt.Errorf("the CSV data could not be read: %v", err))
+	}
+	definitions, myErr := getAttributeDefinitionsFromDb(ctx, action.baseAction, bson.M{})
+	if myErr != nil {
+		return structs.ImportAttributeValuesReply{}, myErr
+	}
+
+	values, myErr := action.validateRows(ctx, definitions, rows)
+	if myErr != nil {
+		return structs.ImportAttributeValuesReply{}, myErr
+	}
+	if !request.ValidateOnly {
+		action.applyRows(ctx, rows, values, request.Header.User, request.Header.Comment)
+	}
+
+	var reply = structs.ImportAttributeValuesReply{Rows: rows, ValidateOnly: request.ValidateOnly}
+	for _, row := range rows {
+		switch row.Status {
+		case attributes.ImportInvalid:
+			reply.InvalidRows++
+		case attributes.ImportFailed:
+			reply.FailedRows++
+		case attributes.ImportApplied:
+			reply.ValidRows++
+			reply.AppliedRows++
+		default:
+			reply.ValidRows++
+		}
+	}
+	reply.Header = structs2.NewReplyHeader(action.ProvideInformation().ReplyTopic)
+	reply.Header.Timestamp = utils2.GetCurrentTimeStamp()
+	reply.Header.Success = true
+
+	return reply, nil
+}
+
+// validateRows resolves the attributes of the rows and validates their values. The values of the rows are returned
+// by the index of their row, rows that are invalid are marked as such. Dependent values are checked against the
+// values of the valid rows only, so they are checked again whenever a row of a parent attribute turns out invalid.
+// Values of parent attributes must not invalidate the stored values of dependent attributes that are not imported.
+func (action *ImportAttributeValuesAction) validateRows(ctx context.Context, definitions []structs.AttributeDefinition,
+	rows []structs.AttributeImportRow) (map[int]structs.AttributeValue, *structs2.OrionError) {
+	values := make(map[int]structs.AttributeValue, len(rows))
+	resolved := make(map[int]*structs.AttributeDefinition, len(rows))
+	firstRows := make(map[string]int, len(rows))
+	for idx := range rows {
+		row := &rows[idx]
+		if row.Status == attributes.ImportInvalid {
+			continue
+		}
+		definition := attributes.FindDefinition(definitions, row.Attribute)
+		if definition == nil {
+			row.Status = attributes.ImportInvalid
+			row.ValidationErrors = append(row.ValidationErrors, attributes.NewValidationError(
+				structs.AttributeValue{AttributeId: row.Attribute, ObjectType: row.ObjectType, ObjectId: row.ObjectId, Value: row.Value},
+				attributes.ErrorUnknownAttribute, fmt.Sprintf("attribute definition %v does not exist", row.Attribute)))
+			continue
+		}
+		value := attributes.ImportValue(*definition, *row)
+		key := attributes.AttributeValueKey(value.ObjectType, value.ObjectId, value.AttributeId)
+		if first, ok := firstRows[key]; ok {
+			row.Status = attributes.ImportInvalid
+			row.ValidationErrors = append(row.ValidationErrors, attributes.NewValidationError(value, attributes.ErrorDuplicateValue,
+				fmt.Sprintf("the value was already set in row %d", first)))
+			continue
+		}
+		firstRows[key] = row.Row
+		if validationErrors := attributes.Validate(*definition, value); len(validationErrors) > 0 {
+			row.Status = attributes.ImportInvalid
+			row.ValidationErrors = append(row.ValidationErrors, validationErrors...)
+			continue
+		}
+		values[idx] = value
+		resolved[idx] = definition
+	}
+
+	candidates := make([]structs.AttributeValue, 0, len(values))
+	for _, value := range values {
+		candidates = append(candidates, value)
+	}
+	stored, myErr := getStoredParentValues(ctx, action.baseAction, definitions, candidates)
+	if myErr != nil {
+		return nil, myErr
+	}
+	storedDependents, myErr := getStoredDependentValues(ctx, action.baseAction, definitions, candidates)
+	if myErr != nil {
+		return nil, myErr
+	}
+	pending := indexAttributeValues(candidates)
+	for changed := true; changed; {
+		changed = false
+		for idx, value := range values {
+			validationErrors := validateDependency(definitions, *resolved[idx], value, pending, stored)
+			validationErrors = append(validationErrors, validateDependents(definitions, *resolved[idx], value, pending, storedDependents)...)
+			if len(validationErrors) == 0 {
+				continue
+			}
+			rows[idx].Status = attributes.ImportInvalid
+			rows[idx].ValidationErrors = append(rows[idx].ValidationErrors, validationErrors...)
+			delete(values, idx)
+			delete(pending, attributes.AttributeValueKey(value.ObjectType, value.ObjectId, value.AttributeId))
+			changed = true
+		}
+	}
+
+	return values, nil
+}
+
+// applyRows saves the values of the valid rows in batches, every batch is a transaction of its own. The values of an
+// object are never split across batches, so a dependent value is saved or rejected together with its parent value.
+// The rows of a batch that could not be saved are marked as failed.
+func (action *ImportAttributeValuesAction) applyRows(ctx context.Context, rows []structs.AttributeImportRow,
+	values map[int]structs.AttributeValue, user, comment string) {
+	batchSize := viper.GetInt("attributes.importBatchSize")
+	if batchSize <= 0 {
+		batchSize = defaultImportBatchSize
+	}
+
+	objects := make([]string, 0)
+	indexesByObject := make(map[string][]int)
+	for idx := range rows {
+		value, ok := values[idx]
+		if !ok {
+			continue
+		}
+		key := attributes.ValueKey(value.ObjectType, value.ObjectId)
+		if _, ok := indexesByObject[key]; !ok {
+			objects = append(objects, key)
+		}
+		indexesByObject[key] = append(indexesByObject[key], idx)
+	}
+	batches := make([][]int, 0)
+	current := make([]int, 0, batchSize)
+	for _, key := range objects {
+		if len(current) > 0 && len(current)+len(indexesByObject[key]) > batchSize {
+			batches = append(batches, current)
+			current = make([]int, 0, batchSize)
+		}
+		current = append(current, indexesByObject[key]...)
+	}
+	if len(current) > 0 {
+		batches = append(batches, current)
+	}
+
+	for _, indexes := range batches {
+		batch := make([]structs.AttributeValue, 0, len(indexes))
+		for _, idx := range indexes {
+			batch = append(batch, values[idx])
+		}
+
+		changes, myErr := saveAttributeValues(ctx, action.baseAction, batch, user, comment, false)
+		if myErr != nil {
+			message := myErr.Error.Error()
+			for _, idx := range indexes {
+				rows[idx].Status = attributes.ImportFailed
+				rows[idx].Message = &message
+			}
+			continue
+		}
+		changed := make(map[string]bool, len(changes))
+		for _, change := range changes {
+			changed[attributes.AttributeValueKey(change.ObjectType, change.ObjectId, change.AttributeId)] = true
+		}
+		for _, idx := range indexes {
+			value := values[idx]
+			rows[idx].Status = attributes.ImportUnchanged
+			if changed[attributes.AttributeValueKey(value.ObjectType, value.ObjectId, value.AttributeId)] {
+				rows[idx].Status = attributes.ImportApplied
+			}
+		}
+		action.changes = append(action.changes, changes...)
+	}
+}
@@ -0,0 +1,126 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"github.com/abenstex/laniakea/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"orion.misc/attributes"
+	"orion.misc/structs"
+)
+
+// index is an index of a collection, archived indexes belong to the archive database
+type index struct {
+	collection string
+	archived   bool
+	model      mongo.IndexModel
+}
+
+// indexes are the indexes the attribute value search, the trees of objects and the unique keys of the stored
+// objects rely on
+var indexes = []index{
+	{collection: "attribute_values", model: mongo.IndexModel{
+		Keys: bson.D{{Key: "attribute_id", Value: 1}, {Key: "object_type", Value: 1}, {Key: "typed_value", Value: 1}}}},
+	{collection: "attribute_values", model: mongo.IndexModel{
+		Keys: bson.D{{Key: "attribute_id", Value: 1}, {Key: "object_type", Value: 1}, {Key: "value", Value: 1}}}},
+	{collection: "attribute_values", model: mongo.IndexModel{
+		Keys: bson.D{{Key: "attribute_id", Value: 1}, {Key: "object_type", Value: 1}, {Key: "values", Value: 1}}}},
+	{collection: "attribute_values", model: mongo.IndexModel{
+		Keys:    bson.D{{Key: "object_type", Value: 1}, {Key: "object_id", Value: 1}, {Key: "attribute_id", Value: 1}},
+		Options: options.Index().SetUnique(true)}},
+	{collection: "hierarchy_nodes", model: mongo.IndexModel{
+		Keys:    bson.D{{Key: "hierarchy_id", Value: 1}, {Key: "object_type", Value: 1}, {Key: "object_id", Value: 1}},
+		Options: options.Index().SetUnique(true)}},
+	{collection: "hierarchy_nodes", model: mongo.IndexModel{
+		Keys: bson.D{{Key: "hierarchy_id", Value: 1}, {Key: "path", Value: 1}, {Key: "depth", Value: 1}}}},
+	{collection: "state_machine_versions", archived: true, model: mongo.IndexModel{
+		Keys:    bson.D{{Key: "referenced_type", Value: 1}, {Key: "version", Value: 1}},
+		Options: options.Index().SetUnique(true)}},
+	{collection: "object_states", model: mongo.IndexModel{
+		Keys:    bson.D{{Key: "object_type", Value: 1}, {Key: "object_id", Value: 1}},
+		Options: options.Index().SetUnique(true)}},
+	{collection: "user_roles", model: mongo.IndexModel{
+		Keys:    bson.D{{Key: "user", Value: 1}},
+		Options: options.Index().SetUnique(true)}},
+	{collection: "parameters", model: mongo.IndexModel{
+		Keys: bson.D{{Key: "info.name", Value: 1}, {Key: "scope.level", Value: 1}, {Key: "scope.environment", Value: 1},
+			{Key: "scope.application", Value: 1}, {Key: "scope.instance", Value: 1}, {Key: "scope.user", Value: 1}},
+		Options: options.Index().SetUnique(true)}},
+}
+
+// createIndexes creates the indexes one by one, so that an index that can't be created, e.g. a unique index over
+// duplicate documents, doesn't keep the others from being created. Existing indexes are left untouched, an error is
+// returned for every index that could not be created.
+func createIndexes(ctx context.Context, environment utils.Environment) []error {
+	errs := make([]error, 0)
+	for _, index := range indexes {
+		connection := environment.MongoDbConnection
+		if index.archived {
+			connection = environment.MongoDbArchiveConnection
+		}
+		_, err := connection.Database().Collection(index.collection).Indexes().CreateOne(ctx, index.model)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("the index %v of %v could not be created: %v", index.model.Keys, index.collection, err))
+		}
+	}
+
+	return errs
+}
+
+// backfillTypedValues sets the typed_value of attribute values that were stored before values were indexed for
+// searching, without it these values are not found by the search. It returns the number of updated values.
+func backfillTypedValues(ctx context.Context, environment utils.Environment) (int, error) {
+	return fillTypedValues(ctx, environment, bson.M{})
+}
+
+// fillTypedValues sets the typed_value of the attribute values matching filter that have none
+func fillTypedValues(ctx context.Context, environment utils.Environment, filter bson.M) (int, error) {
+	cursor, err := environment.MongoDbConnection.Database().Collection("attribute_definitions").Find(ctx, bson.M{})
+	if err != nil {
+		return 0, err
+	}
+	definitions := make([]structs.AttributeDefinition, 0)
+	if err = cursor.All(ctx, &definitions); err != nil {
+		return 0, err
+	}
+	definitionsById := make(map[string]structs.AttributeDefinition, len(definitions))
+	for _, definition := range definitions {
+		definitionsById[definition.ID.Hex()] = definition
+	}
+
+	collection := environment.MongoDbConnection.Database().Collection("attribute_values")
+	missing := bson.M{"typed_value": bson.M{"$exists": false}}
+	for key, value := range filter {
+		missing[key] = value
+	}
+	cursor, err = collection.Find(ctx, missing)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+	updated := 0
+	for cursor.Next(ctx) {
+		var value structs.AttributeValue
+		if err = cursor.Decode(&value); err != nil {
+			return updated, err
+		}
+		definition, ok := definitionsById[value.AttributeId]
+		if !ok {
+			continue
+		}
+		normalized, err := attributes.NormalizeValue(definition, value)
+		if err != nil {
+			return updated, err
+		}
+		result, err := collection.UpdateOne(ctx, bson.M{"_id": value.ID, "typed_value": bson.M{"$exists": false}},
+			bson.M{"$set": bson.M{"typed_value": normalized.TypedValue}})
+		if err != nil {
+			return updated, err
+		}
+		updated += int(result.ModifiedCount)
+	}
+
+	return updated, cursor.Err()
+}
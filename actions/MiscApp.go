@@ -26,13 +26,15 @@ const ApplicationVersion = "0.2.2"
 const HeartbeatTopic = "orion/server/heartbeat/misc"
 
 type MiscApp struct {
-	CacheManager structs.CacheManager
-	AppInfo      micro.MicroServiceApplicationInformation
-	Environment  utils.Environment
-	Started      bool
-	topicActions map[string]micro.Action
-	timer        *time.Timer
-	Token        *string
+	CacheManager        structs.CacheManager
+	AppInfo             micro.MicroServiceApplicationInformation
+	Environment         utils.Environment
+	Started             bool
+	topicActions        map[string]micro.Action
+	timer               *time.Timer
+	Token               *string
+	escalationScheduler *StateEscalationScheduler
+	creationListener    *ObjectCreationListener
 }
 
 func (app *MiscApp) WriteApplicationInfoFile() {
@@ -173,6 +175,33 @@ func (app *MiscApp) StartApplication(actions []micro.Action) error {
 	app.topicActions = topicActions
 	//app.Started = true
 
+	for _, err := range createIndexes(context.Background(), app.Environment) {
+		logging.GetLogger(ApplicationName, app.Environment, true).WithError(err).Error("Could not create a database index")
+	}
+	backfilled, err := backfillTypedValues(context.Background(), app.Environment)
+	if err != nil {
+		logging.GetLogger(ApplicationName, app.Environment, true).WithError(err).Error("Could not index the stored attribute values for searching")
+	} else if backfilled > 0 {
+		logging.GetLogger(ApplicationName, app.Environment, true).Info("Indexed " + strconv.Itoa(backfilled) + " stored attribute values for searching")
+	}
+
+	if viper.GetBool("stateEscalation.enabled") {
+		interval := viper.GetInt("stateEscalation.intervalSeconds")
+		if interval <= 0 {
+			interval = 60
+		}
+		app.escalationScheduler = NewStateEscalationScheduler(app.Environment, time.Duration(interval)*time.Second, nil)
+		app.escalationScheduler.Start()
+	}
+
+	if viper.GetBool("objectCreation.enabled") {
+		app.creationListener = NewObjectCreationListener(app.Environment, viper.GetStringSlice("objectCreation.topics"))
+		err = app.creationListener.Start()
+		if err != nil {
+			logging.GetLogger(ApplicationName, app.Environment, true).WithError(err).Error("Could not subscribe to object created events")
+		}
+	}
+
 	logging.GetLogger(ApplicationName, app.Environment, true).Info("Server started and is ready for requests with PID " + strconv.Itoa(os.Getpid()))
 
 	return nil
@@ -182,6 +211,12 @@ func (app *MiscApp) StopApplication() error {
 	logger := logging.GetLogger(app.AppInfo.AppName, app.Environment, true)
 	logger.Debug("Stopping " + ApplicationName + " " + ApplicationVersion)
 	//utils.StopCommunication()
+	if app.escalationScheduler != nil {
+		app.escalationScheduler.Stop()
+	}
+	if app.creationListener != nil {
+		app.creationListener.Stop()
+	}
 
 	if viper.GetBool("database.useSql") == true {
 		return app.Environment.Database.Close()
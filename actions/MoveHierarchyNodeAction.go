@@ -0,0 +1,269 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/abenstex/laniakea/dataStructures"
+	"github.com/abenstex/laniakea/logging"
+	"github.com/abenstex/laniakea/micro"
+	"github.com/abenstex/laniakea/mongodb"
+	utils2 "github.com/abenstex/laniakea/utils"
+	"github.com/abenstex/orion.commons/app"
+	http2 "github.com/abenstex/orion.commons/http"
+	structs2 "github.com/abenstex/orion.commons/structs"
+	"github.com/abenstex/orion.commons/utils"
+	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"net/http"
+	"orion.misc/hierarchy"
+	"orion.misc/structs"
+	"time"
+)
+
+type MoveHierarchyNodeAction struct {
+	baseAction      micro.BaseAction
+	MetricsStore    *utils.MetricsStore
+	receivedRequest structs.MoveHierarchyNodeRequest
+	event           *structs.HierarchyNodeChangedEvent
+}
+
+func (action *MoveHierarchyNodeAction) BeforeAction(ctx context.Context, request []byte) *micro.Exception {
+	dummy := structs.MoveHierarchyNodeRequest{}
+	err := json.Unmarshal(request, &dummy)
+	if err != nil {
+		return micro.NewException(structs2.UnmarshalError, err)
+	}
+	err = app.DefaultHandleActionRequest(request, &dummy.Header, action, true)
+	if err != nil {
+		return micro.NewException(structs2.RequestHeaderInvalid, err)
+	}
+	if len(dummy.HierarchyId) == 0 || len(dummy.ObjectType) == 0 || len(dummy.ObjectId) == 0 {
+		return micro.NewException(structs2.MissingParameterError,
+			fmt.Errorf("not all parameters (hierarchy_id, object_type and object_id) were provided"))
+	}
+
+	return nil
+}
+
+func (action MoveHierarchyNodeAction) BeforeActionAsync(ctx context.Context, request []byte) {
+
+}
+
+func (action MoveHierarchyNodeAction) AfterAction(ctx context.Context, reply *micro.IReply, request *micro.IRequest) *micro.Exception {
+	return nil
+}
+
+func (action MoveHierarchyNodeAction) AfterActionAsync(ctx context.Context, reply micro.IReply, request micro.IRequest) {
+
+}
+
+func (action MoveHierarchyNodeAction) GetBaseAction() micro.BaseAction {
+	return action.baseAction
+}
+
+func (action *MoveHierarchyNodeAction) SetHttpRequest(request *http.Request) {
+	action.baseAction.Request = request
+}
+
+func (action *MoveHierarchyNodeAction) InitBaseAction(baseAction micro.BaseAction) {
+	action.baseAction = baseAction
+}
+
+func (action MoveHierarchyNodeAction) SendEvents(request micro.IRequest) {
+	nodeRequest := request.(*structs.MoveHierarchyNodeRequest)
+	if !nodeRequest.Header.WasExecutedSuccessfully {
+		logging.GetLogger("MoveHierarchyNodeAction",
+			action.GetBaseAction().Environment,
+			true).Warn("RequestFailedEvent will be sent because the request was not successfully executed")
+		blerghEvent := structs2.NewRequestFailedEvent(nodeRequest, action.ProvideInformation(), action.baseAction.ID.String(), "")
+		blerghEvent.Send(action.ProvideInformation().ErrorReplyTopic, byte(viper.GetInt("messageBus.publishEventQos")),
+			utils.GetDefaultMqttConnectionOptionsWithIdPrefix(action.ProvideInformation().Name))
+		return
+	}
+	if action.event == nil {
+		return
+	}
+
+	sendHierarchyNodeChangedEvent(action.ProvideInformation(), nodeRequest.Header.SenderId, action.baseAction.Environment, *action.event)
+}
+
+func (action MoveHierarchyNodeAction) ProvideInformation() micro.ActionInformation {
+	var reply = "orion/server/misc/reply/hierarchy/node/move"
+	var error = "orion/server/misc/error/hierarchy/node/move"
+	var event = "orion/server/misc/event/hierarchy/node/move"
+	var requestSample = dataStructures.StructToJsonString(structs.MoveHierarchyNodeRequest{})
+	var replySample = dataStructures.StructToJsonString(micro.ReplyHeader{})
+	var eventSample = dataStructures.StructToJsonString(structs.HierarchyNodeChangedEvent{})
+	info := micro.ActionInformation{
+		Name:            "MoveHierarchyNodeAction",
+		Description:     "Moves an object together with its descendants below another parent object of the same tree or makes it a root",
+		RequestTopic:    "orion/server/misc/request/hierarchy/node/move",
+		ReplyTopic:      reply,
+		ErrorReplyTopic: error,
+		Version:         1,
+		ClientId:        action.baseAction.ID.String(),
+		HttpMethods:     []string{http.MethodPost, "OPTIONS"},
+		EventTopic:      event,
+		RequestSample:   &requestSample,
+		ReplySample:     &replySample,
+		EventSample:     &eventSample,
+		IsScriptable:    false,
+	}
+
+	return info
+}
+
+func (action *MoveHierarchyNodeAction) HandleWebRequest(writer http.ResponseWriter, request *http.Request) {
+	action.SetHttpRequest(request)
+	http2.HandleHttpRequest(writer, request, action)
+}
+
+func (action *MoveHierarchyNodeAction) HeyHo(ctx context.Context, request []byte) (micro.IReply, micro.IRequest) {
+	start := time.Now()
+	defer action.MetricsStore.HandleActionMetric(start, action.GetBaseAction().Environment, action.ProvideInformation(), *action.baseAction.Token)
+	action.event = nil
+
+	err := json.Unmarshal(request, &action.receivedRequest)
+	if err != nil {
+		return structs2.NewErrorReplyHeaderWithException(micro.NewException(structs2.UnmarshalError, err),
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	reply, myErr := action.moveNode(ctx, action.receivedRequest)
+	if myErr != nil {
+		logging.GetLogger("MoveHierarchyNodeAction",
+			action.GetBaseAction().Environment,
+			true).WithError(myErr.Error).Error("Data could not be saved")
+		return structs2.NewErrorReplyHeaderWithOrionErr(myErr,
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	return reply, &action.receivedRequest
+}
+
+func (action *MoveHierarchyNodeAction) moveNode(ctx context.Context, request structs.MoveHierarchyNodeRequest) (micro.ReplyHeader, *structs2.OrionError) {
+	typeHierarchy, myErr := getHierarchyFromDb(ctx, action.baseAction, request.HierarchyId)
+	if myErr != nil {
+		return micro.ReplyHeader{}, myErr
+	}
+
+	// the node, its new parent and its descendants are read in the transaction and every node is only replaced if
+	// its path is still the one that was read, so a concurrent change of the tree fails the move
+	var node *structs.HierarchyNode
+	var newParentId *string
+	var rejection *structs2.OrionError
+	moved := make([]structs.HierarchyNode, 0)
+	archived := make([]structs.HierarchyNode, 0)
+	callback := func(sessCtx mongo.SessionContext) (interface{}, error) {
+		// the callback runs again if the transaction is retried
+		node, newParentId, rejection = nil, nil, nil
+		moved = moved[:0]
+		archived = archived[:0]
+		current, myErr := findHierarchyNode(sessCtx, action.baseAction, request.HierarchyId, request.ObjectType, request.ObjectId)
+		if myErr != nil {
+			rejection = myErr
+			return nil, myErr.Error
+		}
+		var newParent *structs.HierarchyNode
+		if request.NewParent != nil {
+			newParent, myErr = findHierarchyNode(sessCtx, action.baseAction, request.HierarchyId, request.NewParent.ObjectType, request.NewParent.ObjectId)
+			if myErr != nil {
+				rejection = myErr
+				return nil, myErr.Error
+			}
+			if err := hierarchy.CheckParentType(*typeHierarchy, newParent.ObjectType, current.ObjectType); err != nil {
+				rejection = structs2.NewOrionError(structs.ValidationError, err)
+				return nil, err
+			}
+			parentId := newParent.ID.Hex()
+			newParentId = &parentId
+		}
+		if err := hierarchy.CheckMove(*current, newParent); err != nil {
+			rejection = structs2.NewOrionError(structs.ValidationError, err)
+			return nil, err
+		}
+		descendants, myErr := getHierarchyNodesFromDb(sessCtx, action.baseAction, bson.M{"hierarchy_id": request.HierarchyId, "path": current.ID.Hex()})
+		if myErr != nil {
+			return nil, myErr.Error
+		}
+
+		now := utils2.GetCurrentTimeStamp()
+		updated := *current
+		updated.ParentId = newParentId
+		updated.Path = hierarchy.ChildPath(newParent)
+		updated.Depth = len(updated.Path)
+		oldPath := hierarchy.ChildPath(current)
+		newPath := hierarchy.ChildPath(&updated)
+		for idx, candidate := range append([]structs.HierarchyNode{updated}, descendants...) {
+			readPath := current.Path
+			if idx > 0 {
+				readPath = candidate.Path
+				candidate = hierarchy.Rebase(candidate, oldPath, newPath)
+			}
+			candidate.ChangeDate = &now
+			candidate.User = &request.Header.User
+			candidate.UserComment = &request.Header.Comment
+			original, err := action.replaceNode(sessCtx, candidate, readPath)
+			if err != nil {
+				return nil, err
+			}
+			if original == nil {
+				err = fmt.Errorf("%v %v was changed concurrently, the move was not applied", candidate.ObjectType, candidate.ObjectId)
+				rejection = structs2.NewOrionError(structs.ValidationError, err)
+				return nil, err
+			}
+			original.ChangeDate = &now
+			archived = append(archived, *original)
+			moved = append(moved, candidate)
+		}
+		node = current
+
+		return nil, nil
+	}
+	_, err := mongodb.PerformQueriesInTransaction(ctx, action.baseAction.Environment.MongoDbConnection, callback)
+	if rejection != nil {
+		return micro.ReplyHeader{}, rejection
+	}
+	if err != nil {
+		return micro.ReplyHeader{}, structs2.NewOrionError(structs2.DatabaseError, fmt.Errorf("error executing queries in transaction: %v", err))
+	}
+	// the archive is a different database, it is only written once the move is committed
+	for _, original := range archived {
+		if err := archiveHierarchyNode(action.baseAction, original); err != nil {
+			logging.GetLogger("MoveHierarchyNodeAction", action.GetBaseAction().Environment, true).WithError(err).
+				Error("Could not archive a moved node")
+		}
+	}
+	action.event = &structs.HierarchyNodeChangedEvent{
+		Change:           HierarchyNodeMoved,
+		HierarchyId:      node.HierarchyId,
+		ObjectType:       node.ObjectType,
+		ObjectId:         node.ObjectId,
+		OriginalParentId: node.ParentId,
+		NewParentId:      newParentId,
+		Nodes:            moved,
+	}
+
+	reply := structs2.NewReplyHeader(action.ProvideInformation().ReplyTopic)
+	reply.Timestamp = utils2.GetCurrentTimeStamp()
+	reply.Success = true
+
+	return reply, nil
+}
+
+// replaceNode replaces a node if its path is still path and returns the node as it was before, nil if it did not match
+func (action *MoveHierarchyNodeAction) replaceNode(ctx context.Context, node structs.HierarchyNode, path []string) (*structs.HierarchyNode, error) {
+	var original structs.HierarchyNode
+	err := action.baseAction.Environment.MongoDbConnection.Database().Collection("hierarchy_nodes").
+		FindOneAndReplace(ctx, bson.M{"_id": node.ID, "path": path}, node).Decode(&original)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &original, nil
+}
@@ -0,0 +1,146 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"github.com/abenstex/laniakea/logging"
+	"github.com/abenstex/laniakea/micro"
+	utils2 "github.com/abenstex/laniakea/utils"
+	"github.com/abenstex/orion.commons/utils"
+	MQTT "github.com/eclipse/paho.mqtt.golang"
+	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"orion.misc/attributes"
+	"orion.misc/structs"
+)
+
+// ObjectCreationListener subscribes to the save events of other modules and assigns the attributes that are flagged
+// to be assigned during object creation to the objects that were created
+type ObjectCreationListener struct {
+	baseAction micro.BaseAction
+	Topics     []string
+	client     MQTT.Client
+}
+
+func NewObjectCreationListener(environment utils2.Environment, topics []string) *ObjectCreationListener {
+	return &ObjectCreationListener{
+		baseAction: micro.BaseAction{Environment: environment, ID: utils2.NewUuid()},
+		Topics:     topics,
+	}
+}
+
+func (listener *ObjectCreationListener) Start() error {
+	listener.client = MQTT.NewClient(utils.GetDefaultMqttConnectionOptionsWithIdPrefix(listener.ProvideInformation().Name))
+	if token := listener.client.Connect(); token.Wait() && token.Error() != nil {
+		return token.Error()
+	}
+	filters := make(map[string]byte, len(listener.Topics))
+	for _, topic := range listener.Topics {
+		filters[topic] = byte(viper.GetInt("messageBus.requestQos"))
+	}
+	if token := listener.client.SubscribeMultiple(filters, listener.onObjectsSaved); token.Wait() && token.Error() != nil {
+		return token.Error()
+	}
+
+	return nil
+}
+
+func (listener *ObjectCreationListener) Stop() {
+	if listener.client == nil || !listener.client.IsConnected() {
+		return
+	}
+	listener.client.Disconnect(250)
+}
+
+func (listener *ObjectCreationListener) onObjectsSaved(client MQTT.Client, message MQTT.Message) {
+	logger := logging.GetLogger(ApplicationName, listener.baseAction.Environment, true)
+	objectType, objects, err := attributes.CreatedObjects(message.Topic(), message.Payload())
+	if err != nil {
+		logger.WithError(err).Warnf("Could not read the save event on %v", message.Topic())
+		return
+	}
+
+	for _, object := range objects {
+		changes, err := listener.AssignAttributes(context.Background(), objectType, object)
+		if err != nil {
+			logger.WithError(err).Errorf("Could not assign the attributes of %v %v", objectType, object.ObjectId)
+			continue
+		}
+		if len(changes) > 0 {
+			sendAttributeValueChangedEvent(listener.ProvideInformation(), "", listener.baseAction.Environment, changes)
+		}
+	}
+}
+
+// AssignAttributes saves the default values of the attributes that are assigned during the creation of the object.
+// Values that already exist, e.g. because the event was delivered twice, are kept, values that are not valid for
+// their definition are skipped.
+func (listener *ObjectCreationListener) AssignAttributes(ctx context.Context, objectType string, object attributes.CreatedObject) ([]structs.AttributeChange, error) {
+	logger := logging.GetLogger(ApplicationName, listener.baseAction.Environment, true)
+	definitions, orionErr := getAttributeDefinitionsFromDb(ctx, listener.baseAction, bson.M{"assign_during_object_creation": true})
+	if orionErr != nil {
+		return nil, orionErr.Error
+	}
+	defaults, errs := attributes.CreationDefaults(definitions, objectType, object.ObjectId, object.Fields)
+	for _, err := range errs {
+		logger.WithError(err).Warnf("Skipped an attribute of %v %v", objectType, object.ObjectId)
+	}
+	if len(defaults) == 0 {
+		return nil, nil
+	}
+
+	validationErrors, orionErr := validateAttributeValues(ctx, listener.baseAction, defaults)
+	if orionErr != nil {
+		return nil, orionErr.Error
+	}
+	invalid := make(map[string]bool, len(validationErrors))
+	for _, validationError := range validationErrors {
+		invalid[validationError.AttributeId] = true
+		logger.Warnf("The default value of attribute %v for %v %v is invalid: %v", validationError.AttributeId,
+			objectType, object.ObjectId, validationError.Message)
+	}
+	values := make([]structs.AttributeValue, 0, len(defaults))
+	for _, value := range defaults {
+		if !invalid[value.AttributeId] {
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	// the stored values are checked in the transaction, the unique index on the values of an object makes a
+	// concurrent delivery of the same event fail instead of assigning the values twice
+	changes, orionErr := saveAttributeValues(ctx, listener.baseAction, values, ApplicationName,
+		fmt.Sprintf("Assigned during the creation of %v %v", objectType, object.ObjectId), true)
+	if orionErr != nil {
+		return nil, orionErr.Error
+	}
+
+	return changes, nil
+}
+
+func (listener *ObjectCreationListener) ProvideInformation() micro.ActionInformation {
+	return micro.ActionInformation{
+		Name:        "ObjectCreationListener",
+		Description: "Assigns the attributes flagged for assignment during object creation to newly created objects",
+		EventTopic:  AttributeValueChangedEventTopic,
+		Version:     1,
+		ClientId:    listener.baseAction.ID.String(),
+	}
+}
+
+// insertedObjectId returns the id MongoDB generated for an inserted document. Save events must carry it, the
+// attributes of new objects are assigned by it.
+func insertedObjectId(result *mongo.InsertOneResult) *primitive.ObjectID {
+	if result == nil {
+		return nil
+	}
+	if id, ok := result.InsertedID.(primitive.ObjectID); ok {
+		return &id
+	}
+
+	return nil
+}
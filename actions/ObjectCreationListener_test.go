@@ -0,0 +1,51 @@
+package actions
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"orion.misc/attributes"
+	"orion.misc/structs"
+)
+
+func TestCreatedObjectsOfSaveEvent(t *testing.T) {
+	created := structs.Category{ID: insertedObjectId(&mongo.InsertOneResult{InsertedID: primitive.NewObjectID()})}
+	created.Info.Name = "new"
+	updatedId := primitive.NewObjectID()
+	changeDate := int64(1600000000000)
+	updated := structs.Category{ID: &updatedId}
+	updated.Info.Name = "changed"
+	updated.Info.ChangeDate = &changeDate
+	event := structs.CategorySavedEvent{
+		ObjectType: "CATEGORY",
+		Categories: []structs.Category{created, updated},
+	}
+	payload, err := event.ToJsonString()
+	if err != nil {
+		t.Fatalf("ToJsonString failed: %v", err)
+	}
+
+	objectType, objects, err := attributes.CreatedObjects("orion/server/misc/event/category/save", []byte(payload))
+	if err != nil {
+		t.Fatalf("CreatedObjects failed: %v", err)
+	}
+	if objectType != "CATEGORY" {
+		t.Fatalf("CreatedObjects() object type = %v, want CATEGORY", objectType)
+	}
+	if len(objects) != 1 || objects[0].ObjectId != created.ID.Hex() {
+		t.Fatalf("CreatedObjects() = %v, want the new category %v only", objects, created.ID.Hex())
+	}
+	if objects[0].Fields["info"].(map[string]interface{})["name"] != "new" {
+		t.Fatalf("CreatedObjects() fields = %v, want the published category", objects[0].Fields)
+	}
+}
+
+func TestInsertedObjectId(t *testing.T) {
+	if id := insertedObjectId(nil); id != nil {
+		t.Fatalf("insertedObjectId(nil) = %v, want nil", id)
+	}
+	if id := insertedObjectId(&mongo.InsertOneResult{InsertedID: "custom"}); id != nil {
+		t.Fatalf("insertedObjectId() of a custom id = %v, want nil", id)
+	}
+}
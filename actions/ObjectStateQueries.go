@@ -0,0 +1,200 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/abenstex/laniakea/logging"
+	"github.com/abenstex/laniakea/micro"
+	"github.com/abenstex/laniakea/mongodb"
+	"github.com/abenstex/laniakea/mqtt"
+	utils2 "github.com/abenstex/laniakea/utils"
+	structs2 "github.com/abenstex/orion.commons/structs"
+	"github.com/abenstex/orion.commons/utils"
+	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"orion.misc/statemachine"
+	"orion.misc/structs"
+)
+
+const StateChangedEventTopic = "orion/server/misc/event/state/changed"
+
+var errObjectStateChanged = errors.New("the state of the object was changed concurrently")
+
+func getObjectStateFromDb(ctx context.Context, baseAction micro.BaseAction, objectType, objectId string) (*structs.ObjectState, *structs2.OrionError) {
+	var objectState structs.ObjectState
+	err := baseAction.Environment.MongoDbConnection.Database().Collection("object_states").
+		FindOne(ctx, bson.M{"object_type": objectType, "object_id": objectId}).Decode(&objectState)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, structs2.NewOrionError(structs2.DatabaseError, err)
+	}
+
+	return &objectState, nil
+}
+
+func getObjectStateHistoryFromDb(ctx context.Context, baseAction micro.BaseAction, objectType, objectId string) ([]structs.ObjectStateTransition, *structs2.OrionError) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "timestamp", Value: 1}})
+	cursor, err := baseAction.Environment.MongoDbConnection.Database().Collection("object_state_transitions").
+		Find(ctx, bson.M{"object_type": objectType, "object_id": objectId}, findOptions)
+	if err != nil {
+		return nil, structs2.NewOrionError(structs2.DatabaseError, err)
+	}
+	objects := make([]structs.ObjectStateTransition, 0)
+	if err = cursor.All(ctx, &objects); err != nil {
+		return nil, structs2.NewOrionError(structs2.DatabaseError, err)
+	}
+
+	return objects, nil
+}
+
+// getUserRoles reads the roles of a user from the user_roles collection, which is maintained with the
+// SaveUserRolesAction. Users without an entry have no roles, so every role guard denies them.
+func getUserRoles(ctx context.Context, baseAction micro.BaseAction, user string) ([]string, *structs2.OrionError) {
+	if len(user) == 0 {
+		return []string{}, nil
+	}
+	var userRoles structs.UserRoles
+	err := baseAction.Environment.MongoDbConnection.Database().Collection("user_roles").
+		FindOne(ctx, bson.M{"user": user}).Decode(&userRoles)
+	if err == mongo.ErrNoDocuments {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, structs2.NewOrionError(structs2.DatabaseError, err)
+	}
+
+	return userRoles.Roles, nil
+}
+
+// applyObjectStateTransition validates the transition of an object against the stored rules and, if it is allowed,
+// moves the object into the target state and records the transition in its history. Now is the time in milliseconds
+// the transition is recorded with.
+func applyObjectStateTransition(ctx context.Context, baseAction micro.BaseAction, objectType, objectId string,
+	input statemachine.TransitionInput, now int64) (*structs.ObjectStateTransition, statemachine.Decision, *structs2.OrionError) {
+	objectState, orionErr := getObjectStateFromDb(ctx, baseAction, objectType, objectId)
+	if orionErr != nil {
+		return nil, statemachine.Decision{}, orionErr
+	}
+	createdDate := now
+	if objectState != nil {
+		createdDate = objectState.CreatedDate
+	}
+	definition, orionErr := resolveStateMachineDefinition(ctx, baseAction, objectType, createdDate)
+	if orionErr != nil {
+		return nil, statemachine.Decision{}, orionErr
+	}
+
+	var decision statemachine.Decision
+	if objectState == nil {
+		input.CurrentState = ""
+		decision = definition.EvaluateInitialState(input.TargetState)
+	} else {
+		input.CurrentState = objectState.CurrentState
+		decision = definition.Evaluate(input)
+	}
+	if !decision.Allowed {
+		return nil, decision, nil
+	}
+
+	transition := structs.ObjectStateTransition{
+		ObjectType:  objectType,
+		ObjectId:    objectId,
+		SourceState: input.CurrentState,
+		TargetState: definition.StateKey(input.TargetState),
+		User:        input.User,
+		Comment:     input.Comment,
+		Timestamp:   now,
+	}
+	if decision.MatchingRule != nil && decision.MatchingRule.ID != nil {
+		ruleId := decision.MatchingRule.ID.Hex()
+		transition.RuleId = &ruleId
+	}
+
+	callback := func(sessCtx mongo.SessionContext) (interface{}, error) {
+		collection := baseAction.Environment.MongoDbConnection.Database().Collection("object_states")
+		filter := bson.M{"object_type": objectType, "object_id": objectId}
+		if objectState == nil {
+			newState := structs.ObjectState{
+				ObjectType:   objectType,
+				ObjectId:     objectId,
+				CurrentState: transition.TargetState,
+				EnteredDate:  now,
+				User:         input.User,
+				CreatedDate:  now,
+			}
+			// a concurrent first transition inserted the state already if the unique index on the object rejects it
+			result, err := collection.UpdateOne(sessCtx, filter, bson.M{"$setOnInsert": newState}, options.Update().SetUpsert(true))
+			if mongo.IsDuplicateKeyError(err) {
+				return nil, errObjectStateChanged
+			}
+			if err != nil {
+				return nil, err
+			}
+			if result.UpsertedCount == 0 {
+				return nil, errObjectStateChanged
+			}
+		} else {
+			filter["current_state"] = objectState.CurrentState
+			update := bson.M{
+				"$set":   bson.M{"current_state": transition.TargetState, "entered_date": now, "user": input.User},
+				"$unset": bson.M{"escalated_date": ""},
+			}
+			result, err := collection.UpdateOne(sessCtx, filter, update)
+			if err != nil {
+				return nil, err
+			}
+			if result.MatchedCount == 0 {
+				return nil, errObjectStateChanged
+			}
+		}
+		_, err := mongodb.InsertOne(sessCtx, baseAction.Environment.MongoDbConnection, "object_state_transitions", transition)
+
+		return nil, err
+	}
+	_, err := mongodb.PerformQueriesInTransaction(ctx, baseAction.Environment.MongoDbConnection, callback)
+	if err != nil {
+		return nil, decision, structs2.NewOrionError(structs2.DatabaseError, fmt.Errorf("error executing queries in transaction: %v", err))
+	}
+
+	return &transition, decision, nil
+}
+
+func getObjectStatesFromDb(ctx context.Context, baseAction micro.BaseAction, filter bson.M) ([]structs.ObjectState, *structs2.OrionError) {
+	cursor, err := baseAction.Environment.MongoDbConnection.Database().Collection("object_states").Find(ctx, filter)
+	if err != nil {
+		return nil, structs2.NewOrionError(structs2.DatabaseError, err)
+	}
+	objects := make([]structs.ObjectState, 0)
+	if err = cursor.All(ctx, &objects); err != nil {
+		return nil, structs2.NewOrionError(structs2.DatabaseError, err)
+	}
+
+	return objects, nil
+}
+
+func sendStateChangedEvent(actionInfo micro.ActionInformation, senderId string, environment utils2.Environment, transition structs.ObjectStateTransition) {
+	event := structs.StateChangedEvent{
+		Header:      *micro.NewEventHeaderForAction(actionInfo, senderId, ""),
+		ObjectType:  transition.ObjectType,
+		ObjectId:    transition.ObjectId,
+		SourceState: transition.SourceState,
+		TargetState: transition.TargetState,
+		User:        transition.User,
+		Comment:     transition.Comment,
+		Timestamp:   transition.Timestamp,
+	}
+
+	json, err := event.ToJsonString()
+	if err != nil {
+		logging.GetLogger(actionInfo.Name, environment, true).WithError(err).Error("Could not send events")
+
+		return
+	}
+	mqtt.Publish(StateChangedEventTopic, json, byte(viper.GetInt("messageBus.publishEventQos")),
+		utils.GetDefaultMqttConnectionOptionsWithIdPrefix(actionInfo.Name))
+}
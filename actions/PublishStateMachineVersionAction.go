@@ -0,0 +1,213 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/abenstex/laniakea/dataStructures"
+	"github.com/abenstex/laniakea/logging"
+	"github.com/abenstex/laniakea/micro"
+	"github.com/abenstex/laniakea/mongodb"
+	"github.com/abenstex/laniakea/mqtt"
+	utils2 "github.com/abenstex/laniakea/utils"
+	"github.com/abenstex/orion.commons/app"
+	http2 "github.com/abenstex/orion.commons/http"
+	structs2 "github.com/abenstex/orion.commons/structs"
+	"github.com/abenstex/orion.commons/utils"
+	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/mongo"
+	"net/http"
+	"orion.misc/structs"
+	"time"
+)
+
+type PublishStateMachineVersionAction struct {
+	baseAction       micro.BaseAction
+	MetricsStore     *utils.MetricsStore
+	publishedVersion *structs.StateMachineVersion
+}
+
+func (action PublishStateMachineVersionAction) BeforeAction(ctx context.Context, request []byte) *micro.Exception {
+	dummy := structs.PublishStateMachineVersionRequest{}
+	err := json.Unmarshal(request, &dummy)
+	if err != nil {
+		return micro.NewException(structs2.UnmarshalError, err)
+	}
+	err = app.DefaultHandleActionRequest(request, &dummy.Header, &action, true)
+	if err != nil {
+		return micro.NewException(structs2.RequestHeaderInvalid, err)
+	}
+	if len(dummy.ReferencedType) == 0 {
+		return micro.NewException(structs2.MissingParameterError, fmt.Errorf("the parameter referenced_type was not provided"))
+	}
+
+	return nil
+}
+
+func (action PublishStateMachineVersionAction) BeforeActionAsync(ctx context.Context, request []byte) {
+
+}
+
+func (action PublishStateMachineVersionAction) AfterAction(ctx context.Context, reply *micro.IReply, request *micro.IRequest) *micro.Exception {
+	return nil
+}
+
+func (action PublishStateMachineVersionAction) AfterActionAsync(ctx context.Context, reply micro.IReply, request micro.IRequest) {
+
+}
+
+func (action PublishStateMachineVersionAction) GetBaseAction() micro.BaseAction {
+	return action.baseAction
+}
+
+func (action *PublishStateMachineVersionAction) SetHttpRequest(request *http.Request) {
+	action.baseAction.Request = request
+}
+
+func (action *PublishStateMachineVersionAction) InitBaseAction(baseAction micro.BaseAction) {
+	action.baseAction = baseAction
+}
+
+func (action PublishStateMachineVersionAction) SendEvents(request micro.IRequest) {
+	publishRequest := request.(*structs.PublishStateMachineVersionRequest)
+	if !publishRequest.Header.WasExecutedSuccessfully || action.publishedVersion == nil {
+		logging.GetLogger("PublishStateMachineVersionAction",
+			action.GetBaseAction().Environment,
+			true).Warn("RequestFailedEvent will be sent because the request was not successfully executed")
+		blerghEvent := structs2.NewRequestFailedEvent(publishRequest, action.ProvideInformation(), action.baseAction.ID.String(), "")
+		blerghEvent.Send(action.ProvideInformation().ErrorReplyTopic, byte(viper.GetInt("messageBus.publishEventQos")),
+			utils.GetDefaultMqttConnectionOptionsWithIdPrefix(action.ProvideInformation().Name))
+		return
+	}
+
+	event := structs.StateMachineVersionPublishedEvent{
+		Header:         *micro.NewEventHeaderForAction(action.ProvideInformation(), publishRequest.Header.SenderId, ""),
+		ReferencedType: action.publishedVersion.ReferencedType,
+		Version:        action.publishedVersion.Version,
+		EffectiveFrom:  action.publishedVersion.EffectiveFrom,
+	}
+
+	json, err := event.ToJsonString()
+	if err != nil {
+		logging.GetLogger("PublishStateMachineVersionAction", action.GetBaseAction().Environment, true).WithError(err).Error("Could not send events")
+
+		return
+	}
+	mqtt.Publish(action.ProvideInformation().EventTopic, json, byte(viper.GetInt("messageBus.publishEventQos")),
+		utils.GetDefaultMqttConnectionOptionsWithIdPrefix(action.ProvideInformation().Name))
+}
+
+func (action PublishStateMachineVersionAction) ProvideInformation() micro.ActionInformation {
+	var reply = "orion/server/misc/reply/state/version/publish"
+	var error = "orion/server/misc/error/state/version/publish"
+	var event = "orion/server/misc/event/state/version/publish"
+	var requestSample = dataStructures.StructToJsonString(structs.PublishStateMachineVersionRequest{})
+	var replySample = dataStructures.StructToJsonString(micro.ReplyHeader{})
+	var eventSample = dataStructures.StructToJsonString(structs.StateMachineVersionPublishedEvent{})
+	info := micro.ActionInformation{
+		Name:            "PublishStateMachineVersionAction",
+		Description:     "Publishes the current states and state transition rules of a referenced type as an immutable version",
+		RequestTopic:    "orion/server/misc/request/state/version/publish",
+		ReplyTopic:      reply,
+		ErrorReplyTopic: error,
+		Version:         1,
+		ClientId:        action.GetBaseAction().ID.String(),
+		HttpMethods:     []string{http.MethodPost, "OPTIONS"},
+		EventTopic:      event,
+		RequestSample:   &requestSample,
+		ReplySample:     &replySample,
+		EventSample:     &eventSample,
+		IsScriptable:    false,
+	}
+
+	return info
+}
+
+func (action *PublishStateMachineVersionAction) HandleWebRequest(writer http.ResponseWriter, request *http.Request) {
+	action.SetHttpRequest(request)
+	http2.HandleHttpRequest(writer, request, action)
+}
+
+func (action *PublishStateMachineVersionAction) HeyHo(ctx context.Context, request []byte) (micro.IReply, micro.IRequest) {
+	start := time.Now()
+	defer action.MetricsStore.HandleActionMetric(start, action.GetBaseAction().Environment, action.ProvideInformation(), *action.baseAction.Token)
+	action.publishedVersion = nil
+
+	publishRequest := structs.PublishStateMachineVersionRequest{}
+	err := json.Unmarshal(request, &publishRequest)
+	if err != nil {
+		logging.GetLogger(action.ProvideInformation().Name, action.baseAction.Environment, true).WithError(err).Error("Could not unmarshal request")
+		return structs2.NewErrorReplyHeaderWithOrionErr(structs2.NewOrionError(structs2.UnmarshalError, err),
+			action.ProvideInformation().ErrorReplyTopic), &publishRequest
+	}
+
+	orionErr := action.publishVersion(ctx, publishRequest)
+	if orionErr != nil {
+		logging.GetLogger(action.ProvideInformation().Name,
+			action.GetBaseAction().Environment,
+			true).WithError(orionErr.Error).Error("Version could not be published")
+		return structs2.NewErrorReplyHeaderWithOrionErr(orionErr,
+			action.ProvideInformation().ErrorReplyTopic), &publishRequest
+	}
+
+	reply := structs2.NewReplyHeader(action.ProvideInformation().ReplyTopic)
+	reply.Success = true
+
+	return reply, &publishRequest
+}
+
+func (action *PublishStateMachineVersionAction) publishVersion(ctx context.Context, request structs.PublishStateMachineVersionRequest) *structs2.OrionError {
+	definition, orionErr := loadStateMachineDefinition(ctx, action.baseAction, request.ReferencedType)
+	if orionErr != nil {
+		return orionErr
+	}
+	if len(definition.States) == 0 {
+		return structs2.NewOrionError(structs2.NoDataFound, fmt.Errorf("no states were found for %v", request.ReferencedType))
+	}
+	versions, orionErr := getStateMachineVersionsFromDb(ctx, action.baseAction, request.ReferencedType)
+	if orionErr != nil {
+		return orionErr
+	}
+
+	now := utils2.GetCurrentTimeStamp()
+	effectiveFrom := now
+	if request.EffectiveFrom != nil {
+		effectiveFrom = *request.EffectiveFrom
+	}
+	nextVersion := 1
+	for _, version := range versions {
+		if version.EffectiveFrom > effectiveFrom {
+			return structs2.NewOrionError(structs.ValidationError,
+				fmt.Errorf("version %d is already effective from %d which is after %d", version.Version, version.EffectiveFrom, effectiveFrom))
+		}
+		if version.Version >= nextVersion {
+			nextVersion = version.Version + 1
+		}
+	}
+
+	objectType := "STATE_MACHINE_VERSION"
+	version := structs.StateMachineVersion{
+		ReferencedType: request.ReferencedType,
+		Version:        nextVersion,
+		EffectiveFrom:  effectiveFrom,
+		States:         definition.States,
+		Rules:          definition.Rules,
+	}
+	version.Info.Name = fmt.Sprintf("%v v%d", request.ReferencedType, nextVersion)
+	version.Info.CreatedDate = now
+	version.Info.User = &request.Header.User
+	version.Info.UserComment = &request.Header.Comment
+	version.Info.ObjectType = &objectType
+
+	_, err := mongodb.InsertOne(ctx, action.baseAction.Environment.MongoDbArchiveConnection, "state_machine_versions", version)
+	if mongo.IsDuplicateKeyError(err) {
+		return structs2.NewOrionError(structs.ValidationError,
+			fmt.Errorf("version %d of %v was published concurrently, please retry", nextVersion, request.ReferencedType))
+	}
+	if err != nil {
+		return structs2.NewOrionError(structs2.DatabaseError, err)
+	}
+	action.publishedVersion = &version
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/abenstex/laniakea/dataStructures"
+	"github.com/abenstex/laniakea/micro"
+	utils2 "github.com/abenstex/laniakea/utils"
+	"github.com/abenstex/orion.commons/app"
+	http2 "github.com/abenstex/orion.commons/http"
+	structs2 "github.com/abenstex/orion.commons/structs"
+	"github.com/abenstex/orion.commons/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"net/http"
+	"orion.misc/hierarchy"
+	"orion.misc/structs"
+	"time"
+)
+
+type ResolveHierarchiesAction struct {
+	baseAction      micro.BaseAction
+	MetricsStore    *utils.MetricsStore
+	receivedRequest structs.ResolveHierarchiesRequest
+}
+
+func (action *ResolveHierarchiesAction) BeforeAction(ctx context.Context, request []byte) *micro.Exception {
+	dummy := structs.ResolveHierarchiesRequest{}
+	err := json.Unmarshal(request, &dummy)
+	if err != nil {
+		return micro.NewException(structs2.UnmarshalError, err)
+	}
+	err = app.DefaultHandleActionRequest(request, &dummy.Header, action, true)
+	if err != nil {
+		return micro.NewException(structs2.RequestHeaderInvalid, err)
+	}
+	if len(dummy.ObjectType) == 0 {
+		return micro.NewException(structs2.MissingParameterError, fmt.Errorf("the parameter object_type was not provided"))
+	}
+
+	return nil
+}
+
+func (action ResolveHierarchiesAction) BeforeActionAsync(ctx context.Context, request []byte) {
+
+}
+
+func (action ResolveHierarchiesAction) AfterAction(ctx context.Context, reply *micro.IReply, request *micro.IRequest) *micro.Exception {
+	return nil
+}
+
+func (action ResolveHierarchiesAction) AfterActionAsync(ctx context.Context, reply micro.IReply, request micro.IRequest) {
+
+}
+
+func (action ResolveHierarchiesAction) GetBaseAction() micro.BaseAction {
+	return action.baseAction
+}
+
+func (action *ResolveHierarchiesAction) SetHttpRequest(request *http.Request) {
+	action.baseAction.Request = request
+}
+
+func (action *ResolveHierarchiesAction) InitBaseAction(baseAction micro.BaseAction) {
+	action.baseAction = baseAction
+}
+
+func (action ResolveHierarchiesAction) SendEvents(request micro.IRequest) {
+
+}
+
+func (action ResolveHierarchiesAction) ProvideInformation() micro.ActionInformation {
+	var reply = "orion/server/misc/reply/hierarchy/resolve"
+	var error = "orion/server/misc/error/hierarchy/resolve"
+	var requestSample = dataStructures.StructToJsonString(structs.ResolveHierarchiesRequest{})
+	var replySample = dataStructures.StructToJsonString(structs.ResolveHierarchiesReply{})
+	info := micro.ActionInformation{
+		Name:            "ResolveHierarchiesAction",
+		Description:     "Gets every hierarchy an object type takes part in together with its ordered ancestor and descendant object types",
+		RequestTopic:    "orion/server/misc/request/hierarchy/resolve",
+		ReplyTopic:      reply,
+		ErrorReplyTopic: error,
+		Version:         1,
+		ClientId:        action.baseAction.ID.String(),
+		HttpMethods:     []string{http.MethodPost, "OPTIONS"},
+		RequestSample:   &requestSample,
+		ReplySample:     &replySample,
+		IsScriptable:    false,
+	}
+
+	return info
+}
+
+func (action *ResolveHierarchiesAction) HandleWebRequest(writer http.ResponseWriter, request *http.Request) {
+	action.SetHttpRequest(request)
+	http2.HandleHttpRequest(writer, request, action)
+}
+
+func (action *ResolveHierarchiesAction) HeyHo(ctx context.Context, request []byte) (micro.IReply, micro.IRequest) {
+	start := time.Now()
+	defer action.MetricsStore.HandleActionMetric(start, action.GetBaseAction().Environment, action.ProvideInformation(), *action.baseAction.Token)
+
+	err := json.Unmarshal(request, &action.receivedRequest)
+	if err != nil {
+		return structs2.NewErrorReplyHeaderWithException(micro.NewException(structs2.UnmarshalError, err),
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	hierarchies, myErr := getHierarchiesFromDb(ctx, action.baseAction, bson.M{"entries.object_type": action.receivedRequest.ObjectType})
+	if myErr != nil {
+		return structs2.NewErrorReplyHeaderWithOrionErr(myErr,
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	reply, myErr := action.createResolveHierarchiesReply(hierarchy.Resolve(hierarchies, action.receivedRequest.ObjectType))
+	if myErr != nil {
+		return structs2.NewErrorReplyHeaderWithOrionErr(myErr,
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	return reply, &action.receivedRequest
+}
+
+func (action ResolveHierarchiesAction) createResolveHierarchiesReply(objects []structs.HierarchyResolution) (structs.ResolveHierarchiesReply, *structs2.OrionError) {
+	var reply = structs.ResolveHierarchiesReply{}
+	reply.Header = structs2.NewReplyHeader(action.ProvideInformation().ReplyTopic)
+	reply.Header.Timestamp = utils2.GetCurrentTimeStamp()
+	if len(objects) > 0 {
+		reply.Header.Success = true
+		reply.Hierarchies = objects
+		return reply, nil
+	}
+	reply.Header.Success = false
+	errorMsg := "The object type is not part of any hierarchy"
+	reply.Header.ErrorMessage = &errorMsg
+
+	err := errors.New(errorMsg)
+
+	return reply, structs2.NewOrionError(structs2.NoDataFound, err)
+}
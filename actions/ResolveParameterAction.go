@@ -0,0 +1,146 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/abenstex/laniakea/dataStructures"
+	"github.com/abenstex/laniakea/micro"
+	utils2 "github.com/abenstex/laniakea/utils"
+	"github.com/abenstex/orion.commons/app"
+	http2 "github.com/abenstex/orion.commons/http"
+	structs2 "github.com/abenstex/orion.commons/structs"
+	"github.com/abenstex/orion.commons/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"net/http"
+	"orion.misc/parameters"
+	"orion.misc/structs"
+	"time"
+)
+
+type ResolveParameterAction struct {
+	baseAction      micro.BaseAction
+	MetricsStore    *utils.MetricsStore
+	receivedRequest structs.ResolveParameterRequest
+}
+
+func (action *ResolveParameterAction) BeforeAction(ctx context.Context, request []byte) *micro.Exception {
+	dummy := structs.ResolveParameterRequest{}
+	err := json.Unmarshal(request, &dummy)
+	if err != nil {
+		return micro.NewException(structs2.UnmarshalError, err)
+	}
+	err = app.DefaultHandleActionRequest(request, &dummy.Header, action, true)
+	if err != nil {
+		return micro.NewException(structs2.RequestHeaderInvalid, err)
+	}
+	if len(dummy.Name) == 0 {
+		return micro.NewException(structs2.MissingParameterError, fmt.Errorf("the parameter name was not provided"))
+	}
+
+	return nil
+}
+
+func (action ResolveParameterAction) BeforeActionAsync(ctx context.Context, request []byte) {
+
+}
+
+func (action ResolveParameterAction) AfterAction(ctx context.Context, reply *micro.IReply, request *micro.IRequest) *micro.Exception {
+	return nil
+}
+
+func (action ResolveParameterAction) AfterActionAsync(ctx context.Context, reply micro.IReply, request micro.IRequest) {
+
+}
+
+func (action ResolveParameterAction) GetBaseAction() micro.BaseAction {
+	return action.baseAction
+}
+
+func (action *ResolveParameterAction) SetHttpRequest(request *http.Request) {
+	action.baseAction.Request = request
+}
+
+func (action *ResolveParameterAction) InitBaseAction(baseAction micro.BaseAction) {
+	action.baseAction = baseAction
+}
+
+func (action ResolveParameterAction) SendEvents(request micro.IRequest) {
+
+}
+
+func (action ResolveParameterAction) ProvideInformation() micro.ActionInformation {
+	var reply = "orion/server/misc/reply/parameter/resolve"
+	var error = "orion/server/misc/error/parameter/resolve"
+	var requestSample = dataStructures.StructToJsonString(structs.ResolveParameterRequest{})
+	var replySample = dataStructures.StructToJsonString(structs.ResolveParameterReply{})
+	info := micro.ActionInformation{
+		Name:            "ResolveParameterAction",
+		Description:     "Resolves the effective value of a parameter for an environment, application, instance and user together with all overrides that apply",
+		RequestTopic:    "orion/server/misc/request/parameter/resolve",
+		ReplyTopic:      reply,
+		ErrorReplyTopic: error,
+		Version:         1,
+		ClientId:        action.baseAction.ID.String(),
+		HttpMethods:     []string{http.MethodPost, "OPTIONS"},
+		RequestSample:   &requestSample,
+		ReplySample:     &replySample,
+		IsScriptable:    false,
+	}
+
+	return info
+}
+
+func (action *ResolveParameterAction) HandleWebRequest(writer http.ResponseWriter, request *http.Request) {
+	action.SetHttpRequest(request)
+	http2.HandleHttpRequest(writer, request, action)
+}
+
+func (action *ResolveParameterAction) HeyHo(ctx context.Context, request []byte) (micro.IReply, micro.IRequest) {
+	start := time.Now()
+	defer action.MetricsStore.HandleActionMetric(start, action.GetBaseAction().Environment, action.ProvideInformation(), *action.baseAction.Token)
+
+	err := json.Unmarshal(request, &action.receivedRequest)
+	if err != nil {
+		return structs2.NewErrorReplyHeaderWithException(micro.NewException(structs2.UnmarshalError, err),
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	candidates, myErr := getParametersFromDb(ctx, action.baseAction, bson.M{"info.name": action.receivedRequest.Name})
+	if myErr != nil {
+		return structs2.NewErrorReplyHeaderWithOrionErr(myErr,
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+	resolution, err := parameters.Resolve(action.receivedRequest.Name, candidates, action.receivedRequest.Context)
+	if err != nil {
+		return structs2.NewErrorReplyHeaderWithOrionErr(structs2.NewOrionError(structs.ValidationError, err),
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	reply, myErr := action.createResolveParameterReply(resolution)
+	if myErr != nil {
+		return structs2.NewErrorReplyHeaderWithOrionErr(myErr,
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	return reply, &action.receivedRequest
+}
+
+func (action ResolveParameterAction) createResolveParameterReply(resolution *structs.ParameterResolution) (structs.ResolveParameterReply, *structs2.OrionError) {
+	var reply = structs.ResolveParameterReply{}
+	reply.Header = structs2.NewReplyHeader(action.ProvideInformation().ReplyTopic)
+	reply.Header.Timestamp = utils2.GetCurrentTimeStamp()
+	if resolution != nil {
+		reply.Header.Success = true
+		reply.Resolution = *resolution
+		return reply, nil
+	}
+	reply.Header.Success = false
+	errorMsg := "The parameter has no value for this context"
+	reply.Header.ErrorMessage = &errorMsg
+
+	err := errors.New(errorMsg)
+
+	return reply, structs2.NewOrionError(structs2.NoDataFound, err)
+}
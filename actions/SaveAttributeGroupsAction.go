@@ -0,0 +1,226 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/abenstex/laniakea/dataStructures"
+	"github.com/abenstex/laniakea/logging"
+	"github.com/abenstex/laniakea/micro"
+	"github.com/abenstex/laniakea/mongodb"
+	"github.com/abenstex/laniakea/mqtt"
+	laniakea "github.com/abenstex/laniakea/utils"
+	"github.com/abenstex/orion.commons/app"
+	http2 "github.com/abenstex/orion.commons/http"
+	"github.com/abenstex/orion.commons/structs"
+	"github.com/abenstex/orion.commons/utils"
+	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"net/http"
+	"orion.misc/attributes"
+	structs2 "orion.misc/structs"
+	"time"
+)
+
+type SaveAttributeGroupsAction struct {
+	baseAction   micro.BaseAction
+	MetricsStore *utils.MetricsStore
+	savedObjects []structs2.AttributeGroup
+	saveRequest  structs2.SaveAttributeGroupsRequest
+	startedTime  int64
+}
+
+func (action *SaveAttributeGroupsAction) BeforeAction(ctx context.Context, request []byte) *micro.Exception {
+	dummy := structs2.SaveAttributeGroupsRequest{}
+	err := json.Unmarshal(request, &dummy)
+	if err != nil {
+		return micro.NewException(structs.UnmarshalError, err)
+	}
+	err = app.DefaultHandleActionRequest(request, &dummy.Header, action, true)
+
+	action.saveRequest = dummy
+
+	if err != nil {
+		return micro.NewException(structs.RequestHeaderInvalid, err)
+	}
+
+	return nil
+}
+
+func (action SaveAttributeGroupsAction) BeforeActionAsync(ctx context.Context, request []byte) {
+
+}
+
+func (action SaveAttributeGroupsAction) AfterAction(ctx context.Context, reply *micro.IReply, request *micro.IRequest) *micro.Exception {
+	return nil
+}
+
+func (action SaveAttributeGroupsAction) AfterActionAsync(ctx context.Context, reply micro.IReply, request micro.IRequest) {
+
+}
+
+func (action SaveAttributeGroupsAction) GetBaseAction() micro.BaseAction {
+	return action.baseAction
+}
+
+func (action *SaveAttributeGroupsAction) SetHttpRequest(request *http.Request) {
+	action.baseAction.Request = request
+}
+
+func (action *SaveAttributeGroupsAction) InitBaseAction(baseAction micro.BaseAction) {
+	action.baseAction = baseAction
+}
+
+func (action SaveAttributeGroupsAction) SendEvents(request micro.IRequest) {
+	saveRequest := request.(*structs2.SaveAttributeGroupsRequest)
+	if !saveRequest.Header.WasExecutedSuccessfully {
+		logging.GetLogger("SaveAttributeGroupsAction",
+			action.GetBaseAction().Environment,
+			true).Warn("RequestFailedEvent will be sent because the request was not successfully executed")
+		blerghEvent := structs.NewRequestFailedEvent(saveRequest, action.ProvideInformation(), action.baseAction.ID.String(), "")
+		blerghEvent.Send(action.ProvideInformation().ErrorReplyTopic, byte(viper.GetInt("messageBus.publishEventQos")),
+			utils.GetDefaultMqttConnectionOptionsWithIdPrefix(action.ProvideInformation().Name))
+		return
+	}
+
+	event := structs2.AttributeGroupSavedEvent{
+		Header:          *micro.NewEventHeaderForAction(action.ProvideInformation(), saveRequest.Header.SenderId, ""),
+		AttributeGroups: action.savedObjects,
+		ObjectType:      "ATTRIBUTE_GROUP",
+	}
+
+	json, err := event.ToJsonString()
+	if err != nil {
+		logging.GetLogger("SaveAttributeGroupsAction", action.GetBaseAction().Environment, true).WithError(err).Error("Could not send events")
+
+		return
+	}
+	mqtt.Publish(action.ProvideInformation().EventTopic, json, byte(viper.GetInt("messageBus.publishEventQos")),
+		utils.GetDefaultMqttConnectionOptionsWithIdPrefix(action.ProvideInformation().Name))
+}
+
+func (action SaveAttributeGroupsAction) ProvideInformation() micro.ActionInformation {
+	var reply = "orion/server/misc/reply/attributegroup/save"
+	var error = "orion/server/misc/error/attributegroup/save"
+	var event = "orion/server/misc/event/attributegroup/save"
+	var requestSample = dataStructures.StructToJsonString(structs2.SaveAttributeGroupsRequest{})
+	var replySample = dataStructures.StructToJsonString(micro.ReplyHeader{})
+	var eventSample = dataStructures.StructToJsonString(structs2.AttributeGroupSavedEvent{})
+	info := micro.ActionInformation{
+		Name:            "SaveAttributeGroupsAction",
+		Description:     "Saves attribute groups, their ordered members and display settings to the database",
+		RequestTopic:    "orion/server/misc/request/attributegroup/save",
+		ReplyTopic:      reply,
+		ErrorReplyTopic: error,
+		Version:         1,
+		ClientId:        action.GetBaseAction().ID.String(),
+		HttpMethods:     []string{http.MethodPost, "OPTIONS"},
+		EventTopic:      event,
+		RequestSample:   &requestSample,
+		ReplySample:     &replySample,
+		EventSample:     &eventSample,
+		IsScriptable:    false,
+	}
+
+	return info
+}
+
+func (action *SaveAttributeGroupsAction) HandleWebRequest(writer http.ResponseWriter, request *http.Request) {
+	action.SetHttpRequest(request)
+	http2.HandleHttpRequest(writer, request, action)
+}
+
+func (action *SaveAttributeGroupsAction) HeyHo(ctx context.Context, request []byte) (micro.IReply, micro.IRequest) {
+	start := time.Now()
+	defer action.MetricsStore.HandleActionMetric(start, action.GetBaseAction().Environment, action.ProvideInformation(), *action.baseAction.Token)
+	action.startedTime = laniakea.GetCurrentTimeStamp()
+
+	exception := action.checkGroups(ctx, action.saveRequest.UpdatedAttributeGroups)
+	if exception != nil {
+		return structs.NewErrorReplyHeaderWithOrionErr(exception,
+			action.ProvideInformation().ErrorReplyTopic), &action.saveRequest
+	}
+
+	exception = action.saveObjects(ctx, action.saveRequest.UpdatedAttributeGroups, action.saveRequest.Header.Comment, action.saveRequest.Header.User)
+	if exception != nil {
+		logging.GetLogger("SaveAttributeGroupsAction",
+			action.GetBaseAction().Environment,
+			true).WithField("exception:", exception).Error("Data could not be saved")
+		return structs.NewErrorReplyHeaderWithOrionErr(exception,
+			action.ProvideInformation().ErrorReplyTopic), &action.saveRequest
+	}
+
+	reply := structs.NewReplyHeader(action.ProvideInformation().ReplyTopic)
+	reply.Success = true
+
+	return reply, &action.saveRequest
+}
+
+// checkGroups makes sure that the groups only contain existing attribute definitions
+func (action *SaveAttributeGroupsAction) checkGroups(ctx context.Context, groups []structs2.AttributeGroup) *structs.OrionError {
+	definitions, orionErr := getAttributeDefinitionsFromDb(ctx, action.baseAction, bson.M{})
+	if orionErr != nil {
+		return orionErr
+	}
+	if err := attributes.CheckAttributeGroups(groups, definitions); err != nil {
+		return structs.NewOrionError(structs2.ValidationError, err)
+	}
+
+	return nil
+}
+
+func (action *SaveAttributeGroupsAction) archiveAndReplaceObject(ctx context.Context, object structs2.AttributeGroup) error {
+	var objectToArchive structs2.AttributeGroup
+	result, err := mongodb.ReplaceAndFindOneById(ctx, action.baseAction.Environment.MongoDbConnection, "attribute_groups", object.ID.Hex(), object)
+	if err != nil {
+		return err
+	}
+	err = result.Decode(&objectToArchive)
+	if err != nil {
+		return err
+	}
+	objectToArchive.Info.ChangeDate = &action.startedTime
+	objectToArchive.ID = nil
+	_, err = mongodb.InsertOne(context.Background(), action.baseAction.Environment.MongoDbArchiveConnection, "attribute_groups", objectToArchive)
+
+	return err
+}
+
+func (action *SaveAttributeGroupsAction) saveObjects(ctx context.Context, objects []structs2.AttributeGroup, comment, user string) *structs.OrionError {
+	newCtx := context.WithValue(ctx, "objects", objects)
+
+	callback := func(sessCtx mongo.SessionContext) (interface{}, error) {
+		objects := sessCtx.Value("objects").([]structs2.AttributeGroup)
+		for _, object := range objects {
+			if object.Info.CreatedDate == 0 {
+				object.Info.CreatedDate = laniakea.GetCurrentTimeStamp()
+			}
+			if object.ID == nil || object.ID.IsZero() {
+				result, err := mongodb.InsertOne(sessCtx, action.baseAction.Environment.MongoDbConnection, "attribute_groups", object)
+				if err != nil {
+					return nil, err
+				}
+				object.ID = insertedObjectId(result)
+			} else {
+				object.Info.UserComment = &comment
+				object.Info.User = &user
+				object.Info.ChangeDate = &action.startedTime
+
+				err := action.archiveAndReplaceObject(sessCtx, object)
+				if err != nil {
+					return nil, err
+				}
+			}
+			action.savedObjects = append(action.savedObjects, object)
+		}
+
+		return nil, nil
+	}
+	_, err := mongodb.PerformQueriesInTransaction(newCtx, action.baseAction.Environment.MongoDbConnection, callback)
+	if err != nil {
+		return structs.NewOrionError(structs.DatabaseError, fmt.Errorf("error executing queries in transaction: %v", err))
+	}
+
+	return nil
+}
@@ -179,10 +179,11 @@ func (action *SaveCategoriesAction) saveObjects(ctx context.Context, objects []s
 				object.Info.CreatedDate = laniakea.GetCurrentTimeStamp()
 			}
 			if object.ID == nil || object.ID.IsZero() {
-				_, err := mongodb.InsertOne(sessCtx, action.baseAction.Environment.MongoDbConnection, "categories", object)
+				result, err := mongodb.InsertOne(sessCtx, action.baseAction.Environment.MongoDbConnection, "categories", object)
 				if err != nil {
 					return nil, err
 				}
+				object.ID = insertedObjectId(result)
 			} else {
 				object.Info.UserComment = &comment
 				object.Info.User = &user
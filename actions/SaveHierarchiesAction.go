@@ -14,8 +14,10 @@ import (
 	structs2 "github.com/abenstex/orion.commons/structs"
 	"github.com/abenstex/orion.commons/utils"
 	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"net/http"
+	"orion.misc/hierarchy"
 	"orion.misc/structs"
 	"time"
 )
@@ -138,7 +140,20 @@ func (action *SaveHierarchiesAction) HeyHo(ctx context.Context, request []byte)
 			action.ProvideInformation().ErrorReplyTopic), &saveRequest
 	}
 
-	exception := action.saveObjects(ctx, saveRequest.UpdatedHierarchies, saveRequest.Header.Comment, saveRequest.Header.User)
+	validationErrors, exception := action.validateHierarchies(ctx, saveRequest.UpdatedHierarchies)
+	if exception != nil {
+		return structs2.NewErrorReplyHeaderWithOrionErr(exception,
+			action.ProvideInformation().ErrorReplyTopic), &saveRequest
+	}
+	if len(validationErrors) > 0 {
+		reply := structs.SaveHierarchiesErrorReply{ValidationErrors: validationErrors}
+		reply.Header = structs2.NewErrorReplyHeaderWithOrionErr(structs2.NewOrionError(structs.ValidationError,
+			fmt.Errorf("%d hierarchy entries failed the validation", len(validationErrors))), action.ProvideInformation().ErrorReplyTopic)
+
+		return reply, &saveRequest
+	}
+
+	exception = action.saveObjects(ctx, saveRequest.UpdatedHierarchies, saveRequest.Header.Comment, saveRequest.Header.User)
 	if exception != nil {
 		logging.GetLogger(action.ProvideInformation().Name,
 			action.GetBaseAction().Environment,
@@ -153,6 +168,15 @@ func (action *SaveHierarchiesAction) HeyHo(ctx context.Context, request []byte)
 	return reply, &saveRequest
 }
 
+// validateHierarchies checks the entries of the hierarchies and their ordering against the stored hierarchies
+func (action *SaveHierarchiesAction) validateHierarchies(ctx context.Context, hierarchies []structs.Hierarchy) ([]structs.HierarchyValidationError, *structs2.OrionError) {
+	stored, orionErr := getHierarchiesFromDb(ctx, action.baseAction, bson.M{})
+	if orionErr != nil {
+		return nil, orionErr
+	}
+	return hierarchy.Validate(hierarchies, stored, getKnownObjectTypes()), nil
+}
+
 func (action *SaveHierarchiesAction) archiveAndReplaceObject(ctx context.Context, object structs.Hierarchy) error {
 	var objectToArchive structs.Hierarchy
 	result, err := mongodb.ReplaceAndFindOneById(ctx, action.baseAction.Environment.MongoDbConnection, "hierarchies", object.ID.Hex(), object)
@@ -180,10 +204,11 @@ func (action *SaveHierarchiesAction) saveObjects(ctx context.Context, objects []
 				object.Info.CreatedDate = utils2.GetCurrentTimeStamp()
 			}
 			if object.ID == nil || object.ID.IsZero() {
-				_, err := mongodb.InsertOne(sessCtx, action.baseAction.Environment.MongoDbConnection, "hierarchies", object)
+				result, err := mongodb.InsertOne(sessCtx, action.baseAction.Environment.MongoDbConnection, "hierarchies", object)
 				if err != nil {
 					return nil, err
 				}
+				object.ID = insertedObjectId(result)
 			} else {
 				object.Info.UserComment = &comment
 				object.Info.User = &user
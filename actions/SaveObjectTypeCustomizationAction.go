@@ -178,10 +178,11 @@ func (action *SaveObjectTypeCustomizationAction) saveObjects(ctx context.Context
 				object.CreatedDate = laniakea.GetCurrentTimeStamp()
 			}
 			if object.ID == nil || object.ID.IsZero() {
-				_, err := mongodb.InsertOne(sessCtx, action.baseAction.Environment.MongoDbConnection, "object_type_customization", object)
+				result, err := mongodb.InsertOne(sessCtx, action.baseAction.Environment.MongoDbConnection, "object_type_customization", object)
 				if err != nil {
 					return nil, err
 				}
+				object.ID = insertedObjectId(result)
 			} else {
 				object.UserComment = &comment
 				object.User = &user
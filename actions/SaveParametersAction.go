@@ -15,8 +15,10 @@ import (
 	"github.com/abenstex/orion.commons/structs"
 	"github.com/abenstex/orion.commons/utils"
 	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"net/http"
+	"orion.misc/parameters"
 	structs2 "orion.misc/structs"
 	"time"
 )
@@ -140,7 +142,20 @@ func (action *SaveParametersAction) HeyHo(ctx context.Context, request []byte) (
 			action.ProvideInformation().ErrorReplyTopic), &saveRequest
 	}
 
-	exception := action.saveObjects(ctx, saveRequest.Parameters, saveRequest.Header.Comment, saveRequest.Header.User)
+	validationErrors, exception := action.validateParameters(ctx, saveRequest.Parameters)
+	if exception != nil {
+		return structs.NewErrorReplyHeaderWithOrionErr(exception,
+			action.ProvideInformation().ErrorReplyTopic), &saveRequest
+	}
+	if len(validationErrors) > 0 {
+		reply := structs2.SaveParametersErrorReply{ValidationErrors: validationErrors}
+		reply.Header = structs.NewErrorReplyHeaderWithOrionErr(structs.NewOrionError(structs2.ValidationError,
+			fmt.Errorf("%d parameter values failed the validation", len(validationErrors))), action.ProvideInformation().ErrorReplyTopic)
+
+		return reply, &saveRequest
+	}
+
+	exception = action.saveObjects(ctx, saveRequest.Parameters, saveRequest.Header.Comment, saveRequest.Header.User)
 	if exception != nil {
 		//fmt.Printf("Save Users error: %v\n", err)
 		logging.GetLogger("SaveParametersAction",
@@ -156,6 +171,23 @@ func (action *SaveParametersAction) HeyHo(ctx context.Context, request []byte) (
 	return reply, &saveRequest
 }
 
+// validateParameters checks the values of the parameters and makes sure that their scopes don't collide with
+// each other or with the stored values of the same parameters. The scopes are normalized in place, so they are
+// saved in the form the unique index on the scopes expects.
+func (action *SaveParametersAction) validateParameters(ctx context.Context, objects []structs2.Parameter) ([]structs2.ParameterValidationError, *structs.OrionError) {
+	names := make([]string, 0, len(objects))
+	for idx := range objects {
+		objects[idx].Scope = parameters.NormalizeScope(objects[idx].Scope)
+		names = append(names, objects[idx].Info.Name)
+	}
+	stored, orionErr := getParametersFromDb(ctx, action.baseAction, bson.M{"info.name": bson.M{"$in": names}})
+	if orionErr != nil {
+		return nil, orionErr
+	}
+
+	return parameters.CheckOverrides(objects, stored), nil
+}
+
 func (action *SaveParametersAction) archiveAndReplaceObject(ctx context.Context, object structs2.Parameter) error {
 	var objectToArchive structs2.Parameter
 	result, err := mongodb.ReplaceAndFindOneById(ctx, action.baseAction.Environment.MongoDbConnection, "parameters", object.ID.Hex(), object)
@@ -179,14 +211,16 @@ func (action *SaveParametersAction) saveObjects(ctx context.Context, objects []s
 	callback := func(sessCtx mongo.SessionContext) (interface{}, error) {
 		objects := sessCtx.Value("objects").([]structs2.Parameter)
 		for _, object := range objects {
+			object.Type = parameters.NormalizeType(object.Type)
 			if object.Info.CreatedDate == 0 {
 				object.Info.CreatedDate = laniakea.GetCurrentTimeStamp()
 			}
 			if object.ID == nil || object.ID.IsZero() {
-				_, err := mongodb.InsertOne(sessCtx, action.baseAction.Environment.MongoDbConnection, "parameters", object)
+				result, err := mongodb.InsertOne(sessCtx, action.baseAction.Environment.MongoDbConnection, "parameters", object)
 				if err != nil {
 					return nil, err
 				}
+				object.ID = insertedObjectId(result)
 			} else {
 				object.Info.UserComment = &comment
 				object.Info.User = &user
@@ -15,9 +15,12 @@ import (
 	structs2 "github.com/abenstex/orion.commons/structs"
 	"github.com/abenstex/orion.commons/utils"
 	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"net/http"
+	"orion.misc/statemachine"
 	"orion.misc/structs"
+	"strings"
 	"time"
 )
 
@@ -138,6 +141,17 @@ func (action *SaveStateTransitionRulesAction) HeyHo(ctx context.Context, request
 			action.ProvideInformation().ErrorReplyTopic), &saveRequest
 	}
 
+	if saveRequest.RejectNewFindings {
+		orionErr := action.checkStateMachines(ctx, saveRequest.UpdatedStateTransitionRules)
+		if orionErr != nil {
+			logging.GetLogger(action.ProvideInformation().Name,
+				action.GetBaseAction().Environment,
+				true).WithError(orionErr.Error).Warn("Saving the rules would introduce new state machine findings")
+			return structs2.NewErrorReplyHeaderWithOrionErr(orionErr,
+				action.ProvideInformation().ErrorReplyTopic), &saveRequest
+		}
+	}
+
 	orionErr := action.saveObjects(ctx, saveRequest.UpdatedStateTransitionRules, saveRequest.Header.Comment, saveRequest.Header.User)
 	if orionErr != nil {
 		logging.GetLogger(action.ProvideInformation().Name,
@@ -153,6 +167,34 @@ func (action *SaveStateTransitionRulesAction) HeyHo(ctx context.Context, request
 	return reply, &saveRequest
 }
 
+func (action *SaveStateTransitionRulesAction) checkStateMachines(ctx context.Context, updatedRules []structs.StateTransitionRule) *structs2.OrionError {
+	states, orionErr := getStateDefinitionsFromDb(ctx, action.baseAction, bson.M{})
+	if orionErr != nil {
+		return orionErr
+	}
+	storedRules, orionErr := getAllStateTransitionRulesFromDb(ctx, action.baseAction)
+	if orionErr != nil {
+		return orionErr
+	}
+
+	analysesBefore := make(map[string]structs.StateMachineAnalysis)
+	for _, definition := range statemachine.NewDefinitions(states, storedRules) {
+		analysesBefore[definition.ReferencedType] = definition.Analyze()
+	}
+	messages := make([]string, 0)
+	for _, definition := range statemachine.NewDefinitions(states, statemachine.MergeRules(storedRules, updatedRules)) {
+		for _, finding := range statemachine.NewFindings(analysesBefore[definition.ReferencedType], definition.Analyze()) {
+			messages = append(messages, fmt.Sprintf("%v (%v): %v", finding.Code, finding.ReferencedType, finding.Message))
+		}
+	}
+	if len(messages) > 0 {
+		return structs2.NewOrionError(structs.ValidationError,
+			fmt.Errorf("the rules were not saved because they would introduce new problems: %v", strings.Join(messages, "; ")))
+	}
+
+	return nil
+}
+
 func (action *SaveStateTransitionRulesAction) archiveAndReplaceObject(ctx context.Context, object structs.StateTransitionRule) error {
 	var objectToArchive structs.StateTransitionRule
 	result, err := mongodb.ReplaceAndFindOneById(ctx, action.baseAction.Environment.MongoDbConnection, "state_transition_rules", object.ID.Hex(), object)
@@ -185,10 +227,11 @@ func (action *SaveStateTransitionRulesAction) saveObjects(ctx context.Context, o
 				object.Info.ObjectType = &objType
 			}
 			if object.ID == nil || object.ID.IsZero() {
-				_, err := mongodb.InsertOne(sessCtx, action.baseAction.Environment.MongoDbConnection, "state_transition_rules", object)
+				result, err := mongodb.InsertOne(sessCtx, action.baseAction.Environment.MongoDbConnection, "state_transition_rules", object)
 				if err != nil {
 					return nil, err
 				}
+				object.ID = insertedObjectId(result)
 			} else {
 				object.Info.ChangeDate = &action.startedTime
 
@@ -182,10 +182,11 @@ func (action *SaveStatesAction) saveObjects(ctx context.Context, objects []struc
 				object.Info.CreatedDate = utils2.GetCurrentTimeStamp()
 			}
 			if object.ID == nil || object.ID.IsZero() {
-				_, err := mongodb.InsertOne(sessCtx, action.baseAction.Environment.MongoDbConnection, "states", object)
+				result, err := mongodb.InsertOne(sessCtx, action.baseAction.Environment.MongoDbConnection, "states", object)
 				if err != nil {
 					return nil, err
 				}
+				object.ID = insertedObjectId(result)
 			} else {
 
 				object.Info.ChangeDate = &action.startedTime
@@ -0,0 +1,206 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/abenstex/laniakea/dataStructures"
+	"github.com/abenstex/laniakea/logging"
+	"github.com/abenstex/laniakea/micro"
+	"github.com/abenstex/laniakea/mongodb"
+	"github.com/abenstex/laniakea/mqtt"
+	utils2 "github.com/abenstex/laniakea/utils"
+	"github.com/abenstex/orion.commons/app"
+	http2 "github.com/abenstex/orion.commons/http"
+	structs2 "github.com/abenstex/orion.commons/structs"
+	"github.com/abenstex/orion.commons/utils"
+	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"net/http"
+	"orion.misc/structs"
+	"strings"
+	"time"
+)
+
+// SaveUserRolesAction maintains the roles of users in the user_roles collection, USER_ROLE guards of state
+// transition rules are checked against them
+type SaveUserRolesAction struct {
+	baseAction   micro.BaseAction
+	MetricsStore *utils.MetricsStore
+	savedObjects []structs.UserRoles
+	saveRequest  structs.SaveUserRolesRequest
+}
+
+func (action *SaveUserRolesAction) BeforeAction(ctx context.Context, request []byte) *micro.Exception {
+	dummy := structs.SaveUserRolesRequest{}
+	err := json.Unmarshal(request, &dummy)
+	if err != nil {
+		return micro.NewException(structs2.UnmarshalError, err)
+	}
+	err = app.DefaultHandleActionRequest(request, &dummy.Header, action, true)
+	if err != nil {
+		return micro.NewException(structs2.RequestHeaderInvalid, err)
+	}
+
+	return nil
+}
+
+func (action SaveUserRolesAction) BeforeActionAsync(ctx context.Context, request []byte) {
+
+}
+
+func (action SaveUserRolesAction) AfterAction(ctx context.Context, reply *micro.IReply, request *micro.IRequest) *micro.Exception {
+	return nil
+}
+
+func (action SaveUserRolesAction) AfterActionAsync(ctx context.Context, reply micro.IReply, request micro.IRequest) {
+
+}
+
+func (action SaveUserRolesAction) GetBaseAction() micro.BaseAction {
+	return action.baseAction
+}
+
+func (action *SaveUserRolesAction) SetHttpRequest(request *http.Request) {
+	action.baseAction.Request = request
+}
+
+func (action *SaveUserRolesAction) InitBaseAction(baseAction micro.BaseAction) {
+	action.baseAction = baseAction
+}
+
+func (action SaveUserRolesAction) SendEvents(request micro.IRequest) {
+	saveRequest := request.(*structs.SaveUserRolesRequest)
+	if !saveRequest.Header.WasExecutedSuccessfully {
+		logging.GetLogger("SaveUserRolesAction",
+			action.GetBaseAction().Environment,
+			true).Warn("RequestFailedEvent will be sent because the request was not successfully executed")
+		blerghEvent := structs2.NewRequestFailedEvent(saveRequest, action.ProvideInformation(), action.baseAction.ID.String(), "")
+		blerghEvent.Send(action.ProvideInformation().ErrorReplyTopic, byte(viper.GetInt("messageBus.publishEventQos")),
+			utils.GetDefaultMqttConnectionOptionsWithIdPrefix(action.ProvideInformation().Name))
+		return
+	}
+
+	event := structs.UserRolesSavedEvent{
+		Header:    *micro.NewEventHeaderForAction(action.ProvideInformation(), saveRequest.Header.SenderId, ""),
+		UserRoles: action.savedObjects,
+	}
+
+	json, err := event.ToJsonString()
+	if err != nil {
+		logging.GetLogger("SaveUserRolesAction", action.GetBaseAction().Environment, true).WithError(err).Error("Could not send events")
+
+		return
+	}
+	mqtt.Publish(action.ProvideInformation().EventTopic, json, byte(viper.GetInt("messageBus.publishEventQos")),
+		utils.GetDefaultMqttConnectionOptionsWithIdPrefix(action.ProvideInformation().Name))
+}
+
+func (action SaveUserRolesAction) ProvideInformation() micro.ActionInformation {
+	var reply = "orion/server/misc/reply/user/roles/save"
+	var error = "orion/server/misc/error/user/roles/save"
+	var event = "orion/server/misc/event/user/roles/save"
+	var requestSample = dataStructures.StructToJsonString(structs.SaveUserRolesRequest{})
+	var replySample = dataStructures.StructToJsonString(micro.ReplyHeader{})
+	var eventSample = dataStructures.StructToJsonString(structs.UserRolesSavedEvent{})
+	info := micro.ActionInformation{
+		Name:            "SaveUserRolesAction",
+		Description:     "Replaces the roles of users that USER_ROLE guards of state transition rules are checked against",
+		RequestTopic:    "orion/server/misc/request/user/roles/save",
+		ReplyTopic:      reply,
+		ErrorReplyTopic: error,
+		Version:         1,
+		ClientId:        action.baseAction.ID.String(),
+		HttpMethods:     []string{http.MethodPost, "OPTIONS"},
+		EventTopic:      event,
+		RequestSample:   &requestSample,
+		ReplySample:     &replySample,
+		EventSample:     &eventSample,
+		IsScriptable:    false,
+	}
+
+	return info
+}
+
+func (action *SaveUserRolesAction) HandleWebRequest(writer http.ResponseWriter, request *http.Request) {
+	action.SetHttpRequest(request)
+	http2.HandleHttpRequest(writer, request, action)
+}
+
+func (action *SaveUserRolesAction) HeyHo(ctx context.Context, request []byte) (micro.IReply, micro.IRequest) {
+	start := time.Now()
+	defer action.MetricsStore.HandleActionMetric(start, action.GetBaseAction().Environment, action.ProvideInformation(), *action.baseAction.Token)
+	action.savedObjects = nil
+
+	err := json.Unmarshal(request, &action.saveRequest)
+	if err != nil {
+		return structs2.NewErrorReplyHeaderWithException(micro.NewException(structs2.UnmarshalError, err),
+			action.ProvideInformation().ErrorReplyTopic), &action.saveRequest
+	}
+
+	exception := action.saveObjects(ctx, action.saveRequest.UserRoles, action.saveRequest.Header.Comment, action.saveRequest.Header.User)
+	if exception != nil {
+		logging.GetLogger("SaveUserRolesAction",
+			action.GetBaseAction().Environment,
+			true).WithField("exception:", exception).Error("Data could not be saved")
+		return structs2.NewErrorReplyHeaderWithOrionErr(exception,
+			action.ProvideInformation().ErrorReplyTopic), &action.saveRequest
+	}
+
+	reply := structs2.NewReplyHeader(action.ProvideInformation().ReplyTopic)
+	reply.Success = true
+
+	return reply, &action.saveRequest
+}
+
+func (action *SaveUserRolesAction) saveObjects(ctx context.Context, objects []structs.UserRoles, comment, user string) *structs2.OrionError {
+	for _, object := range objects {
+		if len(strings.TrimSpace(object.User)) == 0 {
+			return structs2.NewOrionError(structs2.MissingParameterError, fmt.Errorf("the user of a list of roles was not provided"))
+		}
+	}
+
+	replaced := make([]structs.UserRoles, 0, len(objects))
+	callback := func(sessCtx mongo.SessionContext) (interface{}, error) {
+		// the callback runs again if the transaction is retried
+		action.savedObjects = action.savedObjects[:0]
+		replaced = replaced[:0]
+		now := utils2.GetCurrentTimeStamp()
+		for _, object := range objects {
+			object.ChangeDate = &now
+			object.ChangedBy = &user
+			object.UserComment = &comment
+			if object.Roles == nil {
+				object.Roles = []string{}
+			}
+			var original structs.UserRoles
+			err := action.baseAction.Environment.MongoDbConnection.Database().Collection("user_roles").
+				FindOneAndReplace(sessCtx, bson.M{"user": object.User}, object, options.FindOneAndReplace().SetUpsert(true)).Decode(&original)
+			if err != nil && err != mongo.ErrNoDocuments {
+				return nil, err
+			}
+			if err == nil {
+				replaced = append(replaced, original)
+			}
+			action.savedObjects = append(action.savedObjects, object)
+		}
+
+		return nil, nil
+	}
+	_, err := mongodb.PerformQueriesInTransaction(ctx, action.baseAction.Environment.MongoDbConnection, callback)
+	if err != nil {
+		return structs2.NewOrionError(structs2.DatabaseError, fmt.Errorf("error executing queries in transaction: %v", err))
+	}
+	// the archive is a different database, it is only written once the roles are committed
+	for _, original := range replaced {
+		_, err = mongodb.InsertOne(ctx, action.baseAction.Environment.MongoDbArchiveConnection, "user_roles", original)
+		if err != nil {
+			logging.GetLogger("SaveUserRolesAction", action.GetBaseAction().Environment, true).WithError(err).
+				Error("Could not archive the replaced roles of a user")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,234 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/abenstex/laniakea/dataStructures"
+	"github.com/abenstex/laniakea/micro"
+	utils2 "github.com/abenstex/laniakea/utils"
+	"github.com/abenstex/orion.commons/app"
+	http2 "github.com/abenstex/orion.commons/http"
+	structs2 "github.com/abenstex/orion.commons/structs"
+	"github.com/abenstex/orion.commons/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"net/http"
+	"orion.misc/attributes"
+	"orion.misc/structs"
+	"time"
+)
+
+const (
+	defaultSearchPageSize = 50
+	maxSearchPageSize     = 1000
+)
+
+type SearchObjectsByAttributesAction struct {
+	baseAction      micro.BaseAction
+	MetricsStore    *utils.MetricsStore
+	receivedRequest structs.SearchObjectsByAttributesRequest
+}
+
+func (action *SearchObjectsByAttributesAction) BeforeAction(ctx context.Context, request []byte) *micro.Exception {
+	dummy := structs.SearchObjectsByAttributesRequest{}
+	err := json.Unmarshal(request, &dummy)
+	if err != nil {
+		return micro.NewException(structs2.UnmarshalError, err)
+	}
+	err = app.DefaultHandleActionRequest(request, &dummy.Header, action, true)
+	if err != nil {
+		return micro.NewException(structs2.RequestHeaderInvalid, err)
+	}
+	if len(dummy.ObjectType) == 0 {
+		return micro.NewException(structs2.MissingParameterError, fmt.Errorf("the parameter object_type was not provided"))
+	}
+
+	return nil
+}
+
+func (action SearchObjectsByAttributesAction) BeforeActionAsync(ctx context.Context, request []byte) {
+
+}
+
+func (action SearchObjectsByAttributesAction) AfterAction(ctx context.Context, reply *micro.IReply, request *micro.IRequest) *micro.Exception {
+	return nil
+}
+
+func (action SearchObjectsByAttributesAction) AfterActionAsync(ctx context.Context, reply micro.IReply, request micro.IRequest) {
+
+}
+
+func (action SearchObjectsByAttributesAction) GetBaseAction() micro.BaseAction {
+	return action.baseAction
+}
+
+func (action *SearchObjectsByAttributesAction) SetHttpRequest(request *http.Request) {
+	action.baseAction.Request = request
+}
+
+func (action *SearchObjectsByAttributesAction) InitBaseAction(baseAction micro.BaseAction) {
+	action.baseAction = baseAction
+}
+
+func (action SearchObjectsByAttributesAction) SendEvents(request micro.IRequest) {
+
+}
+
+func (action SearchObjectsByAttributesAction) ProvideInformation() micro.ActionInformation {
+	var reply = "orion/server/misc/reply/attribute/search"
+	var error = "orion/server/misc/error/attribute/search"
+	var requestSample = dataStructures.StructToJsonString(structs.SearchObjectsByAttributesRequest{})
+	var replySample = dataStructures.StructToJsonString(structs.SearchObjectsByAttributesReply{})
+	info := micro.ActionInformation{
+		Name:            "SearchObjectsByAttributesAction",
+		Description:     "Searches the objects of a type by the values of their attributes",
+		RequestTopic:    "orion/server/misc/request/attribute/search",
+		ReplyTopic:      reply,
+		ErrorReplyTopic: error,
+		Version:         1,
+		ClientId:        action.baseAction.ID.String(),
+		HttpMethods:     []string{http.MethodPost, "OPTIONS"},
+		RequestSample:   &requestSample,
+		ReplySample:     &replySample,
+		IsScriptable:    false,
+	}
+
+	return info
+}
+
+func (action *SearchObjectsByAttributesAction) HandleWebRequest(writer http.ResponseWriter, request *http.Request) {
+	action.SetHttpRequest(request)
+	http2.HandleHttpRequest(writer, request, action)
+}
+
+func (action *SearchObjectsByAttributesAction) HeyHo(ctx context.Context, request []byte) (micro.IReply, micro.IRequest) {
+	start := time.Now()
+	defer action.MetricsStore.HandleActionMetric(start, action.GetBaseAction().Environment, action.ProvideInformation(), *action.baseAction.Token)
+
+	err := json.Unmarshal(request, &action.receivedRequest)
+	if err != nil {
+		return structs2.NewErrorReplyHeaderWithException(micro.NewException(structs2.UnmarshalError, err),
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	reply, myErr := action.search(ctx, action.receivedRequest)
+	if myErr != nil {
+		return structs2.NewErrorReplyHeaderWithOrionErr(myErr,
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	return reply, &action.receivedRequest
+}
+
+func (action SearchObjectsByAttributesAction) search(ctx context.Context, request structs.SearchObjectsByAttributesRequest) (structs.SearchObjectsByAttributesReply, *structs2.OrionError) {
+	definitions, myErr := getAttributeDefinitionsFromDb(ctx, action.baseAction, bson.M{})
+	if myErr != nil {
+		return structs.SearchObjectsByAttributesReply{}, myErr
+	}
+	definitionsById := make(map[string]structs.AttributeDefinition, len(definitions))
+	for _, definition := range definitions {
+		definitionsById[definition.ID.Hex()] = definition
+	}
+
+	attributeIds := make(map[string]bool)
+	condition, myErr := action.conditionFilter(request.Condition, definitionsById, attributeIds)
+	if myErr != nil {
+		return structs.SearchObjectsByAttributesReply{}, myErr
+	}
+
+	var reply = structs.SearchObjectsByAttributesReply{}
+	reply.Header = structs2.NewReplyHeader(action.ProvideInformation().ReplyTopic)
+	reply.Header.Timestamp = utils2.GetCurrentTimeStamp()
+	reply.Page = request.Page
+	if reply.Page <= 0 {
+		reply.Page = 1
+	}
+	reply.PageSize = request.PageSize
+	if reply.PageSize <= 0 || reply.PageSize > maxSearchPageSize {
+		reply.PageSize = defaultSearchPageSize
+	}
+	reply.Objects = make([]structs.AttributeSearchResult, 0)
+
+	ids := make(bson.A, 0, len(attributeIds))
+	for attributeId := range attributeIds {
+		ids = append(ids, attributeId)
+	}
+	// The values of the searched attributes are grouped by object, so that every predicate can be matched against
+	// the values of an object and the whole condition is evaluated and paged by the database
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"object_type": request.ObjectType, "attribute_id": bson.M{"$in": ids}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$object_id", "attribute_values": bson.M{"$push": "$$ROOT"}}}},
+		{{Key: "$match", Value: condition}},
+		{{Key: "$sort", Value: bson.M{"_id": 1}}},
+		{{Key: "$facet", Value: bson.M{
+			"total":   bson.A{bson.M{"$count": "count"}},
+			"objects": bson.A{bson.M{"$skip": (reply.Page - 1) * reply.PageSize}, bson.M{"$limit": reply.PageSize}},
+		}}},
+	}
+	cursor, err := action.baseAction.Environment.MongoDbConnection.Database().Collection("attribute_values").
+		Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return structs.SearchObjectsByAttributesReply{}, structs2.NewOrionError(structs2.DatabaseError, err)
+	}
+	var results []struct {
+		Total []struct {
+			Count int `bson:"count"`
+		} `bson:"total"`
+		Objects []struct {
+			ObjectId string                   `bson:"_id"`
+			Values   []structs.AttributeValue `bson:"attribute_values"`
+		} `bson:"objects"`
+	}
+	if err = cursor.All(ctx, &results); err != nil {
+		return structs.SearchObjectsByAttributesReply{}, structs2.NewOrionError(structs2.DatabaseError, err)
+	}
+	if len(results) > 0 {
+		if len(results[0].Total) > 0 {
+			reply.TotalCount = results[0].Total[0].Count
+		}
+		for _, object := range results[0].Objects {
+			reply.Objects = append(reply.Objects, structs.AttributeSearchResult{ObjectId: object.ObjectId, Values: object.Values})
+		}
+	}
+	reply.Header.Success = true
+
+	return reply, nil
+}
+
+// conditionFilter translates a condition into a filter on the values of an object grouped in attribute_values
+// and collects the attributes it uses
+func (action SearchObjectsByAttributesAction) conditionFilter(condition structs.AttributeSearchCondition,
+	definitions map[string]structs.AttributeDefinition, attributeIds map[string]bool) (bson.M, *structs2.OrionError) {
+	filters := make(bson.A, 0, len(condition.Predicates)+len(condition.Conditions))
+	for _, predicate := range condition.Predicates {
+		definition, ok := definitions[predicate.AttributeId]
+		if !ok {
+			return nil, structs2.NewOrionError(structs2.NoDataFound, fmt.Errorf("attribute definition %v does not exist", predicate.AttributeId))
+		}
+		filter, err := attributes.PredicateFilter(definition, predicate)
+		if err != nil {
+			return nil, structs2.NewOrionError(structs.ValidationError, err)
+		}
+		filters = append(filters, bson.M{"attribute_values": bson.M{"$elemMatch": filter}})
+		attributeIds[predicate.AttributeId] = true
+	}
+	for _, nested := range condition.Conditions {
+		filter, myErr := action.conditionFilter(nested, definitions, attributeIds)
+		if myErr != nil {
+			return nil, myErr
+		}
+		filters = append(filters, filter)
+	}
+	if len(filters) == 0 {
+		return nil, structs2.NewOrionError(structs2.MissingParameterError, fmt.Errorf("the condition has neither predicates nor nested conditions"))
+	}
+
+	operator, err := attributes.CombinatorOperator(condition.Combinator)
+	if err != nil {
+		return nil, structs2.NewOrionError(structs.ValidationError, err)
+	}
+
+	return bson.M{operator: filters}, nil
+}
@@ -0,0 +1,168 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/abenstex/laniakea/dataStructures"
+	"github.com/abenstex/laniakea/logging"
+	"github.com/abenstex/laniakea/micro"
+	utils2 "github.com/abenstex/laniakea/utils"
+	"github.com/abenstex/orion.commons/app"
+	http2 "github.com/abenstex/orion.commons/http"
+	structs2 "github.com/abenstex/orion.commons/structs"
+	"github.com/abenstex/orion.commons/utils"
+	"github.com/spf13/viper"
+	"net/http"
+	"orion.misc/structs"
+	"time"
+)
+
+type SetAttributeValueAction struct {
+	baseAction      micro.BaseAction
+	MetricsStore    *utils.MetricsStore
+	receivedRequest structs.SetAttributeValueRequest
+	changes         []structs.AttributeChange
+}
+
+func (action *SetAttributeValueAction) BeforeAction(ctx context.Context, request []byte) *micro.Exception {
+	dummy := structs.SetAttributeValueRequest{}
+	err := json.Unmarshal(request, &dummy)
+	if err != nil {
+		return micro.NewException(structs2.UnmarshalError, err)
+	}
+	err = app.DefaultHandleActionRequest(request, &dummy.Header, action, true)
+	if err != nil {
+		return micro.NewException(structs2.RequestHeaderInvalid, err)
+	}
+	for _, value := range dummy.Attributes {
+		if len(value.AttributeId) == 0 || len(value.ObjectType) == 0 || len(value.ObjectId) == 0 {
+			return micro.NewException(structs2.MissingParameterError,
+				fmt.Errorf("not all parameters (attribute_id, object_type and object_id) were provided for every attribute"))
+		}
+	}
+
+	return nil
+}
+
+func (action SetAttributeValueAction) BeforeActionAsync(ctx context.Context, request []byte) {
+
+}
+
+func (action SetAttributeValueAction) AfterAction(ctx context.Context, reply *micro.IReply, request *micro.IRequest) *micro.Exception {
+	return nil
+}
+
+func (action SetAttributeValueAction) AfterActionAsync(ctx context.Context, reply micro.IReply, request micro.IRequest) {
+
+}
+
+func (action SetAttributeValueAction) GetBaseAction() micro.BaseAction {
+	return action.baseAction
+}
+
+func (action *SetAttributeValueAction) SetHttpRequest(request *http.Request) {
+	action.baseAction.Request = request
+}
+
+func (action *SetAttributeValueAction) InitBaseAction(baseAction micro.BaseAction) {
+	action.baseAction = baseAction
+}
+
+func (action SetAttributeValueAction) SendEvents(request micro.IRequest) {
+	saveRequest := request.(*structs.SetAttributeValueRequest)
+	if !saveRequest.Header.WasExecutedSuccessfully {
+		logging.GetLogger("SetAttributeValueAction",
+			action.GetBaseAction().Environment,
+			true).Warn("RequestFailedEvent will be sent because the request was not successfully executed")
+		blerghEvent := structs2.NewRequestFailedEvent(saveRequest, action.ProvideInformation(), action.baseAction.ID.String(), "")
+		blerghEvent.Send(action.ProvideInformation().ErrorReplyTopic, byte(viper.GetInt("messageBus.publishEventQos")),
+			utils.GetDefaultMqttConnectionOptionsWithIdPrefix(action.ProvideInformation().Name))
+		return
+	}
+	if len(action.changes) == 0 {
+		return
+	}
+
+	sendAttributeValueChangedEvent(action.ProvideInformation(), saveRequest.Header.SenderId, action.baseAction.Environment, action.changes)
+}
+
+func (action SetAttributeValueAction) ProvideInformation() micro.ActionInformation {
+	var reply = "orion/server/misc/reply/attribute/value/save"
+	var error = "orion/server/misc/error/attribute/value/save"
+	var requestSample = dataStructures.StructToJsonString(structs.SetAttributeValueRequest{})
+	var replySample = dataStructures.StructToJsonString(structs.SetAttributeValueReply{})
+	var eventSample = dataStructures.StructToJsonString(structs.AttributeValueChangedEvent{})
+	info := micro.ActionInformation{
+		Name:            "SetAttributeValueAction",
+		Description:     "Sets the values of attributes on objects",
+		RequestTopic:    "orion/server/misc/request/attribute/value/save",
+		ReplyTopic:      reply,
+		ErrorReplyTopic: error,
+		Version:         1,
+		ClientId:        action.baseAction.ID.String(),
+		HttpMethods:     []string{http.MethodPost, "OPTIONS"},
+		EventTopic:      AttributeValueChangedEventTopic,
+		RequestSample:   &requestSample,
+		ReplySample:     &replySample,
+		EventSample:     &eventSample,
+		IsScriptable:    true,
+	}
+
+	return info
+}
+
+func (action *SetAttributeValueAction) HandleWebRequest(writer http.ResponseWriter, request *http.Request) {
+	action.SetHttpRequest(request)
+	http2.HandleHttpRequest(writer, request, action)
+}
+
+func (action *SetAttributeValueAction) HeyHo(ctx context.Context, request []byte) (micro.IReply, micro.IRequest) {
+	start := time.Now()
+	defer action.MetricsStore.HandleActionMetric(start, action.GetBaseAction().Environment, action.ProvideInformation(), *action.baseAction.Token)
+	action.changes = nil
+
+	err := json.Unmarshal(request, &action.receivedRequest)
+	if err != nil {
+		return structs2.NewErrorReplyHeaderWithException(micro.NewException(structs2.UnmarshalError, err),
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	reply, myErr := action.setAttributeValues(ctx, action.receivedRequest)
+	if myErr != nil {
+		logging.GetLogger("SetAttributeValueAction",
+			action.GetBaseAction().Environment,
+			true).WithError(myErr.Error).Error("Data could not be saved")
+		return structs2.NewErrorReplyHeaderWithOrionErr(myErr,
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	return reply, &action.receivedRequest
+}
+
+func (action *SetAttributeValueAction) setAttributeValues(ctx context.Context, request structs.SetAttributeValueRequest) (structs.SetAttributeValueReply, *structs2.OrionError) {
+	var reply = structs.SetAttributeValueReply{}
+	validationErrors, myErr := validateAttributeValues(ctx, action.baseAction, request.Attributes)
+	if myErr != nil {
+		return reply, myErr
+	}
+	if len(validationErrors) > 0 {
+		reply.Header = structs2.NewErrorReplyHeaderWithOrionErr(structs2.NewOrionError(structs.ValidationError,
+			fmt.Errorf("%d attribute value(s) failed the validation", len(validationErrors))), action.ProvideInformation().ErrorReplyTopic)
+		reply.ValidationErrors = validationErrors
+
+		return reply, nil
+	}
+
+	changes, myErr := saveAttributeValues(ctx, action.baseAction, request.Attributes, request.Header.User, request.Header.Comment, false)
+	if myErr != nil {
+		return reply, myErr
+	}
+	action.changes = changes
+
+	reply.Header = structs2.NewReplyHeader(action.ProvideInformation().ReplyTopic)
+	reply.Header.Timestamp = utils2.GetCurrentTimeStamp()
+	reply.Header.Success = true
+
+	return reply, nil
+}
@@ -0,0 +1,168 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/abenstex/laniakea/dataStructures"
+	"github.com/abenstex/laniakea/micro"
+	utils2 "github.com/abenstex/laniakea/utils"
+	"github.com/abenstex/orion.commons/app"
+	http2 "github.com/abenstex/orion.commons/http"
+	structs2 "github.com/abenstex/orion.commons/structs"
+	"github.com/abenstex/orion.commons/utils"
+	"net/http"
+	"orion.misc/statemachine"
+	"orion.misc/structs"
+	"time"
+)
+
+type SimulateStateTransitionsAction struct {
+	baseAction      micro.BaseAction
+	MetricsStore    *utils.MetricsStore
+	receivedRequest structs.SimulateStateTransitionsRequest
+}
+
+func (action *SimulateStateTransitionsAction) BeforeAction(ctx context.Context, request []byte) *micro.Exception {
+	dummy := structs.SimulateStateTransitionsRequest{}
+	err := json.Unmarshal(request, &dummy)
+	if err != nil {
+		return micro.NewException(structs2.UnmarshalError, err)
+	}
+	err = app.DefaultHandleActionRequest(request, &dummy.Header, action, true)
+	if err != nil {
+		return micro.NewException(structs2.RequestHeaderInvalid, err)
+	}
+	if len(dummy.ReferencedType) == 0 || len(dummy.Paths) == 0 {
+		return micro.NewException(structs2.MissingParameterError,
+			fmt.Errorf("not all parameters (referenced_type and paths) were provided"))
+	}
+
+	return nil
+}
+
+func (action SimulateStateTransitionsAction) BeforeActionAsync(ctx context.Context, request []byte) {
+
+}
+
+func (action SimulateStateTransitionsAction) AfterAction(ctx context.Context, reply *micro.IReply, request *micro.IRequest) *micro.Exception {
+	return nil
+}
+
+func (action SimulateStateTransitionsAction) AfterActionAsync(ctx context.Context, reply micro.IReply, request micro.IRequest) {
+
+}
+
+func (action SimulateStateTransitionsAction) GetBaseAction() micro.BaseAction {
+	return action.baseAction
+}
+
+func (action *SimulateStateTransitionsAction) SetHttpRequest(request *http.Request) {
+	action.baseAction.Request = request
+}
+
+func (action *SimulateStateTransitionsAction) InitBaseAction(baseAction micro.BaseAction) {
+	action.baseAction = baseAction
+}
+
+func (action SimulateStateTransitionsAction) SendEvents(request micro.IRequest) {
+
+}
+
+func (action SimulateStateTransitionsAction) ProvideInformation() micro.ActionInformation {
+	var reply = "orion/server/misc/reply/state/transition/simulate"
+	var error = "orion/server/misc/error/state/transition/simulate"
+	var requestSample = dataStructures.StructToJsonString(structs.SimulateStateTransitionsRequest{})
+	var replySample = dataStructures.StructToJsonString(structs.SimulateStateTransitionsReply{})
+	info := micro.ActionInformation{
+		Name:            "SimulateStateTransitionsAction",
+		Description:     "Replays state sequences against the current or a draft set of state transition rules without changing anything",
+		RequestTopic:    "orion/server/misc/request/state/transition/simulate",
+		ReplyTopic:      reply,
+		ErrorReplyTopic: error,
+		Version:         1,
+		ClientId:        action.baseAction.ID.String(),
+		HttpMethods:     []string{http.MethodPost, "OPTIONS"},
+		RequestSample:   &requestSample,
+		ReplySample:     &replySample,
+		IsScriptable:    false,
+	}
+
+	return info
+}
+
+func (action *SimulateStateTransitionsAction) HandleWebRequest(writer http.ResponseWriter, request *http.Request) {
+	action.SetHttpRequest(request)
+	http2.HandleHttpRequest(writer, request, action)
+}
+
+func (action *SimulateStateTransitionsAction) HeyHo(ctx context.Context, request []byte) (micro.IReply, micro.IRequest) {
+	start := time.Now()
+	defer action.MetricsStore.HandleActionMetric(start, action.GetBaseAction().Environment, action.ProvideInformation(), *action.baseAction.Token)
+
+	err := json.Unmarshal(request, &action.receivedRequest)
+	if err != nil {
+		return structs2.NewErrorReplyHeaderWithException(micro.NewException(structs2.UnmarshalError, err),
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	reply, myErr := action.simulate(ctx, action.receivedRequest)
+	if myErr != nil {
+		return structs2.NewErrorReplyHeaderWithOrionErr(myErr,
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	return reply, &action.receivedRequest
+}
+
+func (action SimulateStateTransitionsAction) simulate(ctx context.Context, request structs.SimulateStateTransitionsRequest) (structs.SimulateStateTransitionsReply, *structs2.OrionError) {
+	definition, myErr := action.loadDefinition(ctx, request)
+	if myErr != nil {
+		return structs.SimulateStateTransitionsReply{}, myErr
+	}
+
+	userRoles, myErr := getUserRoles(ctx, action.baseAction, request.Header.User)
+	if myErr != nil {
+		return structs.SimulateStateTransitionsReply{}, myErr
+	}
+	results := definition.Simulate(request.Paths, request.Header.User, userRoles, request.ValidateInitialState)
+
+	var reply = structs.SimulateStateTransitionsReply{}
+	reply.Header = structs2.NewReplyHeader(action.ProvideInformation().ReplyTopic)
+	reply.Header.Timestamp = utils2.GetCurrentTimeStamp()
+	reply.Header.Success = true
+	reply.StateMachineVersion = definition.Version
+	reply.Results = results
+	for _, result := range results {
+		if result.Accepted {
+			reply.AcceptedPaths++
+		} else {
+			reply.RejectedPaths++
+		}
+	}
+
+	return reply, nil
+}
+
+// loadDefinition returns the state machine that production uses for the object if no draft is given, draft rules
+// are applied to the live rules
+func (action SimulateStateTransitionsAction) loadDefinition(ctx context.Context, request structs.SimulateStateTransitionsRequest) (statemachine.Definition, *structs2.OrionError) {
+	if !request.DraftReplacesRules && len(request.DraftRules) == 0 {
+		var createdDate int64
+		if request.ObjectCreatedDate != nil {
+			createdDate = *request.ObjectCreatedDate
+		}
+
+		return resolveStateMachineDefinition(ctx, action.baseAction, request.ReferencedType, createdDate)
+	}
+
+	definition, myErr := loadStateMachineDefinition(ctx, action.baseAction, request.ReferencedType)
+	if myErr != nil {
+		return statemachine.Definition{}, myErr
+	}
+	if request.DraftReplacesRules {
+		return statemachine.NewDefinition(request.ReferencedType, definition.States, request.DraftRules), nil
+	}
+
+	return definition.WithRules(request.DraftRules), nil
+}
@@ -0,0 +1,260 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"github.com/abenstex/laniakea/logging"
+	"github.com/abenstex/laniakea/micro"
+	"github.com/abenstex/laniakea/mqtt"
+	utils2 "github.com/abenstex/laniakea/utils"
+	structs2 "github.com/abenstex/orion.commons/structs"
+	"github.com/abenstex/orion.commons/utils"
+	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/bson"
+	"orion.misc/statemachine"
+	"orion.misc/structs"
+	"sync"
+	"time"
+)
+
+const StateEscalationEventTopic = "orion/server/misc/event/state/escalation"
+
+// Clock provides the current time so the scheduler can be driven by a fake clock
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (clock systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// StateEscalationScheduler periodically looks for tracked objects that stayed longer in a state than the
+// max dwell time of its transition rule and either escalates them or moves them into the escalation target state
+type StateEscalationScheduler struct {
+	baseAction micro.BaseAction
+	Clock      Clock
+	Interval   time.Duration
+	stop       chan struct{}
+	waitGroup  sync.WaitGroup
+}
+
+func NewStateEscalationScheduler(environment utils2.Environment, interval time.Duration, clock Clock) *StateEscalationScheduler {
+	if clock == nil {
+		clock = systemClock{}
+	}
+
+	return &StateEscalationScheduler{
+		baseAction: micro.BaseAction{Environment: environment, ID: utils2.NewUuid()},
+		Clock:      clock,
+		Interval:   interval,
+	}
+}
+
+func (scheduler *StateEscalationScheduler) Start() {
+	scheduler.stop = make(chan struct{})
+	scheduler.waitGroup.Add(1)
+	go func() {
+		defer scheduler.waitGroup.Done()
+		ticker := time.NewTicker(scheduler.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				err := scheduler.RunOnce(context.Background())
+				if err != nil {
+					logging.GetLogger(ApplicationName, scheduler.baseAction.Environment, true).WithError(err).Error("State escalation run failed")
+				}
+			case <-scheduler.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (scheduler *StateEscalationScheduler) Stop() {
+	if scheduler.stop == nil {
+		return
+	}
+	close(scheduler.stop)
+	scheduler.waitGroup.Wait()
+	scheduler.stop = nil
+}
+
+// RunOnce performs a single scan using the scheduler's clock. Every object is checked against the published
+// version of its state machine that applies to it, just like transitions that are validated or applied.
+// Only objects in states that have a max dwell time in the live rules or in a published version are loaded.
+func (scheduler *StateEscalationScheduler) RunOnce(ctx context.Context) error {
+	filter, orionErr := scheduler.candidateFilter(ctx)
+	if orionErr != nil {
+		return orionErr.Error
+	}
+	if filter == nil {
+		return nil
+	}
+	objectStates, orionErr := getObjectStatesFromDb(ctx, scheduler.baseAction, filter)
+	if orionErr != nil {
+		return orionErr.Error
+	}
+
+	now := scheduler.now()
+	resolvers := make(map[string]*stateMachineResolver)
+	escalations, err := scheduler.dueEscalations(objectStates, now, func(objectState structs.ObjectState) (statemachine.Definition, error) {
+		resolver, ok := resolvers[objectState.ObjectType]
+		if !ok {
+			resolver = newStateMachineResolver(scheduler.baseAction, objectState.ObjectType)
+			resolvers[objectState.ObjectType] = resolver
+		}
+		createdDate := objectState.CreatedDate
+		if createdDate <= 0 {
+			createdDate = now
+		}
+		definition, orionErr := resolver.resolve(ctx, createdDate)
+		if orionErr != nil {
+			return statemachine.Definition{}, orionErr.Error
+		}
+
+		return definition, nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, escalation := range escalations {
+		err := scheduler.escalate(ctx, escalation, now)
+		if err != nil {
+			logging.GetLogger(ApplicationName, scheduler.baseAction.Environment, true).WithError(err).
+				Errorf("Could not escalate %v %v", escalation.ObjectState.ObjectType, escalation.ObjectState.ObjectId)
+		}
+	}
+
+	return nil
+}
+
+// candidateFilter selects the objects that are not escalated yet and are in a state with a max dwell time, it is
+// nil if no state machine limits the dwell time
+func (scheduler *StateEscalationScheduler) candidateFilter(ctx context.Context) (bson.M, *structs2.OrionError) {
+	definitions, orionErr := loadAllStateMachineDefinitions(ctx, scheduler.baseAction)
+	if orionErr != nil {
+		return nil, orionErr
+	}
+	versions, orionErr := getDwellLimitedStateMachineVersionsFromDb(ctx, scheduler.baseAction)
+	if orionErr != nil {
+		return nil, orionErr
+	}
+	for _, version := range versions {
+		definitions = append(definitions, statemachine.NewDefinitionFromVersion(version))
+	}
+
+	return escalationCandidateFilter(definitions), nil
+}
+
+// escalationCandidateFilter builds the filter of the objects in the dwell limited states of the definitions
+func escalationCandidateFilter(definitions []statemachine.Definition) bson.M {
+	statesByType := make(map[string][]string)
+	objectTypes := make([]string, 0)
+	for _, definition := range definitions {
+		references := definition.DwellLimitedStates()
+		if len(references) == 0 {
+			continue
+		}
+		if _, ok := statesByType[definition.ReferencedType]; !ok {
+			objectTypes = append(objectTypes, definition.ReferencedType)
+		}
+		statesByType[definition.ReferencedType] = append(statesByType[definition.ReferencedType], references...)
+	}
+	if len(objectTypes) == 0 {
+		return nil
+	}
+	candidates := make(bson.A, 0, len(objectTypes))
+	for _, objectType := range objectTypes {
+		candidates = append(candidates, bson.M{"object_type": objectType, "current_state": bson.M{"$in": statesByType[objectType]}})
+	}
+
+	return bson.M{"escalated_date": bson.M{"$exists": false}, "$or": candidates}
+}
+
+// dueEscalations returns the escalations of the objects at the given time, definitionOf returns the state machine
+// that applies to an object
+func (scheduler *StateEscalationScheduler) dueEscalations(objectStates []structs.ObjectState, now int64,
+	definitionOf func(objectState structs.ObjectState) (statemachine.Definition, error)) ([]statemachine.Escalation, error) {
+	escalations := make([]statemachine.Escalation, 0)
+	for _, objectState := range objectStates {
+		definition, err := definitionOf(objectState)
+		if err != nil {
+			return nil, err
+		}
+		escalations = append(escalations, definition.DueEscalations([]structs.ObjectState{objectState}, now)...)
+	}
+
+	return escalations, nil
+}
+
+// now returns the time of the scheduler's clock in milliseconds
+func (scheduler *StateEscalationScheduler) now() int64 {
+	return scheduler.Clock.Now().UnixNano() / int64(time.Millisecond)
+}
+
+func (scheduler *StateEscalationScheduler) escalate(ctx context.Context, escalation statemachine.Escalation, now int64) error {
+	objectState := escalation.ObjectState
+	event := structs.StateEscalationEvent{
+		Header:                *micro.NewEventHeaderForAction(scheduler.ProvideInformation(), "", ""),
+		ObjectType:            objectState.ObjectType,
+		ObjectId:              objectState.ObjectId,
+		State:                 objectState.CurrentState,
+		EnteredDate:           objectState.EnteredDate,
+		MaxDwellSeconds:       *escalation.Rule.MaxDwellSeconds,
+		EscalationTargetState: escalation.Rule.EscalationTargetState,
+		Reason:                fmt.Sprintf("the object stayed longer than %d seconds in its state", *escalation.Rule.MaxDwellSeconds),
+	}
+
+	// only one instance of the service escalates an object, the others find it already escalated
+	result, err := scheduler.baseAction.Environment.MongoDbConnection.Database().Collection("object_states").UpdateOne(ctx,
+		bson.M{"object_type": objectState.ObjectType, "object_id": objectState.ObjectId, "current_state": objectState.CurrentState,
+			"escalated_date": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"escalated_date": now}})
+	if err != nil {
+		return err
+	}
+	if result.ModifiedCount != 1 {
+		return nil
+	}
+
+	var transitionErr error
+	if escalation.Rule.AutoTransition && escalation.Rule.EscalationTargetState != nil {
+		transition, decision, orionErr := applyObjectStateTransition(ctx, scheduler.baseAction, objectState.ObjectType, objectState.ObjectId,
+			statemachine.TransitionInput{
+				TargetState: *escalation.Rule.EscalationTargetState,
+				User:        ApplicationName,
+				Comment:     event.Reason,
+			}, now)
+		if orionErr != nil {
+			// the object is marked as escalated already, the event must be published or the escalation is lost
+			event.Reason = fmt.Sprintf("%v; the automatic transition failed: %v", event.Reason, orionErr.Error)
+			transitionErr = orionErr.Error
+		} else if transition != nil {
+			event.AutoTransitioned = true
+			sendStateChangedEvent(scheduler.ProvideInformation(), "", scheduler.baseAction.Environment, *transition)
+		} else {
+			event.Reason = fmt.Sprintf("%v; the automatic transition was rejected: %v", event.Reason, decision.Reason)
+		}
+	}
+	json, err := event.ToJsonString()
+	if err != nil {
+		return err
+	}
+	mqtt.Publish(StateEscalationEventTopic, json, byte(viper.GetInt("messageBus.publishEventQos")),
+		utils.GetDefaultMqttConnectionOptionsWithIdPrefix(scheduler.ProvideInformation().Name))
+
+	return transitionErr
+}
+
+func (scheduler *StateEscalationScheduler) ProvideInformation() micro.ActionInformation {
+	return micro.ActionInformation{
+		Name:        "StateEscalationScheduler",
+		Description: "Escalates objects that exceeded the max dwell time of their state",
+		EventTopic:  StateEscalationEventTopic,
+		Version:     1,
+		ClientId:    scheduler.baseAction.ID.String(),
+	}
+}
@@ -0,0 +1,113 @@
+package actions
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	utils2 "github.com/abenstex/laniakea/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"orion.misc/statemachine"
+	"orion.misc/structs"
+)
+
+type fakeClock struct {
+	time time.Time
+}
+
+func (clock *fakeClock) Now() time.Time {
+	return clock.time
+}
+
+func TestStateEscalationSchedulerUsesInjectedClock(t *testing.T) {
+	clock := &fakeClock{time: time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)}
+	scheduler := NewStateEscalationScheduler(utils2.Environment{}, time.Minute, clock)
+
+	if got, want := scheduler.now(), clock.time.UnixNano()/int64(time.Millisecond); got != want {
+		t.Fatalf("now() = %d, want %d", got, want)
+	}
+	clock.time = clock.time.Add(90 * time.Second)
+	if got, want := scheduler.now(), clock.time.UnixNano()/int64(time.Millisecond); got != want {
+		t.Fatalf("now() after advancing the clock = %d, want %d", got, want)
+	}
+}
+
+func TestStateEscalationSchedulerDefaultsToSystemClock(t *testing.T) {
+	scheduler := NewStateEscalationScheduler(utils2.Environment{}, time.Minute, nil)
+	before := time.Now().UnixNano() / int64(time.Millisecond)
+	now := scheduler.now()
+	after := time.Now().UnixNano() / int64(time.Millisecond)
+
+	if now < before || now > after {
+		t.Fatalf("now() = %d, want a value between %d and %d", now, before, after)
+	}
+}
+
+func ticketDefinition() statemachine.Definition {
+	maxDwell := int64(60)
+	target := "ESCALATED"
+
+	return statemachine.NewDefinition("TICKET", nil, []structs.StateTransitionRule{
+		{ReferencedType: "TICKET", SourceState: "OPEN", AllowedTargetStates: []string{"CLOSED", target}, MaxDwellSeconds: &maxDwell,
+			EscalationTargetState: &target, AutoTransition: true},
+		{ReferencedType: "TICKET", SourceState: "CLOSED", AllowedTargetStates: []string{"OPEN"}},
+	})
+}
+
+func TestStateEscalationSchedulerDueEscalationsFollowClock(t *testing.T) {
+	clock := &fakeClock{time: time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)}
+	scheduler := NewStateEscalationScheduler(utils2.Environment{}, time.Minute, clock)
+	entered := scheduler.now()
+	objectStates := []structs.ObjectState{
+		{ObjectType: "TICKET", ObjectId: "1", CurrentState: "OPEN", EnteredDate: entered},
+		{ObjectType: "TICKET", ObjectId: "2", CurrentState: "CLOSED", EnteredDate: entered},
+	}
+	definitionOf := func(objectState structs.ObjectState) (statemachine.Definition, error) {
+		return ticketDefinition(), nil
+	}
+
+	escalations, err := scheduler.dueEscalations(objectStates, scheduler.now(), definitionOf)
+	if err != nil {
+		t.Fatalf("dueEscalations() returned %v", err)
+	}
+	if len(escalations) != 0 {
+		t.Fatalf("dueEscalations() right after entering the state = %d escalations, want none", len(escalations))
+	}
+
+	clock.time = clock.time.Add(61 * time.Second)
+	escalations, err = scheduler.dueEscalations(objectStates, scheduler.now(), definitionOf)
+	if err != nil {
+		t.Fatalf("dueEscalations() returned %v", err)
+	}
+	if len(escalations) != 1 || escalations[0].ObjectState.ObjectId != "1" {
+		t.Fatalf("dueEscalations() after the max dwell time = %v, want the open ticket only", escalations)
+	}
+
+	failure := errors.New("no state machine")
+	_, err = scheduler.dueEscalations(objectStates, scheduler.now(), func(objectState structs.ObjectState) (statemachine.Definition, error) {
+		return statemachine.Definition{}, failure
+	})
+	if err != failure {
+		t.Fatalf("dueEscalations() = %v, want the error of the definition", err)
+	}
+}
+
+func TestEscalationCandidateFilter(t *testing.T) {
+	if filter := escalationCandidateFilter([]statemachine.Definition{statemachine.NewDefinition("ORDER", nil, nil)}); filter != nil {
+		t.Fatalf("escalationCandidateFilter() without dwell limits = %v, want nil", filter)
+	}
+
+	filter := escalationCandidateFilter([]statemachine.Definition{ticketDefinition(), statemachine.NewDefinition("ORDER", nil, nil)})
+	candidates, ok := filter["$or"].(bson.A)
+	if !ok || len(candidates) != 1 {
+		t.Fatalf("escalationCandidateFilter() = %v, want one candidate type", filter)
+	}
+	candidate := candidates[0].(bson.M)
+	states := candidate["current_state"].(bson.M)["$in"].([]string)
+	if candidate["object_type"] != "TICKET" || len(states) != 1 || states[0] != "OPEN" {
+		t.Fatalf("escalationCandidateFilter() candidate = %v, want the OPEN tickets", candidate)
+	}
+	if _, ok := filter["escalated_date"]; !ok {
+		t.Fatalf("escalationCandidateFilter() = %v, want escalated objects to be excluded", filter)
+	}
+}
@@ -0,0 +1,158 @@
+package actions
+
+import (
+	"context"
+	"github.com/abenstex/laniakea/micro"
+	"github.com/abenstex/laniakea/utils"
+	structs2 "github.com/abenstex/orion.commons/structs"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"orion.misc/statemachine"
+	"orion.misc/structs"
+)
+
+func getStateDefinitionsFromDb(ctx context.Context, baseAction micro.BaseAction, filter bson.M) ([]structs.StateDefinition, *structs2.OrionError) {
+	cursor, err := baseAction.Environment.MongoDbConnection.Database().Collection("states").Find(ctx, filter)
+	if err != nil {
+		return nil, structs2.NewOrionError(structs2.DatabaseError, err)
+	}
+	var objects []structs.StateDefinition
+	if err = cursor.All(ctx, &objects); err != nil {
+		return nil, structs2.NewOrionError(structs2.DatabaseError, err)
+	}
+
+	return objects, nil
+}
+
+func getAllStateTransitionRulesFromDb(ctx context.Context, baseAction micro.BaseAction) ([]structs.StateTransitionRule, *structs2.OrionError) {
+	cursor, err := baseAction.Environment.MongoDbConnection.Database().Collection("state_transition_rules").Find(ctx, bson.M{})
+	if err != nil {
+		return nil, structs2.NewOrionError(structs2.DatabaseError, err)
+	}
+	var objects []structs.StateTransitionRule
+	if err = cursor.All(ctx, &objects); err != nil {
+		return nil, structs2.NewOrionError(structs2.DatabaseError, err)
+	}
+
+	return objects, nil
+}
+
+func loadStateMachineDefinition(ctx context.Context, baseAction micro.BaseAction, referencedType string) (statemachine.Definition, *structs2.OrionError) {
+	states, orionErr := getStateDefinitionsFromDb(ctx, baseAction, bson.M{"referenced_type": referencedType})
+	if orionErr != nil {
+		return statemachine.Definition{}, orionErr
+	}
+	rules, orionErr := getAllStateTransitionRulesFromDb(ctx, baseAction)
+	if orionErr != nil {
+		return statemachine.Definition{}, orionErr
+	}
+
+	return statemachine.NewDefinition(referencedType, states, rules), nil
+}
+
+func loadAllStateMachineDefinitions(ctx context.Context, baseAction micro.BaseAction) ([]statemachine.Definition, *structs2.OrionError) {
+	states, orionErr := getStateDefinitionsFromDb(ctx, baseAction, bson.M{})
+	if orionErr != nil {
+		return nil, orionErr
+	}
+	rules, orionErr := getAllStateTransitionRulesFromDb(ctx, baseAction)
+	if orionErr != nil {
+		return nil, orionErr
+	}
+
+	return statemachine.NewDefinitions(states, rules), nil
+}
+
+func getStateMachineVersionsFromDb(ctx context.Context, baseAction micro.BaseAction, referencedType string) ([]structs.StateMachineVersion, *structs2.OrionError) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "version", Value: 1}})
+	cursor, err := baseAction.Environment.MongoDbArchiveConnection.Database().Collection("state_machine_versions").
+		Find(ctx, bson.M{"referenced_type": referencedType}, findOptions)
+	if err != nil {
+		return nil, structs2.NewOrionError(structs2.DatabaseError, err)
+	}
+	objects := make([]structs.StateMachineVersion, 0)
+	if err = cursor.All(ctx, &objects); err != nil {
+		return nil, structs2.NewOrionError(structs2.DatabaseError, err)
+	}
+
+	return objects, nil
+}
+
+// stateMachineResolver resolves the definitions of many objects of one referenced type, the published versions and
+// the live rules are read only once
+type stateMachineResolver struct {
+	baseAction     micro.BaseAction
+	referencedType string
+	versions       []structs.StateMachineVersion
+	loaded         bool
+	definitions    map[int]statemachine.Definition
+}
+
+func newStateMachineResolver(baseAction micro.BaseAction, referencedType string) *stateMachineResolver {
+	return &stateMachineResolver{
+		baseAction:     baseAction,
+		referencedType: referencedType,
+		definitions:    make(map[int]statemachine.Definition),
+	}
+}
+
+// resolve returns the same definition as resolveStateMachineDefinition for an object created at the given time
+func (resolver *stateMachineResolver) resolve(ctx context.Context, objectCreatedDate int64) (statemachine.Definition, *structs2.OrionError) {
+	if !resolver.loaded {
+		versions, orionErr := getStateMachineVersionsFromDb(ctx, resolver.baseAction, resolver.referencedType)
+		if orionErr != nil {
+			return statemachine.Definition{}, orionErr
+		}
+		resolver.versions = versions
+		resolver.loaded = true
+	}
+	// the live rules are kept as version 0
+	versionNumber := 0
+	version := statemachine.SelectVersion(resolver.versions, objectCreatedDate)
+	if version != nil {
+		versionNumber = version.Version
+	}
+	if definition, ok := resolver.definitions[versionNumber]; ok {
+		return definition, nil
+	}
+
+	var definition statemachine.Definition
+	if version != nil {
+		definition = statemachine.NewDefinitionFromVersion(*version)
+	} else {
+		live, orionErr := loadStateMachineDefinition(ctx, resolver.baseAction, resolver.referencedType)
+		if orionErr != nil {
+			return statemachine.Definition{}, orionErr
+		}
+		definition = live
+	}
+	resolver.definitions[versionNumber] = definition
+
+	return definition, nil
+}
+
+// resolveStateMachineDefinition returns the published version of the state machine that applies to an object
+// created at the given time (0 if unknown). Referenced types without published versions use the live rules.
+func resolveStateMachineDefinition(ctx context.Context, baseAction micro.BaseAction, referencedType string, objectCreatedDate int64) (statemachine.Definition, *structs2.OrionError) {
+	if objectCreatedDate <= 0 {
+		objectCreatedDate = utils.GetCurrentTimeStamp()
+	}
+
+	return newStateMachineResolver(baseAction, referencedType).resolve(ctx, objectCreatedDate)
+}
+
+// getDwellLimitedStateMachineVersionsFromDb returns the published versions of all referenced types that limit the
+// dwell time of at least one state
+func getDwellLimitedStateMachineVersionsFromDb(ctx context.Context, baseAction micro.BaseAction) ([]structs.StateMachineVersion, *structs2.OrionError) {
+	cursor, err := baseAction.Environment.MongoDbArchiveConnection.Database().Collection("state_machine_versions").
+		Find(ctx, bson.M{"rules.max_dwell_seconds": bson.M{"$gt": 0}})
+	if err != nil {
+		return nil, structs2.NewOrionError(structs2.DatabaseError, err)
+	}
+	objects := make([]structs.StateMachineVersion, 0)
+	if err = cursor.All(ctx, &objects); err != nil {
+		return nil, structs2.NewOrionError(structs2.DatabaseError, err)
+	}
+
+	return objects, nil
+}
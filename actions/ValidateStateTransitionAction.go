@@ -0,0 +1,154 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/abenstex/laniakea/dataStructures"
+	"github.com/abenstex/laniakea/micro"
+	utils2 "github.com/abenstex/laniakea/utils"
+	"github.com/abenstex/orion.commons/app"
+	http2 "github.com/abenstex/orion.commons/http"
+	structs2 "github.com/abenstex/orion.commons/structs"
+	"github.com/abenstex/orion.commons/utils"
+	"net/http"
+	"orion.misc/statemachine"
+	"orion.misc/structs"
+	"time"
+)
+
+type ValidateStateTransitionAction struct {
+	baseAction      micro.BaseAction
+	MetricsStore    *utils.MetricsStore
+	receivedRequest structs.ValidateStateTransitionRequest
+}
+
+func (action *ValidateStateTransitionAction) BeforeAction(ctx context.Context, request []byte) *micro.Exception {
+	dummy := structs.ValidateStateTransitionRequest{}
+	err := json.Unmarshal(request, &dummy)
+	if err != nil {
+		return micro.NewException(structs2.UnmarshalError, err)
+	}
+	err = app.DefaultHandleActionRequest(request, &dummy.Header, action, true)
+	if err != nil {
+		return micro.NewException(structs2.RequestHeaderInvalid, err)
+	}
+	if len(dummy.ObjectType) == 0 || len(dummy.CurrentState) == 0 || len(dummy.TargetState) == 0 {
+		return micro.NewException(structs2.MissingParameterError,
+			fmt.Errorf("not all parameters (object_type, current_state and target_state) were provided"))
+	}
+
+	return nil
+}
+
+func (action ValidateStateTransitionAction) BeforeActionAsync(ctx context.Context, request []byte) {
+
+}
+
+func (action ValidateStateTransitionAction) AfterAction(ctx context.Context, reply *micro.IReply, request *micro.IRequest) *micro.Exception {
+	return nil
+}
+
+func (action ValidateStateTransitionAction) AfterActionAsync(ctx context.Context, reply micro.IReply, request micro.IRequest) {
+
+}
+
+func (action ValidateStateTransitionAction) GetBaseAction() micro.BaseAction {
+	return action.baseAction
+}
+
+func (action *ValidateStateTransitionAction) SetHttpRequest(request *http.Request) {
+	action.baseAction.Request = request
+}
+
+func (action *ValidateStateTransitionAction) InitBaseAction(baseAction micro.BaseAction) {
+	action.baseAction = baseAction
+}
+
+func (action ValidateStateTransitionAction) SendEvents(request micro.IRequest) {
+
+}
+
+func (action ValidateStateTransitionAction) ProvideInformation() micro.ActionInformation {
+	var reply = "orion/server/misc/reply/state/transition/validate"
+	var error = "orion/server/misc/error/state/transition/validate"
+	var requestSample = dataStructures.StructToJsonString(structs.ValidateStateTransitionRequest{})
+	var replySample = dataStructures.StructToJsonString(structs.ValidateStateTransitionReply{})
+	info := micro.ActionInformation{
+		Name:            "ValidateStateTransitionAction",
+		Description:     "Checks whether an object type may move from its current state to a target state according to the state transition rules",
+		RequestTopic:    "orion/server/misc/request/state/transition/validate",
+		ReplyTopic:      reply,
+		ErrorReplyTopic: error,
+		Version:         1,
+		ClientId:        action.baseAction.ID.String(),
+		HttpMethods:     []string{http.MethodPost, "OPTIONS"},
+		RequestSample:   &requestSample,
+		ReplySample:     &replySample,
+		IsScriptable:    true,
+	}
+
+	return info
+}
+
+func (action *ValidateStateTransitionAction) HandleWebRequest(writer http.ResponseWriter, request *http.Request) {
+	action.SetHttpRequest(request)
+	http2.HandleHttpRequest(writer, request, action)
+}
+
+func (action *ValidateStateTransitionAction) HeyHo(ctx context.Context, request []byte) (micro.IReply, micro.IRequest) {
+	start := time.Now()
+	defer action.MetricsStore.HandleActionMetric(start, action.GetBaseAction().Environment, action.ProvideInformation(), *action.baseAction.Token)
+
+	err := json.Unmarshal(request, &action.receivedRequest)
+	if err != nil {
+		return structs2.NewErrorReplyHeaderWithException(micro.NewException(structs2.UnmarshalError, err),
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	reply, myErr := action.validateTransition(ctx, action.receivedRequest)
+	if myErr != nil {
+		return structs2.NewErrorReplyHeaderWithOrionErr(myErr,
+			action.ProvideInformation().ErrorReplyTopic), &action.receivedRequest
+	}
+
+	return reply, &action.receivedRequest
+}
+
+func (action ValidateStateTransitionAction) validateTransition(ctx context.Context, request structs.ValidateStateTransitionRequest) (structs.ValidateStateTransitionReply, *structs2.OrionError) {
+	var createdDate int64
+	if request.ObjectCreatedDate != nil {
+		createdDate = *request.ObjectCreatedDate
+	}
+	definition, myErr := resolveStateMachineDefinition(ctx, action.baseAction, request.ObjectType, createdDate)
+	if myErr != nil {
+		return structs.ValidateStateTransitionReply{}, myErr
+	}
+
+	userRoles, myErr := getUserRoles(ctx, action.baseAction, request.Header.User)
+	if myErr != nil {
+		return structs.ValidateStateTransitionReply{}, myErr
+	}
+
+	decision := definition.Evaluate(statemachine.TransitionInput{
+		CurrentState: request.CurrentState,
+		TargetState:  request.TargetState,
+		Object:       request.Object,
+		User:         request.Header.User,
+		UserRoles:    userRoles,
+		Comment:      request.Header.Comment,
+	})
+
+	var reply = structs.ValidateStateTransitionReply{}
+	reply.Header = structs2.NewReplyHeader(action.ProvideInformation().ReplyTopic)
+	reply.Header.Timestamp = utils2.GetCurrentTimeStamp()
+	reply.Header.Success = true
+	reply.Allowed = decision.Allowed
+	reply.ReasonCode = decision.ReasonCode
+	reply.Reason = decision.Reason
+	reply.MatchingRule = decision.MatchingRule
+	reply.FailedGuards = decision.FailedGuards
+	reply.StateMachineVersion = definition.Version
+
+	return reply, nil
+}
@@ -0,0 +1,102 @@
+package attributes
+
+import (
+	"encoding/json"
+	"fmt"
+	"orion.misc/structs"
+	"strings"
+)
+
+const (
+	CardinalitySingle   = "SINGLE"
+	CardinalityMultiple = "MULTIPLE"
+)
+
+const ErrorMultipleValuesNotAllowed = "MULTIPLE_VALUES_NOT_ALLOWED"
+
+// IsMultiValued reports whether an attribute holds several values
+func IsMultiValued(definition structs.AttributeDefinition) bool {
+	return strings.ToUpper(definition.Cardinality) == CardinalityMultiple
+}
+
+// ValueList returns the single values of a value. Multi-valued attributes that were sent with Value only are
+// treated as having that one value.
+func ValueList(definition structs.AttributeDefinition, value structs.AttributeValue) []string {
+	if IsMultiValued(definition) && (len(value.Values) > 0 || len(value.Value) == 0) {
+		return value.Values
+	}
+
+	return []string{value.Value}
+}
+
+// NewValue creates the value of an attribute from its string representation, the values of multi-valued attributes
+// are separated by MultiValueSeparator
+func NewValue(definition structs.AttributeDefinition, objectType, objectId, raw string) structs.AttributeValue {
+	value := structs.AttributeValue{
+		AttributeId: definition.ID.Hex(),
+		ObjectType:  objectType,
+		ObjectId:    objectId,
+		Value:       raw,
+	}
+	if IsMultiValued(definition) {
+		value.Value = ""
+		value.Values = make([]string, 0)
+		for _, single := range strings.Split(raw, MultiValueSeparator) {
+			if single = strings.TrimSpace(single); len(single) > 0 {
+				value.Values = append(value.Values, single)
+			}
+		}
+	}
+
+	return value
+}
+
+// NormalizeValue prepares a value for storing: multi-valued attributes get their values without duplicates in Values
+// and as a JSON array in Value, so that changes can be detected and archived like single values
+func NormalizeValue(definition structs.AttributeDefinition, value structs.AttributeValue) (structs.AttributeValue, error) {
+	if !IsMultiValued(definition) {
+		value.Values = nil
+		value.TypedValue = IndexValue(definition.DataType, value.Value)
+
+		return value, nil
+	}
+
+	seen := make(map[string]bool)
+	values := make([]string, 0)
+	typedValues := make([]interface{}, 0)
+	for _, single := range ValueList(definition, value) {
+		if seen[single] {
+			continue
+		}
+		seen[single] = true
+		values = append(values, single)
+		typedValues = append(typedValues, IndexValue(definition.DataType, single))
+	}
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		return value, err
+	}
+	value.Values = values
+	value.Value = string(encoded)
+	value.TypedValue = typedValues
+
+	return value, nil
+}
+
+// CheckCardinalities makes sure that every attribute has a known cardinality and that computed attributes are
+// single-valued
+func CheckCardinalities(definitions []structs.AttributeDefinition) error {
+	for _, definition := range definitions {
+		switch strings.ToUpper(definition.Cardinality) {
+		case "", CardinalitySingle:
+		case CardinalityMultiple:
+			if IsComputed(definition) {
+				return fmt.Errorf("the computed attribute %v can't hold multiple values", definition.Info.Name)
+			}
+		default:
+			return fmt.Errorf("the cardinality %v of attribute %v is unknown", definition.Cardinality, definition.Info.Name)
+		}
+	}
+
+	return nil
+}
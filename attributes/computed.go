@@ -0,0 +1,155 @@
+package attributes
+
+import (
+	"fmt"
+	"orion.misc/structs"
+	"strings"
+)
+
+const (
+	KindStored   = "STORED"
+	KindComputed = "COMPUTED"
+)
+
+// IsComputed reports whether the value of an attribute is calculated from its expression instead of being stored
+func IsComputed(definition structs.AttributeDefinition) bool {
+	return strings.ToUpper(definition.Kind) == KindComputed
+}
+
+// CheckComputedDefinitions makes sure that the named definitions, new attributes and renamed ones, do not take the
+// name of another attribute, expressions reference attributes by it. Names used more than once before are tolerated
+// until one of the attributes is renamed. It parses the expressions of all computed attributes and makes sure that
+// they only reference existing attributes and that no attribute depends on itself, directly or through other attributes.
+func CheckComputedDefinitions(definitions, named []structs.AttributeDefinition) error {
+	byName := make(map[string]structs.AttributeDefinition, len(definitions))
+	usages := make(map[string]int, len(definitions))
+	for _, definition := range definitions {
+		byName[definition.Info.Name] = definition
+		usages[definition.Info.Name]++
+	}
+	for _, definition := range named {
+		if usages[definition.Info.Name] > 1 {
+			return fmt.Errorf("the name %v is used by more than one attribute", definition.Info.Name)
+		}
+	}
+
+	dependencies := make(map[string][]string)
+	for _, definition := range definitions {
+		if !IsComputed(definition) {
+			continue
+		}
+		if definition.Expression == nil || len(strings.TrimSpace(*definition.Expression)) == 0 {
+			return fmt.Errorf("the computed attribute %v has no expression", definition.Info.Name)
+		}
+		expression, err := ParseExpression(*definition.Expression)
+		if err != nil {
+			return fmt.Errorf("the expression of attribute %v is invalid: %v", definition.Info.Name, err)
+		}
+		for _, reference := range expression.References {
+			if _, ok := byName[reference]; !ok {
+				return fmt.Errorf("the expression of attribute %v references the unknown attribute %v", definition.Info.Name, reference)
+			}
+		}
+		dependencies[definition.Info.Name] = expression.References
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	marks := make(map[string]int)
+	var path []string
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch marks[name] {
+		case visiting:
+			start := 0
+			for idx, entry := range path {
+				if entry == name {
+					start = idx
+				}
+			}
+			return fmt.Errorf("computed attributes depend on each other: %v", strings.Join(append(path[start:], name), " -> "))
+		case visited:
+			return nil
+		}
+		marks[name] = visiting
+		path = append(path, name)
+		for _, dependency := range dependencies[name] {
+			if err := visit(dependency); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		marks[name] = visited
+
+		return nil
+	}
+	for _, definition := range definitions {
+		if err := visit(definition.Info.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Evaluator evaluates the attributes of a single object. Stored attributes are resolved by Stored, computed
+// attributes are calculated from the attributes their expression references.
+type Evaluator struct {
+	// Definitions holds all attribute definitions by name
+	Definitions map[string]structs.AttributeDefinition
+	Stored      func(definition structs.AttributeDefinition) (Evaluation, error)
+	evaluating  map[string]bool
+}
+
+// NewEvaluator creates an evaluator for the given attribute definitions
+func NewEvaluator(definitions []structs.AttributeDefinition, stored func(definition structs.AttributeDefinition) (Evaluation, error)) *Evaluator {
+	evaluator := Evaluator{
+		Definitions: make(map[string]structs.AttributeDefinition, len(definitions)),
+		Stored:      stored,
+		evaluating:  make(map[string]bool),
+	}
+	for _, definition := range definitions {
+		evaluator.Definitions[definition.Info.Name] = definition
+	}
+
+	return &evaluator
+}
+
+// Evaluate resolves the value of an attribute
+func (evaluator *Evaluator) Evaluate(definition structs.AttributeDefinition) (Evaluation, error) {
+	if !IsComputed(definition) {
+		return evaluator.Stored(definition)
+	}
+	if evaluator.evaluating[definition.Info.Name] {
+		return Evaluation{}, fmt.Errorf("the computed attribute %v depends on itself", definition.Info.Name)
+	}
+	if definition.Expression == nil {
+		return Evaluation{}, fmt.Errorf("the computed attribute %v has no expression", definition.Info.Name)
+	}
+	expression, err := ParseExpression(*definition.Expression)
+	if err != nil {
+		return Evaluation{}, err
+	}
+
+	evaluator.evaluating[definition.Info.Name] = true
+	defer delete(evaluator.evaluating, definition.Info.Name)
+	value, err := expression.Evaluate(func(name string) (interface{}, error) {
+		referenced, ok := evaluator.Definitions[name]
+		if !ok {
+			return nil, fmt.Errorf("the attribute %v does not exist", name)
+		}
+		evaluation, err := evaluator.Evaluate(referenced)
+		if err != nil {
+			return nil, err
+		}
+		return TypedValue(referenced.DataType, evaluation.Value), nil
+	})
+	if err != nil {
+		return Evaluation{}, fmt.Errorf("the attribute %v could not be computed: %v", definition.Info.Name, err)
+	}
+
+	return Evaluation{Value: FormatValue(value), Source: SourceComputed}, nil
+}
@@ -0,0 +1,56 @@
+package attributes
+
+import (
+	"strings"
+	"testing"
+
+	"orion.misc/structs"
+)
+
+func definition(name string, expression string) structs.AttributeDefinition {
+	definition := structs.AttributeDefinition{Kind: KindStored}
+	definition.Info.Name = name
+	if len(expression) > 0 {
+		definition.Kind = KindComputed
+		definition.Expression = &expression
+	}
+
+	return definition
+}
+
+func TestCheckComputedDefinitions(t *testing.T) {
+	tests := []struct {
+		name        string
+		definitions []structs.AttributeDefinition
+		// named holds the indexes of the new or renamed definitions
+		named []int
+		err   string
+	}{
+		{"valid", []structs.AttributeDefinition{definition("net", ""), definition("gross", "net * 1.19")}, nil, ""},
+		{"chained", []structs.AttributeDefinition{definition("a", ""), definition("b", "a + 1"), definition("c", "b * 2")}, nil, ""},
+		{"duplicate name", []structs.AttributeDefinition{definition("net", ""), definition("net", "")}, []int{1}, "used by more than one attribute"},
+		{"legacy duplicate name", []structs.AttributeDefinition{definition("net", ""), definition("net", ""), definition("gross", "")}, []int{2}, ""},
+		{"unknown reference", []structs.AttributeDefinition{definition("gross", "net * 1.19")}, nil, "unknown attribute net"},
+		{"self reference", []structs.AttributeDefinition{definition("a", "a + 1")}, nil, "a -> a"},
+		{"cycle", []structs.AttributeDefinition{definition("a", "b + 1"), definition("b", "a + 1")}, nil, "a -> b -> a"},
+		{"invalid expression", []structs.AttributeDefinition{definition("a", "1 +")}, nil, "is invalid"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			named := make([]structs.AttributeDefinition, 0, len(test.named))
+			for _, idx := range test.named {
+				named = append(named, test.definitions[idx])
+			}
+			err := CheckComputedDefinitions(test.definitions, named)
+			if len(test.err) == 0 {
+				if err != nil {
+					t.Fatalf("CheckComputedDefinitions failed: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("CheckComputedDefinitions error = %v, want an error containing %q", err, test.err)
+			}
+		})
+	}
+}
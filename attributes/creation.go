@@ -0,0 +1,153 @@
+package attributes
+
+import (
+	"encoding/json"
+	"fmt"
+	"orion.misc/structs"
+	"strings"
+)
+
+// CreatedObject is an object that another module created, Fields holds the object as it was published
+type CreatedObject struct {
+	ObjectId string
+	Fields   map[string]interface{}
+}
+
+// CreatedObjects reads the created objects from a save event of another module, e.g. on
+// orion/server/core/event/user/save. Save events carry the object type and the saved objects in a list named after
+// the type, so every list of objects is read. Objects are identified by _id or by info.id, the save actions publish
+// new objects with the id of the inserted document. Objects that have a change date were changed rather than created
+// and are left out, just like objects without an id.
+func CreatedObjects(topic string, payload []byte) (string, []CreatedObject, error) {
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return "", nil, err
+	}
+	var objectType string
+	if raw, ok := fields["object_type"]; ok {
+		if err := json.Unmarshal(raw, &objectType); err != nil {
+			return "", nil, fmt.Errorf("the object type could not be read: %v", err)
+		}
+	}
+	if levels := strings.Split(topic, "/"); len(objectType) == 0 && len(levels) == 6 {
+		objectType = levels[4]
+	}
+	if len(objectType) == 0 {
+		return "", nil, fmt.Errorf("the event has no object type")
+	}
+
+	created := make([]CreatedObject, 0)
+	for name, raw := range fields {
+		if name == "event_header" || name == "object_type" {
+			continue
+		}
+		var objects []map[string]interface{}
+		if err := json.Unmarshal(raw, &objects); err != nil {
+			continue
+		}
+		for _, object := range objects {
+			if changeDate, _ := objectField(object, "info.change_date"); changeDate != nil {
+				continue
+			}
+			objectId := objectIdOf(object)
+			if len(objectId) == 0 {
+				continue
+			}
+			created = append(created, CreatedObject{ObjectId: objectId, Fields: object})
+		}
+	}
+
+	return objectType, created, nil
+}
+
+func objectIdOf(object map[string]interface{}) string {
+	for _, path := range []string{"_id", "info.id"} {
+		id, err := objectField(object, path)
+		if err != nil || id == nil {
+			continue
+		}
+		if formatted := FormatValue(id); formatted != nil && len(*formatted) > 0 && *formatted != "0" {
+			return *formatted
+		}
+	}
+
+	return ""
+}
+
+// CheckCreationQueries makes sure that the creation queries of the definitions are valid expressions
+func CheckCreationQueries(definitions []structs.AttributeDefinition) error {
+	for _, definition := range definitions {
+		if definition.CreationQuery == nil || len(strings.TrimSpace(*definition.CreationQuery)) == 0 {
+			continue
+		}
+		if _, err := ParseExpression(*definition.CreationQuery); err != nil {
+			return fmt.Errorf("the creation query of attribute %v is invalid: %v", definition.Info.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// CreationDefaults returns the values of the attributes that are assigned when an object of a type is created.
+// The creation query of a definition is an expression over the fields of the created object, the default value is
+// used if there is no creation query or it has no result. Definitions whose creation query fails are skipped and
+// reported as error.
+func CreationDefaults(definitions []structs.AttributeDefinition, objectType, objectId string, object map[string]interface{}) ([]structs.AttributeValue, []error) {
+	values := make([]structs.AttributeValue, 0)
+	errs := make([]error, 0)
+	for _, definition := range definitions {
+		if !definition.AssignDuringObjectCreation || IsComputed(definition) || definition.ID == nil || !allowedFor(definition, objectType) {
+			continue
+		}
+		defaultValue := definition.DefaultValue
+		if definition.CreationQuery != nil && len(strings.TrimSpace(*definition.CreationQuery)) > 0 {
+			queried, err := queryDefault(*definition.CreationQuery, object)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("the default of attribute %v could not be queried: %v", definition.Info.Name, err))
+				continue
+			}
+			if queried != nil {
+				defaultValue = queried
+			}
+		}
+		if defaultValue == nil {
+			continue
+		}
+		values = append(values, NewValue(definition, objectType, objectId, *defaultValue))
+	}
+
+	return values, errs
+}
+
+func queryDefault(query string, object map[string]interface{}) (*string, error) {
+	expression, err := ParseExpression(query)
+	if err != nil {
+		return nil, err
+	}
+	value, err := expression.Evaluate(func(name string) (interface{}, error) {
+		return objectField(object, name)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return FormatValue(value), nil
+}
+
+// objectField reads a field of a JSON object, nested fields are separated by dots
+func objectField(object map[string]interface{}, path string) (interface{}, error) {
+	var current interface{} = object
+	for _, name := range strings.Split(path, ".") {
+		fields, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, nil
+		}
+		current = fields[name]
+	}
+	switch current.(type) {
+	case nil, string, float64, bool:
+		return current, nil
+	default:
+		return nil, fmt.Errorf("the field %v is neither a string, a number nor a boolean", path)
+	}
+}
@@ -0,0 +1,126 @@
+package attributes
+
+import (
+	"fmt"
+	"orion.misc/structs"
+	"strings"
+)
+
+const (
+	ErrorParentValueMissing      = "PARENT_VALUE_MISSING"
+	ErrorValueNotAllowedByParent = "VALUE_NOT_ALLOWED_BY_PARENT"
+	ErrorDependentValueInvalid   = "DEPENDENT_VALUE_INVALID"
+)
+
+// FindDefinition returns the definition that is referenced either by its id or by its name
+func FindDefinition(definitions []structs.AttributeDefinition, reference string) *structs.AttributeDefinition {
+	for idx, definition := range definitions {
+		if definition.ID != nil && definition.ID.Hex() == reference {
+			return &definitions[idx]
+		}
+	}
+	for idx, definition := range definitions {
+		if definition.Info.Name == reference {
+			return &definitions[idx]
+		}
+	}
+
+	return nil
+}
+
+// EffectiveListOfValues returns the values that can be chosen for an attribute given the values of its parent
+// attribute, a single-valued parent has at most one. The values allowed by any of the parent values can be chosen.
+// Restricted is false if any value is allowed. An attribute that depends on a parent without a value has no allowed
+// values at all.
+func EffectiveListOfValues(definition structs.AttributeDefinition, parentValues []string) (values []string, restricted bool) {
+	if definition.DependsOn == nil {
+		return definition.ListOfValues, len(definition.ListOfValues) > 0
+	}
+	values = make([]string, 0)
+	for _, mapping := range definition.DependsOn.Values {
+		if !contains(parentValues, mapping.ParentValue) {
+			continue
+		}
+		for _, value := range mapping.Values {
+			if (len(definition.ListOfValues) == 0 || contains(definition.ListOfValues, value)) && !contains(values, value) {
+				values = append(values, value)
+			}
+		}
+	}
+
+	return values, true
+}
+
+// ValidateDependency checks that a value is allowed for the values of its parent attribute, see EffectiveListOfValues
+func ValidateDependency(definition structs.AttributeDefinition, value structs.AttributeValue, parentValues []string) []structs.AttributeValidationError {
+	validationErrors := make([]structs.AttributeValidationError, 0)
+	if definition.DependsOn == nil {
+		return validationErrors
+	}
+	if len(parentValues) == 0 {
+		return append(validationErrors, NewValidationError(value, ErrorParentValueMissing,
+			fmt.Sprintf("the value depends on the attribute %v which has no value", definition.DependsOn.ParentAttribute)))
+	}
+	allowed, _ := EffectiveListOfValues(definition, parentValues)
+	for _, single := range ValueList(definition, value) {
+		if !contains(allowed, single) {
+			validationErrors = append(validationErrors, NewValidationError(value, ErrorValueNotAllowedByParent,
+				fmt.Sprintf("%v is not allowed when %v is %v", single, definition.DependsOn.ParentAttribute, strings.Join(parentValues, ", "))))
+		}
+	}
+
+	return validationErrors
+}
+
+// ValidateDependentValue checks that the stored value of a dependent attribute is still allowed for a new value of its
+// parent attribute, the error is reported for the new parent value
+func ValidateDependentValue(parent structs.AttributeDefinition, value structs.AttributeValue, dependent structs.AttributeDefinition,
+	dependentValue structs.AttributeValue) []structs.AttributeValidationError {
+	if len(ValidateDependency(dependent, dependentValue, ValueList(parent, value))) == 0 {
+		return nil
+	}
+
+	return []structs.AttributeValidationError{NewValidationError(value, ErrorDependentValueInvalid,
+		fmt.Sprintf("the value %v of the dependent attribute %v is not allowed for the new value",
+			strings.Join(ValueList(dependent, dependentValue), ", "), dependent.Info.Name))}
+}
+
+// Dependents returns the attributes that depend on the values of the given attribute
+func Dependents(definitions []structs.AttributeDefinition, definition structs.AttributeDefinition) []structs.AttributeDefinition {
+	dependents := make([]structs.AttributeDefinition, 0)
+	for _, candidate := range definitions {
+		if candidate.DependsOn == nil {
+			continue
+		}
+		if parent := FindDefinition(definitions, candidate.DependsOn.ParentAttribute); parent != nil && parent.ID != nil &&
+			definition.ID != nil && *parent.ID == *definition.ID {
+			dependents = append(dependents, candidate)
+		}
+	}
+
+	return dependents
+}
+
+// CheckValueDependencies makes sure that every parent attribute exists and that attributes don't depend on themselves,
+// directly or through other attributes
+func CheckValueDependencies(definitions []structs.AttributeDefinition) error {
+	for _, definition := range definitions {
+		seen := map[string]bool{}
+		chain := []string{definition.Info.Name}
+		current := definition
+		for current.DependsOn != nil {
+			parent := FindDefinition(definitions, current.DependsOn.ParentAttribute)
+			if parent == nil {
+				return fmt.Errorf("the attribute %v depends on the unknown attribute %v", current.Info.Name, current.DependsOn.ParentAttribute)
+			}
+			chain = append(chain, parent.Info.Name)
+			if parent.Info.Name == definition.Info.Name || seen[parent.Info.Name] {
+				return fmt.Errorf("the lists of values depend on each other: %v", strings.Join(chain, " -> "))
+			}
+			seen[parent.Info.Name] = true
+			current = *parent
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package attributes
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"orion.misc/structs"
+)
+
+func dependentDefinitions() (parent, dependent structs.AttributeDefinition) {
+	parentId := primitive.NewObjectID()
+	parent = structs.AttributeDefinition{Cardinality: CardinalityMultiple}
+	parent.ID = &parentId
+	parent.Info.Name = "country"
+	dependentId := primitive.NewObjectID()
+	dependent = structs.AttributeDefinition{DependsOn: &structs.ValueDependency{
+		ParentAttribute: "country",
+		Values: []structs.DependentValues{
+			{ParentValue: "DE", Values: []string{"Berlin", "Hamburg"}},
+			{ParentValue: "FR", Values: []string{"Paris"}},
+		},
+	}}
+	dependent.ID = &dependentId
+	dependent.Info.Name = "city"
+
+	return parent, dependent
+}
+
+func TestValidateDependencyWithMultiValuedParent(t *testing.T) {
+	parent, dependent := dependentDefinitions()
+	city := func(value string) structs.AttributeValue {
+		return structs.AttributeValue{AttributeId: dependent.ID.Hex(), ObjectType: "SHOP", ObjectId: "1", Value: value}
+	}
+	pending := NewValue(parent, "SHOP", "1", "DE|FR")
+	stored, err := NormalizeValue(parent, pending)
+	if err != nil {
+		t.Fatalf("NormalizeValue failed: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		parentValue structs.AttributeValue
+		value       string
+		valid       bool
+	}{
+		{"allowed by the first pending parent value", pending, "Hamburg", true},
+		{"allowed by the second pending parent value", pending, "Paris", true},
+		{"allowed by a stored parent value", stored, "Paris", true},
+		{"not allowed by any parent value", stored, "Rome", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			validationErrors := ValidateDependency(dependent, city(test.value), ValueList(parent, test.parentValue))
+			if valid := len(validationErrors) == 0; valid != test.valid {
+				t.Fatalf("ValidateDependency() = %v, want valid = %v", validationErrors, test.valid)
+			}
+		})
+	}
+
+	validationErrors := ValidateDependency(dependent, city("Paris"), nil)
+	if len(validationErrors) != 1 || validationErrors[0].Code != ErrorParentValueMissing {
+		t.Fatalf("ValidateDependency() without parent value = %v, want %v", validationErrors, ErrorParentValueMissing)
+	}
+}
+
+func TestValidateDependentValue(t *testing.T) {
+	parent, dependent := dependentDefinitions()
+	stored := structs.AttributeValue{AttributeId: dependent.ID.Hex(), ObjectType: "SHOP", ObjectId: "1", Value: "Paris"}
+
+	if validationErrors := ValidateDependentValue(parent, NewValue(parent, "SHOP", "1", "FR|DE"), dependent, stored); len(validationErrors) != 0 {
+		t.Fatalf("ValidateDependentValue() = %v, want no errors", validationErrors)
+	}
+	validationErrors := ValidateDependentValue(parent, NewValue(parent, "SHOP", "1", "DE"), dependent, stored)
+	if len(validationErrors) != 1 || validationErrors[0].Code != ErrorDependentValueInvalid {
+		t.Fatalf("ValidateDependentValue() = %v, want %v", validationErrors, ErrorDependentValueInvalid)
+	}
+	if dependents := Dependents([]structs.AttributeDefinition{parent, dependent}, parent); len(dependents) != 1 || dependents[0].Info.Name != "city" {
+		t.Fatalf("Dependents() = %v, want city", dependents)
+	}
+}
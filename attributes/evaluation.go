@@ -0,0 +1,123 @@
+package attributes
+
+import (
+	"orion.misc/structs"
+	"sort"
+)
+
+const (
+	SourceObject    = "OBJECT"
+	SourceHierarchy = "HIERARCHY"
+	SourceDefault   = "DEFAULT"
+	SourceComputed  = "COMPUTED"
+	SourceNone      = "NONE"
+)
+
+// Evaluation is the resolved value of an attribute and the hierarchy level that supplied it
+type Evaluation struct {
+	Value  *string
+	Source string
+	Level  *structs.HierarchyLevel
+}
+
+// FindHierarchy returns the first hierarchy that contains the object type or nil if there is none
+func FindHierarchy(hierarchies []structs.Hierarchy, objectType string) *structs.Hierarchy {
+	for idx, hierarchy := range hierarchies {
+		for _, entry := range hierarchy.Entries {
+			if entry.ObjectType == objectType {
+				return &hierarchies[idx]
+			}
+		}
+	}
+
+	return nil
+}
+
+// Levels returns the hierarchy levels that are considered when evaluating an attribute of an object, starting
+// with the object itself and walking up to the root of the hierarchy (the entry with the lowest index).
+// Ancestors maps the object types of the upper levels to object ids, levels without an ancestor are skipped.
+func Levels(hierarchy *structs.Hierarchy, objectType, objectId string, ancestors map[string]string) []structs.HierarchyLevel {
+	own := structs.HierarchyLevel{ObjectType: objectType, ObjectId: objectId}
+	if hierarchy == nil {
+		return []structs.HierarchyLevel{own}
+	}
+	hierarchyId := ""
+	if hierarchy.ID != nil {
+		hierarchyId = hierarchy.ID.Hex()
+	}
+	own.HierarchyId = hierarchyId
+
+	entries := make([]structs.HierarchyEntry, len(hierarchy.Entries))
+	copy(entries, hierarchy.Entries)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Index < entries[j].Index
+	})
+
+	position := -1
+	for idx, entry := range entries {
+		if entry.ObjectType == objectType {
+			position = idx
+			own.Index = entry.Index
+			break
+		}
+	}
+	levels := []structs.HierarchyLevel{own}
+	for idx := position - 1; idx >= 0; idx-- {
+		ancestorId, ok := ancestors[entries[idx].ObjectType]
+		if !ok || len(ancestorId) == 0 {
+			continue
+		}
+		levels = append(levels, structs.HierarchyLevel{
+			HierarchyId: hierarchyId,
+			Index:       entries[idx].Index,
+			ObjectType:  entries[idx].ObjectType,
+			ObjectId:    ancestorId,
+		})
+	}
+
+	return levels
+}
+
+// ValueKey is the key of an object's value in the values passed to Evaluate
+func ValueKey(objectType, objectId string) string {
+	return objectType + "/" + objectId
+}
+
+// AttributeValueKey identifies the value of an attribute of an object
+func AttributeValueKey(objectType, objectId, attributeId string) string {
+	return ValueKey(objectType, objectId) + "/" + attributeId
+}
+
+// Evaluate resolves the value of an attribute from the values set on the given levels. Overwriteable attributes
+// take the value of the most specific level, otherwise a value set on an upper level can't be overwritten below it.
+// If no level carries a value the default value of the definition is used.
+func Evaluate(definition structs.AttributeDefinition, levels []structs.HierarchyLevel, values map[string]string) Evaluation {
+	order := make([]int, 0, len(levels))
+	for idx := range levels {
+		if definition.Overwriteable {
+			order = append(order, idx)
+		} else {
+			order = append(order, len(levels)-1-idx)
+		}
+	}
+
+	for _, idx := range order {
+		value, ok := values[ValueKey(levels[idx].ObjectType, levels[idx].ObjectId)]
+		if !ok {
+			continue
+		}
+		level := levels[idx]
+		source := SourceHierarchy
+		if idx == 0 {
+			source = SourceObject
+		}
+
+		return Evaluation{Value: &value, Source: source, Level: &level}
+	}
+
+	if definition.DefaultValue != nil {
+		return Evaluation{Value: definition.DefaultValue, Source: SourceDefault}
+	}
+
+	return Evaluation{Source: SourceNone}
+}
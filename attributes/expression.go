@@ -0,0 +1,740 @@
+package attributes
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// MaxExpressionLength and maxExpressionDepth keep expressions of computed attributes small enough to be
+// evaluated on every read, MaxResultLength limits the strings an expression builds while it is evaluated
+const (
+	MaxExpressionLength = 1024
+	maxExpressionDepth  = 32
+	MaxResultLength     = 64 * 1024
+)
+
+var (
+	errDivisionByZero = errors.New("division by zero")
+	errNotFinite      = errors.New("the result is not a finite number")
+	errResultTooLong  = fmt.Errorf("the result is longer than %d characters", MaxResultLength)
+)
+
+// Expression is a parsed expression of a computed attribute. It can only read the values of other attributes
+// of the same object and call the built-in functions, nothing else is reachable from an expression.
+type Expression struct {
+	source     string
+	root       expressionNode
+	References []string
+}
+
+// ValueLookup returns the typed value of a referenced attribute, nil if the attribute has no value
+type ValueLookup func(name string) (interface{}, error)
+
+// ParseExpression parses an expression like gross * (1 - discount). Identifiers reference other attributes by name.
+func ParseExpression(source string) (*Expression, error) {
+	if len(source) > MaxExpressionLength {
+		return nil, fmt.Errorf("the expression is longer than %d characters", MaxExpressionLength)
+	}
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+	parser := expressionParser{tokens: tokens, references: make(map[string]bool)}
+	root, err := parser.parseExpression(0, 0)
+	if err != nil {
+		return nil, err
+	}
+	if parser.peek().kind != tokenEnd {
+		return nil, fmt.Errorf("unexpected %v at position %d", parser.peek().text, parser.peek().position)
+	}
+
+	expression := Expression{source: source, root: root, References: make([]string, 0, len(parser.references))}
+	for _, token := range tokens {
+		if token.kind == tokenIdentifier && parser.references[token.text] {
+			expression.References = append(expression.References, token.text)
+			delete(parser.references, token.text)
+		}
+	}
+
+	return &expression, nil
+}
+
+// Evaluate computes the value of the expression, values of referenced attributes are read with lookup
+func (expression Expression) Evaluate(lookup ValueLookup) (interface{}, error) {
+	return checkResult(expression.root.evaluate(lookup))
+}
+
+func (expression Expression) String() string {
+	return expression.source
+}
+
+// FormatValue converts the result of an expression into the string representation of attribute values
+func FormatValue(value interface{}) *string {
+	var formatted string
+	switch typed := value.(type) {
+	case nil:
+		return nil
+	case float64:
+		formatted = strconv.FormatFloat(typed, 'f', -1, 64)
+	case bool:
+		formatted = strconv.FormatBool(typed)
+	default:
+		formatted = fmt.Sprintf("%v", typed)
+	}
+
+	return &formatted
+}
+
+// TypedValue converts an attribute value into the type used in expressions according to the datatype of its attribute
+func TypedValue(datatype string, value *string) interface{} {
+	if value == nil {
+		return nil
+	}
+	switch NormalizeDatatype(datatype) {
+	case DatatypeInteger, DatatypeDecimal:
+		if number, err := strconv.ParseFloat(*value, 64); err == nil && !math.IsNaN(number) && !math.IsInf(number, 0) {
+			return number
+		}
+	case DatatypeBoolean:
+		if boolean, err := strconv.ParseBool(*value); err == nil {
+			return boolean
+		}
+	}
+
+	return *value
+}
+
+const (
+	tokenEnd = iota
+	tokenNumber
+	tokenString
+	tokenIdentifier
+	tokenOperator
+)
+
+type token struct {
+	kind     int
+	text     string
+	position int
+}
+
+var operators = []string{"==", "!=", "<=", ">=", "&&", "||", "+", "-", "*", "/", "%", "<", ">", "!", "(", ")", ","}
+
+func tokenize(source string) ([]token, error) {
+	tokens := make([]token, 0)
+	runes := []rune(source)
+	for position := 0; position < len(runes); {
+		current := runes[position]
+		switch {
+		case unicode.IsSpace(current):
+			position++
+		case unicode.IsDigit(current) || (current == '.' && position+1 < len(runes) && unicode.IsDigit(runes[position+1])):
+			start := position
+			for position < len(runes) && (unicode.IsDigit(runes[position]) || runes[position] == '.') {
+				position++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: string(runes[start:position]), position: start})
+		case current == '"' || current == '\'':
+			start := position
+			var text strings.Builder
+			position++
+			for position < len(runes) && runes[position] != current {
+				if runes[position] == '\\' && position+1 < len(runes) {
+					position++
+				}
+				text.WriteRune(runes[position])
+				position++
+			}
+			if position >= len(runes) {
+				return nil, fmt.Errorf("unterminated string starting at position %d", start)
+			}
+			position++
+			tokens = append(tokens, token{kind: tokenString, text: text.String(), position: start})
+		case unicode.IsLetter(current) || current == '_':
+			start := position
+			for position < len(runes) && (unicode.IsLetter(runes[position]) || unicode.IsDigit(runes[position]) ||
+				runes[position] == '_' || runes[position] == '.') {
+				position++
+			}
+			tokens = append(tokens, token{kind: tokenIdentifier, text: string(runes[start:position]), position: start})
+		default:
+			matched := false
+			for _, operator := range operators {
+				if strings.HasPrefix(string(runes[position:]), operator) {
+					tokens = append(tokens, token{kind: tokenOperator, text: operator, position: position})
+					position += len([]rune(operator))
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character %q at position %d", current, position)
+			}
+		}
+	}
+
+	return append(tokens, token{kind: tokenEnd, text: "end of expression", position: len(runes)}), nil
+}
+
+var binaryPrecedence = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3,
+	"<": 4, "<=": 4, ">": 4, ">=": 4,
+	"+": 5, "-": 5,
+	"*": 6, "/": 6, "%": 6,
+}
+
+type expressionParser struct {
+	tokens     []token
+	position   int
+	references map[string]bool
+}
+
+func (parser *expressionParser) peek() token {
+	return parser.tokens[parser.position]
+}
+
+func (parser *expressionParser) next() token {
+	current := parser.tokens[parser.position]
+	if current.kind != tokenEnd {
+		parser.position++
+	}
+
+	return current
+}
+
+func (parser *expressionParser) expect(text string) error {
+	current := parser.next()
+	if current.kind != tokenOperator || current.text != text {
+		return fmt.Errorf("expected %v but found %v at position %d", text, current.text, current.position)
+	}
+
+	return nil
+}
+
+func (parser *expressionParser) parseExpression(minPrecedence, depth int) (expressionNode, error) {
+	if depth > maxExpressionDepth {
+		return nil, fmt.Errorf("the expression is nested deeper than %d levels", maxExpressionDepth)
+	}
+	left, err := parser.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		current := parser.peek()
+		precedence, ok := binaryPrecedence[current.text]
+		if current.kind != tokenOperator || !ok || precedence <= minPrecedence {
+			return left, nil
+		}
+		parser.next()
+		right, err := parser.parseExpression(precedence, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{operator: current.text, left: left, right: right}
+	}
+}
+
+func (parser *expressionParser) parseUnary(depth int) (expressionNode, error) {
+	if depth > maxExpressionDepth {
+		return nil, fmt.Errorf("the expression is nested deeper than %d levels", maxExpressionDepth)
+	}
+	current := parser.peek()
+	if current.kind == tokenOperator && (current.text == "-" || current.text == "!") {
+		parser.next()
+		operand, err := parser.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return unaryNode{operator: current.text, operand: operand}, nil
+	}
+
+	return parser.parsePrimary(depth)
+}
+
+func (parser *expressionParser) parsePrimary(depth int) (expressionNode, error) {
+	current := parser.next()
+	switch current.kind {
+	case tokenNumber:
+		number, err := strconv.ParseFloat(current.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %v at position %d", current.text, current.position)
+		}
+		return literalNode{value: number}, nil
+	case tokenString:
+		return literalNode{value: current.text}, nil
+	case tokenIdentifier:
+		switch current.text {
+		case "true":
+			return literalNode{value: true}, nil
+		case "false":
+			return literalNode{value: false}, nil
+		case "null":
+			return literalNode{value: nil}, nil
+		}
+		if parser.peek().kind == tokenOperator && parser.peek().text == "(" {
+			return parser.parseCall(current, depth)
+		}
+		parser.references[current.text] = true
+		return referenceNode{name: current.text}, nil
+	case tokenOperator:
+		if current.text == "(" {
+			inner, err := parser.parseExpression(0, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			return inner, parser.expect(")")
+		}
+	}
+
+	return nil, fmt.Errorf("unexpected %v at position %d", current.text, current.position)
+}
+
+func (parser *expressionParser) parseCall(name token, depth int) (expressionNode, error) {
+	function, ok := functions[strings.ToLower(name.text)]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %v at position %d", name.text, name.position)
+	}
+	if err := parser.expect("("); err != nil {
+		return nil, err
+	}
+	arguments := make([]expressionNode, 0)
+	if parser.peek().kind != tokenOperator || parser.peek().text != ")" {
+		for {
+			argument, err := parser.parseExpression(0, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			arguments = append(arguments, argument)
+			if parser.peek().kind == tokenOperator && parser.peek().text == "," {
+				parser.next()
+				continue
+			}
+			break
+		}
+	}
+	if err := parser.expect(")"); err != nil {
+		return nil, err
+	}
+	if len(arguments) < function.minArguments || (function.maxArguments >= 0 && len(arguments) > function.maxArguments) {
+		return nil, fmt.Errorf("wrong number of arguments for function %v at position %d", name.text, name.position)
+	}
+
+	return callNode{name: strings.ToLower(name.text), function: function, arguments: arguments}, nil
+}
+
+type expressionNode interface {
+	evaluate(lookup ValueLookup) (interface{}, error)
+}
+
+type literalNode struct {
+	value interface{}
+}
+
+func (node literalNode) evaluate(lookup ValueLookup) (interface{}, error) {
+	return node.value, nil
+}
+
+type referenceNode struct {
+	name string
+}
+
+func (node referenceNode) evaluate(lookup ValueLookup) (interface{}, error) {
+	return lookup(node.name)
+}
+
+type unaryNode struct {
+	operator string
+	operand  expressionNode
+}
+
+func (node unaryNode) evaluate(lookup ValueLookup) (interface{}, error) {
+	value, err := node.operand.evaluate(lookup)
+	if err != nil {
+		return nil, err
+	}
+	if node.operator == "!" {
+		return !truthy(value), nil
+	}
+	if value == nil {
+		return nil, nil
+	}
+	number, err := toNumber(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return -number, nil
+}
+
+type binaryNode struct {
+	operator string
+	left     expressionNode
+	right    expressionNode
+}
+
+func (node binaryNode) evaluate(lookup ValueLookup) (interface{}, error) {
+	return checkResult(node.apply(lookup))
+}
+
+func (node binaryNode) apply(lookup ValueLookup) (interface{}, error) {
+	left, err := node.left.evaluate(lookup)
+	if err != nil {
+		return nil, err
+	}
+	switch node.operator {
+	case "&&":
+		if !truthy(left) {
+			return false, nil
+		}
+		right, err := node.right.evaluate(lookup)
+		return truthy(right), err
+	case "||":
+		if truthy(left) {
+			return true, nil
+		}
+		right, err := node.right.evaluate(lookup)
+		return truthy(right), err
+	}
+
+	right, err := node.right.evaluate(lookup)
+	if err != nil {
+		return nil, err
+	}
+	switch node.operator {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "<", "<=", ">", ">=":
+		return compare(node.operator, left, right)
+	}
+
+	if left == nil || right == nil {
+		return nil, nil
+	}
+	_, leftIsString := left.(string)
+	_, rightIsString := right.(string)
+	if node.operator == "+" && (leftIsString || rightIsString) {
+		leftString, rightString := toString(left), toString(right)
+		if len(leftString)+len(rightString) > MaxResultLength {
+			return nil, errResultTooLong
+		}
+		return leftString + rightString, nil
+	}
+	leftNumber, err := toNumber(left)
+	if err != nil {
+		return nil, err
+	}
+	rightNumber, err := toNumber(right)
+	if err != nil {
+		return nil, err
+	}
+	switch node.operator {
+	case "+":
+		return leftNumber + rightNumber, nil
+	case "-":
+		return leftNumber - rightNumber, nil
+	case "*":
+		return leftNumber * rightNumber, nil
+	case "/":
+		if rightNumber == 0 {
+			return nil, errDivisionByZero
+		}
+		return leftNumber / rightNumber, nil
+	case "%":
+		if rightNumber == 0 {
+			return nil, errDivisionByZero
+		}
+		return math.Mod(leftNumber, rightNumber), nil
+	}
+
+	return nil, fmt.Errorf("unknown operator %v", node.operator)
+}
+
+type callNode struct {
+	name      string
+	function  expressionFunction
+	arguments []expressionNode
+}
+
+func (node callNode) evaluate(lookup ValueLookup) (interface{}, error) {
+	if node.name == "if" {
+		condition, err := node.arguments[0].evaluate(lookup)
+		if err != nil {
+			return nil, err
+		}
+		if truthy(condition) {
+			return node.arguments[1].evaluate(lookup)
+		}
+		return node.arguments[2].evaluate(lookup)
+	}
+
+	arguments := make([]interface{}, 0, len(node.arguments))
+	for _, argument := range node.arguments {
+		value, err := argument.evaluate(lookup)
+		if err != nil {
+			return nil, err
+		}
+		arguments = append(arguments, value)
+	}
+
+	return checkResult(node.function.call(arguments))
+}
+
+type expressionFunction struct {
+	minArguments int
+	// maxArguments is -1 for functions with a variable number of arguments
+	maxArguments int
+	call         func(arguments []interface{}) (interface{}, error)
+}
+
+var functions = map[string]expressionFunction{
+	// if is evaluated lazily by callNode
+	"if": {minArguments: 3, maxArguments: 3},
+	"coalesce": {minArguments: 1, maxArguments: -1, call: func(arguments []interface{}) (interface{}, error) {
+		for _, argument := range arguments {
+			if argument != nil {
+				return argument, nil
+			}
+		}
+		return nil, nil
+	}},
+	"concat": {minArguments: 1, maxArguments: -1, call: func(arguments []interface{}) (interface{}, error) {
+		parts := make([]string, 0, len(arguments))
+		length := 0
+		for _, argument := range arguments {
+			part := toString(argument)
+			if length += len(part); length > MaxResultLength {
+				return nil, errResultTooLong
+			}
+			parts = append(parts, part)
+		}
+		return strings.Join(parts, ""), nil
+	}},
+	"upper": stringFunction(strings.ToUpper),
+	"lower": stringFunction(strings.ToLower),
+	"trim":  stringFunction(strings.TrimSpace),
+	"length": {minArguments: 1, maxArguments: 1, call: func(arguments []interface{}) (interface{}, error) {
+		return float64(len([]rune(toString(arguments[0])))), nil
+	}},
+	"contains": {minArguments: 2, maxArguments: 2, call: func(arguments []interface{}) (interface{}, error) {
+		return strings.Contains(toString(arguments[0]), toString(arguments[1])), nil
+	}},
+	"replace": {minArguments: 3, maxArguments: 3, call: func(arguments []interface{}) (interface{}, error) {
+		text, old, replacement := toString(arguments[0]), toString(arguments[1]), toString(arguments[2])
+		// the length is checked before replacing, nested replacements would grow exponentially otherwise
+		count := strings.Count(text, old)
+		if len(text)+count*(len(replacement)-len(old)) > MaxResultLength {
+			return nil, errResultTooLong
+		}
+		return strings.ReplaceAll(text, old, replacement), nil
+	}},
+	"substring": {minArguments: 2, maxArguments: 3, call: func(arguments []interface{}) (interface{}, error) {
+		runes := []rune(toString(arguments[0]))
+		start, err := toNumber(arguments[1])
+		if err != nil {
+			return nil, err
+		}
+		end := float64(len(runes))
+		if len(arguments) == 3 {
+			length, err := toNumber(arguments[2])
+			if err != nil {
+				return nil, err
+			}
+			end = start + length
+		}
+		from := int(math.Max(0, math.Min(start, float64(len(runes)))))
+		to := int(math.Max(float64(from), math.Min(end, float64(len(runes)))))
+		return string(runes[from:to]), nil
+	}},
+	"round": {minArguments: 1, maxArguments: 2, call: func(arguments []interface{}) (interface{}, error) {
+		if arguments[0] == nil {
+			return nil, nil
+		}
+		number, err := toNumber(arguments[0])
+		if err != nil {
+			return nil, err
+		}
+		digits := 0.0
+		if len(arguments) == 2 {
+			if digits, err = toNumber(arguments[1]); err != nil {
+				return nil, err
+			}
+		}
+		factor := math.Pow(10, math.Trunc(digits))
+		return math.Round(number*factor) / factor, nil
+	}},
+	"floor": numberFunction(math.Floor),
+	"ceil":  numberFunction(math.Ceil),
+	"abs":   numberFunction(math.Abs),
+	"min": {minArguments: 1, maxArguments: -1, call: func(arguments []interface{}) (interface{}, error) {
+		return reduceNumbers(arguments, math.Min)
+	}},
+	"max": {minArguments: 1, maxArguments: -1, call: func(arguments []interface{}) (interface{}, error) {
+		return reduceNumbers(arguments, math.Max)
+	}},
+	"number": {minArguments: 1, maxArguments: 1, call: func(arguments []interface{}) (interface{}, error) {
+		if arguments[0] == nil {
+			return nil, nil
+		}
+		return toNumber(arguments[0])
+	}},
+	"string": {minArguments: 1, maxArguments: 1, call: func(arguments []interface{}) (interface{}, error) {
+		return toString(arguments[0]), nil
+	}},
+}
+
+// checkResult rejects strings longer than MaxResultLength and numbers that are not finite, they could neither
+// be stored nor compared
+func checkResult(value interface{}, err error) (interface{}, error) {
+	if err != nil {
+		return nil, err
+	}
+	switch typed := value.(type) {
+	case float64:
+		if math.IsNaN(typed) || math.IsInf(typed, 0) {
+			return nil, errNotFinite
+		}
+	case string:
+		if len(typed) > MaxResultLength {
+			return nil, errResultTooLong
+		}
+	}
+
+	return value, nil
+}
+
+func stringFunction(function func(string) string) expressionFunction {
+	return expressionFunction{minArguments: 1, maxArguments: 1, call: func(arguments []interface{}) (interface{}, error) {
+		if arguments[0] == nil {
+			return nil, nil
+		}
+		return function(toString(arguments[0])), nil
+	}}
+}
+
+func numberFunction(function func(float64) float64) expressionFunction {
+	return expressionFunction{minArguments: 1, maxArguments: 1, call: func(arguments []interface{}) (interface{}, error) {
+		if arguments[0] == nil {
+			return nil, nil
+		}
+		number, err := toNumber(arguments[0])
+		if err != nil {
+			return nil, err
+		}
+		return function(number), nil
+	}}
+}
+
+func reduceNumbers(arguments []interface{}, reduce func(float64, float64) float64) (interface{}, error) {
+	var result interface{}
+	for _, argument := range arguments {
+		if argument == nil {
+			continue
+		}
+		number, err := toNumber(argument)
+		if err != nil {
+			return nil, err
+		}
+		if result == nil {
+			result = number
+			continue
+		}
+		result = reduce(result.(float64), number)
+	}
+
+	return result, nil
+}
+
+func truthy(value interface{}) bool {
+	switch typed := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return typed
+	case float64:
+		return typed != 0
+	case string:
+		return len(typed) > 0
+	}
+
+	return true
+}
+
+func toNumber(value interface{}) (float64, error) {
+	switch typed := value.(type) {
+	case float64:
+		return typed, nil
+	case bool:
+		if typed {
+			return 1, nil
+		}
+		return 0, nil
+	case string:
+		number, err := strconv.ParseFloat(strings.TrimSpace(typed), 64)
+		if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+			return 0, fmt.Errorf("%q is not a number", typed)
+		}
+		return number, nil
+	}
+
+	return 0, fmt.Errorf("%v is not a number", value)
+}
+
+func toString(value interface{}) string {
+	formatted := FormatValue(value)
+	if formatted == nil {
+		return ""
+	}
+
+	return *formatted
+}
+
+func equal(left, right interface{}) bool {
+	if left == nil || right == nil {
+		return left == nil && right == nil
+	}
+	leftNumber, leftErr := toNumber(left)
+	rightNumber, rightErr := toNumber(right)
+	if leftErr == nil && rightErr == nil {
+		return leftNumber == rightNumber
+	}
+
+	return toString(left) == toString(right)
+}
+
+func compare(operator string, left, right interface{}) (interface{}, error) {
+	if left == nil || right == nil {
+		return false, nil
+	}
+	var result int
+	leftNumber, leftErr := toNumber(left)
+	rightNumber, rightErr := toNumber(right)
+	if leftErr == nil && rightErr == nil {
+		switch {
+		case leftNumber < rightNumber:
+			result = -1
+		case leftNumber > rightNumber:
+			result = 1
+		}
+	} else {
+		result = strings.Compare(toString(left), toString(right))
+	}
+
+	switch operator {
+	case "<":
+		return result < 0, nil
+	case "<=":
+		return result <= 0, nil
+	case ">":
+		return result > 0, nil
+	}
+
+	return result >= 0, nil
+}
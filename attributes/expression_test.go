@@ -0,0 +1,186 @@
+package attributes
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func lookupFrom(values map[string]interface{}) ValueLookup {
+	return func(name string) (interface{}, error) {
+		value, ok := values[name]
+		if !ok {
+			return nil, errors.New("unknown attribute " + name)
+		}
+		return value, nil
+	}
+}
+
+func evaluate(t *testing.T, source string, values map[string]interface{}) (interface{}, error) {
+	t.Helper()
+	expression, err := ParseExpression(source)
+	if err != nil {
+		t.Fatalf("ParseExpression(%q) failed: %v", source, err)
+	}
+
+	return expression.Evaluate(lookupFrom(values))
+}
+
+func TestExpressionEvaluate(t *testing.T) {
+	values := map[string]interface{}{
+		"gross":      100.0,
+		"discount":   0.25,
+		"name":       "  Widget ",
+		"active":     true,
+		"empty":      nil,
+		"order.size": 3.0,
+	}
+	tests := []struct {
+		name       string
+		expression string
+		want       interface{}
+	}{
+		{"multiplication before addition", "1 + 2 * 3", 7.0},
+		{"parentheses", "(1 + 2) * 3", 9.0},
+		{"left associative subtraction", "10 - 4 - 3", 3.0},
+		{"left associative division", "24 / 4 / 2", 3.0},
+		{"modulo", "10 % 4", 2.0},
+		{"unary minus", "-2 * -3", 6.0},
+		{"comparison before equality", "1 < 2 == true", true},
+		{"and before or", "false && false || true", true},
+		{"not", "!active", false},
+		{"references", "gross * (1 - discount)", 75.0},
+		{"dotted reference", "order.size * 2", 6.0},
+		{"string concatenation", "'a' + 1", "a1"},
+		{"upper", "upper(trim(name))", "WIDGET"},
+		{"lower", "lower('ABC')", "abc"},
+		{"length counts runes", "length('äöü')", 3.0},
+		{"contains", "contains(name, 'dg')", true},
+		{"replace", "replace('a-b-c', '-', '+')", "a+b+c"},
+		{"substring with length", "substring('abcdef', 1, 3)", "bcd"},
+		{"substring out of range", "substring('abc', 5)", ""},
+		{"concat", "concat('a', 1, true)", "a1true"},
+		{"escaped quote", `'it\'s'`, "it's"},
+		{"if true", "if(gross > 50, 'high', 'low')", "high"},
+		{"if false", "if(gross > 500, 'high', 'low')", "low"},
+		{"if is lazy", "if(true, 1, 1 / 0)", 1.0},
+		{"coalesce", "coalesce(empty, 'fallback')", "fallback"},
+		{"round", "round(2.345, 2)", 2.35},
+		{"min", "min(3, empty, 1, 2)", 1.0},
+		{"max", "max(3, 1, 2)", 3.0},
+		{"number from string", "number('42') + 1", 43.0},
+		{"null propagates through arithmetic", "empty + 1", nil},
+		{"null equals null", "empty == null", true},
+		{"numeric string equality", "'1.0' == 1", true},
+		{"string comparison", "'abc' < 'abd'", true},
+		{"short circuit and", "false && (1 / 0)", false},
+		{"short circuit or", "true || (1 / 0)", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := evaluate(t, test.expression, values)
+			if err != nil {
+				t.Fatalf("Evaluate(%q) failed: %v", test.expression, err)
+			}
+			if got != test.want {
+				t.Fatalf("Evaluate(%q) = %#v, want %#v", test.expression, got, test.want)
+			}
+		})
+	}
+}
+
+func TestExpressionEvaluateErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		err        string
+	}{
+		{"division by zero", "1 / 0", "division by zero"},
+		{"modulo by zero", "5 % (2 - 2)", "division by zero"},
+		{"string is not a number", "'abc' * 2", "is not a number"},
+		{"NaN is not a number", "'NaN' * 2", "is not a number"},
+		{"Inf is not a number", "number('Inf')", "is not a number"},
+		{"unknown attribute", "missing + 1", "unknown attribute missing"},
+		{"overflow", "big * 10", "not a finite number"},
+		{"round overflows", "round(big, 10)", "not a finite number"},
+		{"round gives NaN", "round(big, 400)", "not a finite number"},
+		{"nested replace", nestedReplace(6), "longer than"},
+		{"concat too long", "concat(text, text)", "longer than"},
+		{"plus too long", "text + text", "longer than"},
+		{"replace too long", "replace(text, 'a', 'aa')", "longer than"},
+	}
+	values := map[string]interface{}{"text": strings.Repeat("a", MaxResultLength/2+1), "big": 1e308}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := evaluate(t, test.expression, values)
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("Evaluate(%q) error = %v, want an error containing %q", test.expression, err, test.err)
+			}
+		})
+	}
+}
+
+func TestParseExpressionErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		err        string
+	}{
+		{"empty", "", "unexpected end of expression"},
+		{"missing operand", "1 +", "unexpected end of expression"},
+		{"missing closing parenthesis", "(1 + 2", "expected )"},
+		{"trailing token", "1 2", "unexpected 2"},
+		{"unterminated string", "'abc", "unterminated string"},
+		{"unexpected character", "1 $ 2", "unexpected character"},
+		{"invalid number", "1.2.3", "invalid number"},
+		{"unknown function", "exec('rm')", "unknown function exec"},
+		{"too few arguments", "if(true, 1)", "wrong number of arguments"},
+		{"too many arguments", "upper('a', 'b')", "wrong number of arguments"},
+		{"too long", "1" + strings.Repeat(" + 1", MaxExpressionLength/4), "longer than"},
+		{"nested too deep", strings.Repeat("(", maxExpressionDepth+1) + "1" + strings.Repeat(")", maxExpressionDepth+1), "nested deeper"},
+		{"unary chain too deep", strings.Repeat("-", maxExpressionDepth+2) + "1", "nested deeper"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseExpression(test.expression)
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("ParseExpression(%q) error = %v, want an error containing %q", test.expression, err, test.err)
+			}
+		})
+	}
+}
+
+func TestParseExpressionLimitsAllowBoundary(t *testing.T) {
+	nested := strings.Repeat("(", maxExpressionDepth-1) + "1" + strings.Repeat(")", maxExpressionDepth-1)
+	if _, err := ParseExpression(nested); err != nil {
+		t.Fatalf("ParseExpression of %d nested parentheses failed: %v", maxExpressionDepth-1, err)
+	}
+	long := "1" + strings.Repeat("+1", (MaxExpressionLength-1)/2)
+	if len(long) > MaxExpressionLength {
+		t.Fatalf("test expression is %d characters long", len(long))
+	}
+	if _, err := ParseExpression(long); err != nil {
+		t.Fatalf("ParseExpression of %d characters failed: %v", len(long), err)
+	}
+}
+
+func TestParseExpressionReferences(t *testing.T) {
+	expression, err := ParseExpression("if(b > a, concat(a, b), upper(c)) + a")
+	if err != nil {
+		t.Fatalf("ParseExpression failed: %v", err)
+	}
+	want := []string{"b", "a", "c"}
+	if strings.Join(expression.References, ",") != strings.Join(want, ",") {
+		t.Fatalf("References = %v, want %v", expression.References, want)
+	}
+}
+
+// nestedReplace builds replacements that multiply the length of a string by ten with every level
+func nestedReplace(levels int) string {
+	expression := "'aaaaaaaaaa'"
+	for level := 0; level < levels; level++ {
+		expression = "replace(" + expression + ", 'a', 'aaaaaaaaaa')"
+	}
+
+	return expression
+}
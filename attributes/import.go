@@ -0,0 +1,85 @@
+package attributes
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"orion.misc/structs"
+	"strings"
+)
+
+const (
+	ImportValid     = "VALID"
+	ImportInvalid   = "INVALID"
+	ImportApplied   = "APPLIED"
+	ImportUnchanged = "UNCHANGED"
+	ImportFailed    = "FAILED"
+)
+
+const ErrorDuplicateValue = "DUPLICATE_VALUE"
+
+// MultiValueSeparator separates the values of multi-valued attributes within a single CSV cell
+const MultiValueSeparator = "|"
+
+var importColumns = []string{"object_type", "object_id", "attribute", "value"}
+
+// ParseImportRows reads the CSV rows of an import. Every row needs the columns object type, object id, attribute
+// name or id and value, a first row that names these columns is skipped. Rows with a wrong number of columns are
+// returned as invalid, only data that is no CSV at all is an error.
+func ParseImportRows(data string) ([]structs.AttributeImportRow, error) {
+	reader := csv.NewReader(strings.NewReader(strings.TrimPrefix(data, "\ufeff")))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	rows := make([]structs.AttributeImportRow, 0)
+	for number := 1; ; number++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if number == 1 && isImportHeader(record) {
+			continue
+		}
+		row := structs.AttributeImportRow{Row: number, Status: ImportValid, ValidationErrors: make([]structs.AttributeValidationError, 0)}
+		if len(record) != len(importColumns) {
+			message := fmt.Sprintf("the row has %d instead of %d columns", len(record), len(importColumns))
+			row.Status = ImportInvalid
+			row.Message = &message
+			rows = append(rows, row)
+			continue
+		}
+		row.ObjectType = strings.TrimSpace(record[0])
+		row.ObjectId = strings.TrimSpace(record[1])
+		row.Attribute = strings.TrimSpace(record[2])
+		row.Value = record[3]
+		if len(row.ObjectType) == 0 || len(row.ObjectId) == 0 || len(row.Attribute) == 0 {
+			message := "object type, object id and attribute must not be empty"
+			row.Status = ImportInvalid
+			row.Message = &message
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// ImportValue creates the value of a row for its attribute definition
+func ImportValue(definition structs.AttributeDefinition, row structs.AttributeImportRow) structs.AttributeValue {
+	return NewValue(definition, row.ObjectType, row.ObjectId, row.Value)
+}
+
+func isImportHeader(record []string) bool {
+	if len(record) != len(importColumns) {
+		return false
+	}
+	for idx, column := range importColumns {
+		if !strings.EqualFold(strings.TrimSpace(record[idx]), column) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,103 @@
+package attributes
+
+import (
+	"fmt"
+	"orion.misc/structs"
+	"sort"
+)
+
+// AppliesTo reports whether an attribute group is used for an object type
+func AppliesTo(group structs.AttributeGroup, objectType string) bool {
+	return len(group.ObjectTypes) == 0 || contains(group.ObjectTypes, objectType)
+}
+
+// CheckAttributeGroups makes sure that the members of the groups exist and that no attribute is listed twice
+// within the same group
+func CheckAttributeGroups(groups []structs.AttributeGroup, definitions []structs.AttributeDefinition) error {
+	known := make(map[string]bool, len(definitions))
+	for _, definition := range definitions {
+		if definition.ID != nil {
+			known[definition.ID.Hex()] = true
+		}
+	}
+	for _, group := range groups {
+		members := make(map[string]bool, len(group.Attributes))
+		for _, attributeId := range group.Attributes {
+			if !known[attributeId] {
+				return fmt.Errorf("the attribute group %v contains the unknown attribute %v", group.Info.Name, attributeId)
+			}
+			if members[attributeId] {
+				return fmt.Errorf("the attribute group %v contains the attribute %v more than once", group.Info.Name, attributeId)
+			}
+			members[attributeId] = true
+		}
+	}
+
+	return nil
+}
+
+// Layout arranges the attributes that can be set on an object type in the groups that apply to it, ordered by their
+// display order. Attributes that are not allowed for the object type are left out, attributes that are not member of
+// any of the groups are returned as ungrouped in the order of their names.
+func Layout(objectType string, groups []structs.AttributeGroup, definitions []structs.AttributeDefinition) structs.AttributeLayout {
+	definitionsById := make(map[string]structs.AttributeDefinition, len(definitions))
+	for _, definition := range definitions {
+		if definition.ID != nil && allowedFor(definition, objectType) {
+			definitionsById[definition.ID.Hex()] = definition
+		}
+	}
+
+	applicable := make([]structs.AttributeGroup, 0, len(groups))
+	for _, group := range groups {
+		if AppliesTo(group, objectType) {
+			applicable = append(applicable, group)
+		}
+	}
+	sort.SliceStable(applicable, func(i, j int) bool {
+		if applicable[i].DisplayOrder != applicable[j].DisplayOrder {
+			return applicable[i].DisplayOrder < applicable[j].DisplayOrder
+		}
+		return applicable[i].Info.Name < applicable[j].Info.Name
+	})
+
+	layout := structs.AttributeLayout{
+		ObjectType: objectType,
+		Groups:     make([]structs.AttributeLayoutGroup, 0, len(applicable)),
+		Ungrouped:  make([]structs.AttributeDefinition, 0),
+	}
+	grouped := make(map[string]bool)
+	for _, group := range applicable {
+		layoutGroup := structs.AttributeLayoutGroup{
+			Name:         group.Info.Name,
+			DisplayOrder: group.DisplayOrder,
+			Collapsible:  group.Collapsible,
+			Collapsed:    group.Collapsible && group.Collapsed,
+			Attributes:   make([]structs.AttributeDefinition, 0, len(group.Attributes)),
+		}
+		if group.ID != nil {
+			layoutGroup.GroupId = group.ID.Hex()
+		}
+		for _, attributeId := range group.Attributes {
+			if definition, ok := definitionsById[attributeId]; ok {
+				layoutGroup.Attributes = append(layoutGroup.Attributes, definition)
+				grouped[attributeId] = true
+			}
+		}
+		layout.Groups = append(layout.Groups, layoutGroup)
+	}
+
+	for attributeId, definition := range definitionsById {
+		if !grouped[attributeId] {
+			layout.Ungrouped = append(layout.Ungrouped, definition)
+		}
+	}
+	sort.Slice(layout.Ungrouped, func(i, j int) bool {
+		return layout.Ungrouped[i].Info.Name < layout.Ungrouped[j].Info.Name
+	})
+
+	return layout
+}
+
+func allowedFor(definition structs.AttributeDefinition, objectType string) bool {
+	return len(definition.AllowedObjectTypes) == 0 || contains(definition.AllowedObjectTypes, objectType)
+}
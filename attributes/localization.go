@@ -0,0 +1,96 @@
+package attributes
+
+import (
+	"fmt"
+	"orion.misc/structs"
+	"strings"
+)
+
+// Localize resolves the labels of a definition for a locale. A missing translation falls back from the locale
+// ("de-AT") to its language ("de"), then to the default locale and finally to the name of the definition and
+// the values themselves.
+func Localize(definition structs.AttributeDefinition, locale, defaultLocale string) structs.AttributeLabels {
+	candidates := localeCandidates(locale, defaultLocale)
+	labels := structs.AttributeLabels{
+		Locale:       locale,
+		Name:         definition.Info.Name,
+		Description:  definition.Info.Description,
+		ListOfValues: make([]structs.ListOfValueLabel, 0, len(definition.ListOfValues)),
+	}
+
+	for _, candidate := range candidates {
+		if localization, ok := findLocalization(definition, candidate); ok && len(localization.Name) > 0 {
+			labels.Name = localization.Name
+			break
+		}
+	}
+	for _, candidate := range candidates {
+		if localization, ok := findLocalization(definition, candidate); ok && localization.Description != nil {
+			labels.Description = localization.Description
+			break
+		}
+	}
+	for _, value := range definition.ListOfValues {
+		label := value
+		for _, candidate := range candidates {
+			localization, ok := findLocalization(definition, candidate)
+			if translated, found := localization.ListOfValues[value]; ok && found {
+				label = translated
+				break
+			}
+		}
+		labels.ListOfValues = append(labels.ListOfValues, structs.ListOfValueLabel{Value: value, Label: label})
+	}
+
+	return labels
+}
+
+// CheckLocalizations makes sure that only values of the list of values are translated
+func CheckLocalizations(definitions []structs.AttributeDefinition) error {
+	for _, definition := range definitions {
+		for locale, localization := range definition.Localizations {
+			if len(strings.TrimSpace(locale)) == 0 {
+				return fmt.Errorf("the attribute %v has a localization without locale", definition.Info.Name)
+			}
+			for value := range localization.ListOfValues {
+				if !contains(definition.ListOfValues, value) {
+					return fmt.Errorf("the %v label of attribute %v translates %v which is not in the list of values",
+						locale, definition.Info.Name, value)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func localeCandidates(locale, defaultLocale string) []string {
+	candidates := make([]string, 0, 4)
+	for _, candidate := range []string{locale, language(locale), defaultLocale, language(defaultLocale)} {
+		if len(candidate) > 0 && !contains(candidates, candidate) {
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	return candidates
+}
+
+func language(locale string) string {
+	if idx := strings.IndexAny(locale, "-_"); idx > 0 {
+		return locale[:idx]
+	}
+
+	return locale
+}
+
+// findLocalization looks up a locale ignoring case and the separator between language and region
+func findLocalization(definition structs.AttributeDefinition, locale string) (structs.AttributeLocalization, bool) {
+	normalized := strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+	for key, localization := range definition.Localizations {
+		if strings.ToLower(strings.ReplaceAll(key, "_", "-")) == normalized {
+			return localization, true
+		}
+	}
+
+	return structs.AttributeLocalization{}, false
+}
@@ -0,0 +1,113 @@
+package attributes
+
+import (
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"math"
+	"orion.misc/structs"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SearchEquals = "EQ"
+	SearchRange  = "RANGE"
+	SearchPrefix = "PREFIX"
+	SearchIn     = "IN"
+)
+
+const (
+	CombinatorAnd = "AND"
+	CombinatorOr  = "OR"
+)
+
+// IndexValue converts a value into the type that is stored for searching: numbers for integers and decimals,
+// booleans, milliseconds for dates and timestamps and strings for everything else. Values that don't match
+// their datatype are kept as strings.
+func IndexValue(datatype, value string) interface{} {
+	switch NormalizeDatatype(datatype) {
+	case DatatypeInteger, DatatypeDecimal:
+		if number, err := strconv.ParseFloat(value, 64); err == nil && !math.IsNaN(number) && !math.IsInf(number, 0) {
+			return number
+		}
+	case DatatypeBoolean:
+		if boolean, err := strconv.ParseBool(value); err == nil {
+			return boolean
+		}
+	case DatatypeDate:
+		if date, err := time.Parse(DateLayout, value); err == nil {
+			return date.UnixNano() / int64(time.Millisecond)
+		}
+	case DatatypeTimestamp:
+		if milliseconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return milliseconds
+		}
+		if timestamp, err := time.Parse(time.RFC3339, value); err == nil {
+			return timestamp.UnixNano() / int64(time.Millisecond)
+		}
+	}
+
+	return value
+}
+
+// PredicateFilter builds the filter that matches a stored value of the attribute_values collection against a
+// predicate. Operands are converted like the stored values so that comparisons follow the datatype of the attribute.
+func PredicateFilter(definition structs.AttributeDefinition, predicate structs.AttributeSearchPredicate) (bson.M, error) {
+	filter := bson.M{"attribute_id": predicate.AttributeId}
+	switch strings.ToUpper(predicate.Operator) {
+	case SearchEquals:
+		if predicate.Value == nil {
+			return nil, fmt.Errorf("the predicate on attribute %v needs a value", predicate.AttributeId)
+		}
+		filter["typed_value"] = IndexValue(definition.DataType, *predicate.Value)
+	case SearchRange:
+		if predicate.From == nil && predicate.To == nil {
+			return nil, fmt.Errorf("the range on attribute %v needs at least one bound", predicate.AttributeId)
+		}
+		bounds := bson.M{}
+		if predicate.From != nil {
+			bounds["$gte"] = IndexValue(definition.DataType, *predicate.From)
+		}
+		if predicate.To != nil {
+			bounds["$lte"] = IndexValue(definition.DataType, *predicate.To)
+		}
+		filter["typed_value"] = bounds
+	case SearchPrefix:
+		if predicate.Value == nil {
+			return nil, fmt.Errorf("the predicate on attribute %v needs a value", predicate.AttributeId)
+		}
+		field := "value"
+		if IsMultiValued(definition) {
+			field = "values"
+		}
+		filter[field] = primitive.Regex{Pattern: "^" + regexp.QuoteMeta(*predicate.Value)}
+	case SearchIn:
+		if len(predicate.Values) == 0 {
+			return nil, fmt.Errorf("the predicate on attribute %v needs a list of values", predicate.AttributeId)
+		}
+		values := make(bson.A, 0, len(predicate.Values))
+		for _, value := range predicate.Values {
+			values = append(values, IndexValue(definition.DataType, value))
+		}
+		filter["typed_value"] = bson.M{"$in": values}
+	default:
+		return nil, fmt.Errorf("the operator %v is not supported", predicate.Operator)
+	}
+
+	return filter, nil
+}
+
+// CombinatorOperator returns the query operator that joins the predicates and nested conditions of a condition
+func CombinatorOperator(combinator string) (string, error) {
+	switch strings.ToUpper(combinator) {
+	case CombinatorAnd, "":
+		return "$and", nil
+	case CombinatorOr:
+		return "$or", nil
+	}
+
+	return "", fmt.Errorf("the combinator %v is not supported", combinator)
+}
@@ -0,0 +1,181 @@
+package attributes
+
+import (
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"math"
+	"orion.misc/structs"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DatatypeInteger   = "INTEGER"
+	DatatypeDecimal   = "DECIMAL"
+	DatatypeBoolean   = "BOOLEAN"
+	DatatypeDate      = "DATE"
+	DatatypeTimestamp = "TIMESTAMP"
+	DatatypeString    = "STRING"
+	DatatypeReference = "REFERENCE"
+)
+
+const (
+	ErrorUnknownAttribute     = "UNKNOWN_ATTRIBUTE"
+	ErrorComputedAttribute    = "COMPUTED_ATTRIBUTE"
+	ErrorUnknownDatatype      = "UNKNOWN_DATATYPE"
+	ErrorInvalidValue         = "INVALID_VALUE"
+	ErrorValueOutOfRange      = "VALUE_OUT_OF_RANGE"
+	ErrorValueNotInList       = "VALUE_NOT_IN_LIST_OF_VALUES"
+	ErrorObjectTypeNotAllowed = "OBJECT_TYPE_NOT_ALLOWED"
+)
+
+const DateLayout = "2006-01-02"
+
+// Validate checks a value against the cardinality, the datatype, the numeric range, the list of values and the allowed
+// object types of its definition and returns an error for every check that failed. Every single value of a
+// multi-valued attribute is checked on its own.
+func Validate(definition structs.AttributeDefinition, value structs.AttributeValue) []structs.AttributeValidationError {
+	validationErrors := make([]structs.AttributeValidationError, 0)
+	addError := func(code, message string) {
+		validationErrors = append(validationErrors, NewValidationError(value, code, message))
+	}
+
+	if IsComputed(definition) {
+		addError(ErrorComputedAttribute, "the value of a computed attribute can't be set")
+
+		return validationErrors
+	}
+
+	if len(definition.AllowedObjectTypes) > 0 && !contains(definition.AllowedObjectTypes, value.ObjectType) {
+		addError(ErrorObjectTypeNotAllowed, fmt.Sprintf("the attribute can't be set on objects of type %v", value.ObjectType))
+	}
+
+	values := ValueList(definition, value)
+	if !IsMultiValued(definition) && len(value.Values) > 0 {
+		addError(ErrorMultipleValuesNotAllowed, "the attribute holds a single value only")
+	}
+
+	datatype := NormalizeDatatype(definition.DataType)
+	for _, single := range values {
+		number, err := parseValue(datatype, single)
+		if err != nil {
+			code := ErrorInvalidValue
+			if err == errUnknownDatatype {
+				code = ErrorUnknownDatatype
+			}
+			addError(code, err.Error())
+
+			continue
+		}
+
+		if number != nil {
+			if definition.NumericFrom != nil && *number < *definition.NumericFrom {
+				addError(ErrorValueOutOfRange, fmt.Sprintf("%v is lower than the minimum %v", single, *definition.NumericFrom))
+			}
+			if definition.NumericTo != nil && *number > *definition.NumericTo {
+				addError(ErrorValueOutOfRange, fmt.Sprintf("%v is greater than the maximum %v", single, *definition.NumericTo))
+			}
+		}
+
+		if len(definition.ListOfValues) > 0 && !contains(definition.ListOfValues, single) {
+			addError(ErrorValueNotInList, fmt.Sprintf("%v is not in the list of values", single))
+		}
+	}
+
+	return validationErrors
+}
+
+// NewValidationError creates a validation error for a value
+func NewValidationError(value structs.AttributeValue, code, message string) structs.AttributeValidationError {
+	return structs.AttributeValidationError{
+		AttributeId: value.AttributeId,
+		ObjectType:  value.ObjectType,
+		ObjectId:    value.ObjectId,
+		Value:       value.Value,
+		Code:        code,
+		Message:     message,
+	}
+}
+
+// NormalizeDatatype returns the datatype in upper case, attributes without a datatype are strings
+func NormalizeDatatype(datatype string) string {
+	if len(strings.TrimSpace(datatype)) == 0 {
+		return DatatypeString
+	}
+
+	return strings.ToUpper(strings.TrimSpace(datatype))
+}
+
+// CheckDatatypes makes sure that the datatypes of the definitions are known and that their numeric ranges are valid
+func CheckDatatypes(definitions []structs.AttributeDefinition) error {
+	for _, definition := range definitions {
+		datatype := NormalizeDatatype(definition.DataType)
+		if _, err := parseValue(datatype, ""); err == errUnknownDatatype {
+			return fmt.Errorf("the datatype %v of attribute %v is unknown", definition.DataType, definition.Info.Name)
+		}
+		if definition.NumericFrom != nil && definition.NumericTo != nil && *definition.NumericFrom > *definition.NumericTo {
+			return fmt.Errorf("the minimum %v of attribute %v is greater than its maximum %v", *definition.NumericFrom,
+				definition.Info.Name, *definition.NumericTo)
+		}
+	}
+
+	return nil
+}
+
+var errUnknownDatatype = errors.New("the datatype of the attribute is unknown")
+
+// parseValue checks that the value can be parsed as the datatype. Numeric values are returned so that
+// they can be checked against the numeric range.
+func parseValue(datatype, value string) (*float64, error) {
+	switch datatype {
+	case DatatypeInteger:
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%v is not an integer", value)
+		}
+		number := float64(parsed)
+		return &number, nil
+	case DatatypeDecimal:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+			return nil, fmt.Errorf("%v is not a decimal", value)
+		}
+		return &number, nil
+	case DatatypeBoolean:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return nil, fmt.Errorf("%v is not a boolean", value)
+		}
+	case DatatypeDate:
+		if _, err := time.Parse(DateLayout, value); err != nil {
+			return nil, fmt.Errorf("%v is not a date in the format %v", value, DateLayout)
+		}
+	case DatatypeTimestamp:
+		if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return nil, nil
+		}
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			return nil, fmt.Errorf("%v is neither a timestamp in milliseconds nor in RFC 3339 format", value)
+		}
+	case DatatypeReference:
+		if _, err := primitive.ObjectIDFromHex(value); err != nil {
+			return nil, fmt.Errorf("%v is not a valid object id", value)
+		}
+	case DatatypeString:
+	default:
+		return nil, errUnknownDatatype
+	}
+
+	return nil, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+
+	return false
+}
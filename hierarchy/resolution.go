@@ -0,0 +1,53 @@
+package hierarchy
+
+import (
+	"orion.misc/structs"
+	"sort"
+)
+
+// SortedEntries returns the entries of a hierarchy ordered from the root (the lowest index) to the leaf
+func SortedEntries(hierarchy structs.Hierarchy) []structs.HierarchyEntry {
+	entries := make([]structs.HierarchyEntry, len(hierarchy.Entries))
+	copy(entries, hierarchy.Entries)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Index < entries[j].Index
+	})
+
+	return entries
+}
+
+// Resolve returns the position of an object type in every hierarchy it takes part in. Ancestors are ordered from the
+// direct parent up to the root, descendants from the direct child down to the leaf.
+func Resolve(hierarchies []structs.Hierarchy, objectType string) []structs.HierarchyResolution {
+	resolutions := make([]structs.HierarchyResolution, 0)
+	for _, hierarchy := range hierarchies {
+		entries := SortedEntries(hierarchy)
+		position := -1
+		for idx, entry := range entries {
+			if entry.ObjectType == objectType {
+				position = idx
+				break
+			}
+		}
+		if position < 0 {
+			continue
+		}
+
+		resolution := structs.HierarchyResolution{
+			Name:        hierarchy.Info.Name,
+			Index:       entries[position].Index,
+			Ancestors:   make([]structs.HierarchyEntry, 0, position),
+			Descendants: make([]structs.HierarchyEntry, 0, len(entries)-position-1),
+		}
+		if hierarchy.ID != nil {
+			resolution.HierarchyId = hierarchy.ID.Hex()
+		}
+		for idx := position - 1; idx >= 0; idx-- {
+			resolution.Ancestors = append(resolution.Ancestors, entries[idx])
+		}
+		resolution.Descendants = append(resolution.Descendants, entries[position+1:]...)
+		resolutions = append(resolutions, resolution)
+	}
+
+	return resolutions
+}
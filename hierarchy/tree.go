@@ -0,0 +1,95 @@
+package hierarchy
+
+import (
+	"fmt"
+	"orion.misc/structs"
+	"sort"
+)
+
+// CheckParentType makes sure that the object type of a parent node is above the object type of its child in the
+// hierarchy of object types the tree belongs to, levels in between may be skipped
+func CheckParentType(hierarchy structs.Hierarchy, parentType, childType string) error {
+	resolutions := Resolve([]structs.Hierarchy{hierarchy}, childType)
+	if len(resolutions) == 0 {
+		return fmt.Errorf("the object type %v is not part of the hierarchy %v", childType, hierarchy.Info.Name)
+	}
+	for _, ancestor := range resolutions[0].Ancestors {
+		if ancestor.ObjectType == parentType {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("objects of type %v can't be children of objects of type %v in the hierarchy %v", childType, parentType, hierarchy.Info.Name)
+}
+
+// ChildPath returns the path of a node that is attached below parent, roots have an empty path
+func ChildPath(parent *structs.HierarchyNode) []string {
+	if parent == nil {
+		return []string{}
+	}
+	path := make([]string, 0, len(parent.Path)+1)
+	path = append(path, parent.Path...)
+
+	return append(path, parent.ID.Hex())
+}
+
+// CheckMove makes sure that a node is not moved below itself or one of its descendants
+func CheckMove(node structs.HierarchyNode, newParent *structs.HierarchyNode) error {
+	if newParent == nil {
+		return nil
+	}
+	nodeId := node.ID.Hex()
+	if newParent.ID.Hex() == nodeId {
+		return fmt.Errorf("%v %v can't be its own parent", node.ObjectType, node.ObjectId)
+	}
+	for _, ancestorId := range newParent.Path {
+		if ancestorId == nodeId {
+			return fmt.Errorf("%v %v can't be moved below its descendant %v %v", node.ObjectType, node.ObjectId,
+				newParent.ObjectType, newParent.ObjectId)
+		}
+	}
+
+	return nil
+}
+
+// Rebase replaces the path of a moved node within the path of one of its descendants
+func Rebase(descendant structs.HierarchyNode, oldPath, newPath []string) structs.HierarchyNode {
+	path := make([]string, 0, len(newPath)+len(descendant.Path)-len(oldPath))
+	path = append(path, newPath...)
+	path = append(path, descendant.Path[len(oldPath):]...)
+	descendant.Path = path
+	descendant.Depth = len(path)
+
+	return descendant
+}
+
+// BuildTree arranges the descendants of root into a tree, children are ordered by object type and object id.
+// Descendants deeper than maxDepth levels below the root are left out.
+func BuildTree(root structs.HierarchyNode, descendants []structs.HierarchyNode, maxDepth int) structs.HierarchyTreeNode {
+	children := make(map[string][]structs.HierarchyNode)
+	for _, descendant := range descendants {
+		if descendant.ParentId == nil || descendant.Depth-root.Depth > maxDepth {
+			continue
+		}
+		children[*descendant.ParentId] = append(children[*descendant.ParentId], descendant)
+	}
+
+	var build func(node structs.HierarchyNode) structs.HierarchyTreeNode
+	build = func(node structs.HierarchyNode) structs.HierarchyTreeNode {
+		nodes := children[node.ID.Hex()]
+		sort.Slice(nodes, func(i, j int) bool {
+			if nodes[i].ObjectType != nodes[j].ObjectType {
+				return nodes[i].ObjectType < nodes[j].ObjectType
+			}
+			return nodes[i].ObjectId < nodes[j].ObjectId
+		})
+		treeNode := structs.HierarchyTreeNode{Node: node, Children: make([]structs.HierarchyTreeNode, 0, len(nodes))}
+		for _, child := range nodes {
+			treeNode.Children = append(treeNode.Children, build(child))
+		}
+
+		return treeNode
+	}
+
+	return build(root)
+}
@@ -0,0 +1,139 @@
+package hierarchy
+
+import (
+	"fmt"
+	"orion.misc/structs"
+	"sort"
+)
+
+const (
+	ErrorDuplicateIndex        = "DUPLICATE_INDEX"
+	ErrorNonContiguousIndex    = "NON_CONTIGUOUS_INDEX"
+	ErrorDuplicateObjectType   = "DUPLICATE_OBJECT_TYPE"
+	ErrorUnknownObjectType     = "UNKNOWN_OBJECT_TYPE"
+	ErrorContradictoryOrdering = "CONTRADICTORY_ORDERING"
+)
+
+// Validate checks the updated hierarchies and returns an error for every bad entry. Indexes must be unique and
+// contiguous, an object type may appear only once per hierarchy and must be known unless knownTypes is empty.
+// The order of two object types must be the same in every hierarchy, the stored hierarchies that are not updated
+// are taken into account for that.
+func Validate(updated, stored []structs.Hierarchy, knownTypes map[string]bool) []structs.HierarchyValidationError {
+	validationErrors := make([]structs.HierarchyValidationError, 0)
+	for _, hierarchy := range updated {
+		validationErrors = append(validationErrors, validateEntries(hierarchy, knownTypes)...)
+	}
+
+	return append(validationErrors, validateOrdering(updated, stored)...)
+}
+
+func validateEntries(hierarchy structs.Hierarchy, knownTypes map[string]bool) []structs.HierarchyValidationError {
+	validationErrors := make([]structs.HierarchyValidationError, 0)
+	indexes := make(map[int]int, len(hierarchy.Entries))
+	objectTypes := make(map[string]int, len(hierarchy.Entries))
+	for position, entry := range hierarchy.Entries {
+		if first, ok := indexes[entry.Index]; ok {
+			validationErrors = append(validationErrors, newValidationError(hierarchy, position, ErrorDuplicateIndex,
+				fmt.Sprintf("the index %d is already used by entry %d", entry.Index, first)))
+		} else {
+			indexes[entry.Index] = position
+		}
+		if first, ok := objectTypes[entry.ObjectType]; ok {
+			validationErrors = append(validationErrors, newValidationError(hierarchy, position, ErrorDuplicateObjectType,
+				fmt.Sprintf("the object type %v is already part of the hierarchy in entry %d", entry.ObjectType, first)))
+		} else {
+			objectTypes[entry.ObjectType] = position
+		}
+		if len(knownTypes) > 0 && !knownTypes[entry.ObjectType] {
+			validationErrors = append(validationErrors, newValidationError(hierarchy, position, ErrorUnknownObjectType,
+				fmt.Sprintf("the object type %v is unknown", entry.ObjectType)))
+		}
+	}
+
+	sorted := make([]int, 0, len(indexes))
+	for index := range indexes {
+		sorted = append(sorted, index)
+	}
+	sort.Ints(sorted)
+	for idx := 1; idx < len(sorted); idx++ {
+		if sorted[idx] != sorted[idx-1]+1 {
+			validationErrors = append(validationErrors, newValidationError(hierarchy, indexes[sorted[idx]], ErrorNonContiguousIndex,
+				fmt.Sprintf("the index %d does not follow the index %d", sorted[idx], sorted[idx-1])))
+		}
+	}
+
+	return validationErrors
+}
+
+// validateOrdering makes sure that no updated hierarchy places an object type above another one that is above it
+// in a different hierarchy
+func validateOrdering(updated, stored []structs.Hierarchy) []structs.HierarchyValidationError {
+	updatedIds := make(map[string]bool, len(updated))
+	for _, hierarchy := range updated {
+		if hierarchy.ID != nil && !hierarchy.ID.IsZero() {
+			updatedIds[hierarchy.ID.Hex()] = true
+		}
+	}
+	hierarchies := make([]structs.Hierarchy, 0, len(stored)+len(updated))
+	for _, hierarchy := range stored {
+		if hierarchy.ID == nil || !updatedIds[hierarchy.ID.Hex()] {
+			hierarchies = append(hierarchies, hierarchy)
+		}
+	}
+	firstUpdated := len(hierarchies)
+	hierarchies = append(hierarchies, updated...)
+
+	// above maps an ordered pair of object types to the hierarchies that place the first one above the second one
+	above := make(map[[2]string][]int)
+	for idx, hierarchy := range hierarchies {
+		entries := SortedEntries(hierarchy)
+		for upper := 0; upper < len(entries); upper++ {
+			for lower := upper + 1; lower < len(entries); lower++ {
+				if entries[upper].Index == entries[lower].Index {
+					continue
+				}
+				pair := [2]string{entries[upper].ObjectType, entries[lower].ObjectType}
+				above[pair] = append(above[pair], idx)
+			}
+		}
+	}
+
+	validationErrors := make([]structs.HierarchyValidationError, 0)
+	for idx := firstUpdated; idx < len(hierarchies); idx++ {
+		hierarchy := hierarchies[idx]
+		for position, entry := range hierarchy.Entries {
+			for _, other := range hierarchy.Entries {
+				if other.Index >= entry.Index || other.ObjectType == entry.ObjectType {
+					continue
+				}
+				for _, contradicting := range above[[2]string{entry.ObjectType, other.ObjectType}] {
+					if contradicting == idx {
+						continue
+					}
+					validationErrors = append(validationErrors, newValidationError(hierarchy, position, ErrorContradictoryOrdering,
+						fmt.Sprintf("%v is below %v here but above it in the hierarchy %v", entry.ObjectType, other.ObjectType,
+							hierarchies[contradicting].Info.Name)))
+					break
+				}
+			}
+		}
+	}
+
+	return validationErrors
+}
+
+func newValidationError(hierarchy structs.Hierarchy, position int, code, message string) structs.HierarchyValidationError {
+	validationError := structs.HierarchyValidationError{
+		HierarchyName: hierarchy.Info.Name,
+		Entry:         position,
+		ObjectType:    hierarchy.Entries[position].ObjectType,
+		Index:         hierarchy.Entries[position].Index,
+		Code:          code,
+		Message:       message,
+	}
+	if hierarchy.ID != nil {
+		validationError.HierarchyId = hierarchy.ID.Hex()
+	}
+
+	return validationError
+}
@@ -79,12 +79,78 @@ func main() {
 	saveStateTransitionRulesAction.InitBaseAction(baseAction)
 	getStateTransitionRulesAction := actions.GetStateTransitionRulesAction{MetricsStore: metricsStore}
 	getStateTransitionRulesAction.InitBaseAction(baseAction)
+	validateStateTransitionAction := actions.ValidateStateTransitionAction{MetricsStore: metricsStore}
+	validateStateTransitionAction.InitBaseAction(baseAction)
+	getStateMachineGraphAction := actions.GetStateMachineGraphAction{MetricsStore: metricsStore}
+	getStateMachineGraphAction.InitBaseAction(baseAction)
+	analyzeStateMachinesAction := actions.AnalyzeStateMachinesAction{MetricsStore: metricsStore}
+	analyzeStateMachinesAction.InitBaseAction(baseAction)
+	applyStateTransitionAction := actions.ApplyStateTransitionAction{MetricsStore: metricsStore}
+	applyStateTransitionAction.InitBaseAction(baseAction)
+	getObjectStateAction := actions.GetObjectStateAction{MetricsStore: metricsStore}
+	getObjectStateAction.InitBaseAction(baseAction)
+	publishStateMachineVersionAction := actions.PublishStateMachineVersionAction{MetricsStore: metricsStore}
+	publishStateMachineVersionAction.InitBaseAction(baseAction)
+	getStateMachineVersionsAction := actions.GetStateMachineVersionsAction{MetricsStore: metricsStore}
+	getStateMachineVersionsAction.InitBaseAction(baseAction)
+	simulateStateTransitionsAction := actions.SimulateStateTransitionsAction{MetricsStore: metricsStore}
+	simulateStateTransitionsAction.InitBaseAction(baseAction)
+	evaluateAttributeAction := actions.EvaluateAttributeAction{MetricsStore: metricsStore}
+	evaluateAttributeAction.InitBaseAction(baseAction)
+	setAttributeValueAction := actions.SetAttributeValueAction{MetricsStore: metricsStore}
+	setAttributeValueAction.InitBaseAction(baseAction)
+	getAttributeValuesAction := actions.GetAttributeValuesAction{MetricsStore: metricsStore}
+	getAttributeValuesAction.InitBaseAction(baseAction)
+	deleteAttributeValueAction := actions.DeleteAttributeValueAction{MetricsStore: metricsStore}
+	deleteAttributeValueAction.InitBaseAction(baseAction)
+	getAttributeValueChangeHistoryAction := actions.GetAttributeValueChangeHistoryAction{MetricsStore: metricsStore}
+	getAttributeValueChangeHistoryAction.InitBaseAction(baseAction)
+	getEffectiveListOfValuesAction := actions.GetEffectiveListOfValuesAction{MetricsStore: metricsStore}
+	getEffectiveListOfValuesAction.InitBaseAction(baseAction)
+	searchObjectsByAttributesAction := actions.SearchObjectsByAttributesAction{MetricsStore: metricsStore}
+	searchObjectsByAttributesAction.InitBaseAction(baseAction)
+	saveAttributeGroupsAction := actions.SaveAttributeGroupsAction{MetricsStore: metricsStore}
+	saveAttributeGroupsAction.InitBaseAction(baseAction)
+	getAttributeGroupsAction := actions.GetAttributeGroupsAction{MetricsStore: metricsStore}
+	getAttributeGroupsAction.InitBaseAction(baseAction)
+	deleteAttributeGroupAction := actions.DeleteAttributeGroupAction{MetricsStore: metricsStore}
+	deleteAttributeGroupAction.InitBaseAction(baseAction)
+	getAttributeLayoutAction := actions.GetAttributeLayoutAction{MetricsStore: metricsStore}
+	getAttributeLayoutAction.InitBaseAction(baseAction)
+	importAttributeValuesAction := actions.ImportAttributeValuesAction{MetricsStore: metricsStore}
+	importAttributeValuesAction.InitBaseAction(baseAction)
+	resolveHierarchiesAction := actions.ResolveHierarchiesAction{MetricsStore: metricsStore}
+	resolveHierarchiesAction.InitBaseAction(baseAction)
+	attachHierarchyNodeAction := actions.AttachHierarchyNodeAction{MetricsStore: metricsStore}
+	attachHierarchyNodeAction.InitBaseAction(baseAction)
+	detachHierarchyNodeAction := actions.DetachHierarchyNodeAction{MetricsStore: metricsStore}
+	detachHierarchyNodeAction.InitBaseAction(baseAction)
+	moveHierarchyNodeAction := actions.MoveHierarchyNodeAction{MetricsStore: metricsStore}
+	moveHierarchyNodeAction.InitBaseAction(baseAction)
+	getHierarchySubtreeAction := actions.GetHierarchySubtreeAction{MetricsStore: metricsStore}
+	getHierarchySubtreeAction.InitBaseAction(baseAction)
+	getHierarchyAncestorsAction := actions.GetHierarchyAncestorsAction{MetricsStore: metricsStore}
+	getHierarchyAncestorsAction.InitBaseAction(baseAction)
+	resolveParameterAction := actions.ResolveParameterAction{MetricsStore: metricsStore}
+	resolveParameterAction.InitBaseAction(baseAction)
+	saveUserRolesAction := actions.SaveUserRolesAction{MetricsStore: metricsStore}
+	saveUserRolesAction.InitBaseAction(baseAction)
 
 	services := []micro.Action{&saveStatesAction, &deleteStateAction, &getStatesAction, &defineAttributesAction,
 		&deleteAttributeDefinitionAction, &getAttributeDefinitionsAction, &saveHierarchiesAction,
 		&deleteHierarchyAction, &getHierarchiesAction, &saveParametersAction, &deleteParameterAction,
 		&getParametersAction, &saveCategoriesAction, &getCategoriesAction, &deleteCategoryAction,
-		&saveObjectTypeCustomizationsAction, &getObjectTypeCustomizationsAction, &saveStateTransitionRulesAction, &getStateTransitionRulesAction}
+		&saveObjectTypeCustomizationsAction, &getObjectTypeCustomizationsAction, &saveStateTransitionRulesAction, &getStateTransitionRulesAction,
+		&validateStateTransitionAction, &getStateMachineGraphAction,
+		&analyzeStateMachinesAction, &applyStateTransitionAction, &getObjectStateAction,
+		&publishStateMachineVersionAction, &getStateMachineVersionsAction,
+		&simulateStateTransitionsAction, &evaluateAttributeAction, &setAttributeValueAction,
+		&getAttributeValuesAction, &deleteAttributeValueAction, &getAttributeValueChangeHistoryAction,
+		&getEffectiveListOfValuesAction, &searchObjectsByAttributesAction,
+		&saveAttributeGroupsAction, &getAttributeGroupsAction, &deleteAttributeGroupAction, &getAttributeLayoutAction,
+		&importAttributeValuesAction, &resolveHierarchiesAction, &attachHierarchyNodeAction, &detachHierarchyNodeAction,
+		&moveHierarchyNodeAction, &getHierarchySubtreeAction, &getHierarchyAncestorsAction,
+		&resolveParameterAction, &saveUserRolesAction}
 
 	_ = app.StartApplication(services)
 	app.WriteApplicationInfoFile()
@@ -0,0 +1,265 @@
+package parameters
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Schema is the subset of JSON schema that JSON parameters can be validated against: type, enum, properties,
+// required, additionalProperties (as boolean), items, minimum, maximum, minLength, maxLength, minItems, maxItems
+// and pattern. Schemas with any other keyword except the annotations $schema, title and description are rejected,
+// they would be ignored otherwise.
+type Schema struct {
+	Type                 interface{}        `json:"type"`
+	Enum                 []interface{}      `json:"enum"`
+	Properties           map[string]*Schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties *bool              `json:"additionalProperties"`
+	Items                *Schema            `json:"items"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+	MinLength            *int               `json:"minLength"`
+	MaxLength            *int               `json:"maxLength"`
+	MinItems             *int               `json:"minItems"`
+	MaxItems             *int               `json:"maxItems"`
+	Pattern              *string            `json:"pattern"`
+	pattern              *regexp.Regexp
+}
+
+var schemaTypes = map[string]bool{
+	"object": true, "array": true, "string": true, "number": true, "integer": true, "boolean": true, "null": true,
+}
+
+var schemaKeywords = map[string]bool{
+	"type": true, "enum": true, "properties": true, "required": true, "additionalProperties": true, "items": true,
+	"minimum": true, "maximum": true, "minLength": true, "maxLength": true, "minItems": true, "maxItems": true,
+	"pattern": true, "$schema": true, "title": true, "description": true,
+}
+
+// ParseSchema decodes a JSON schema and makes sure that it only uses supported keywords and that its types and
+// patterns are valid
+func ParseSchema(text string) (*Schema, error) {
+	var document interface{}
+	if err := json.Unmarshal([]byte(text), &document); err != nil {
+		return nil, fmt.Errorf("the schema is no valid JSON: %v", err)
+	}
+	if err := checkKeywords("$", document); err != nil {
+		return nil, err
+	}
+	schema := Schema{}
+	if err := json.Unmarshal([]byte(text), &schema); err != nil {
+		return nil, fmt.Errorf("the schema is no valid JSON schema: %v", err)
+	}
+	if err := schema.compile("$"); err != nil {
+		return nil, err
+	}
+
+	return &schema, nil
+}
+
+// checkKeywords rejects keywords that are not supported in a schema document and in the schemas nested in it
+func checkKeywords(path string, document interface{}) error {
+	fields, ok := document.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("the schema at %v must be an object", path)
+	}
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !schemaKeywords[name] {
+			return fmt.Errorf("the schema keyword %v at %v is not supported", name, path)
+		}
+	}
+	if properties, ok := fields["properties"].(map[string]interface{}); ok {
+		for name, property := range properties {
+			if err := checkKeywords(path+"."+name, property); err != nil {
+				return err
+			}
+		}
+	}
+	if items, ok := fields["items"]; ok && items != nil {
+		return checkKeywords(path+"[]", items)
+	}
+
+	return nil
+}
+
+func (schema *Schema) compile(path string) error {
+	for _, schemaType := range schema.types() {
+		if !schemaTypes[schemaType] {
+			return fmt.Errorf("the schema type %v at %v is unknown", schemaType, path)
+		}
+	}
+	if schema.Type != nil && len(schema.types()) == 0 {
+		return fmt.Errorf("the schema type at %v must be a string or a list of strings", path)
+	}
+	if schema.Pattern != nil {
+		pattern, err := regexp.Compile(*schema.Pattern)
+		if err != nil {
+			return fmt.Errorf("the schema pattern at %v is invalid: %v", path, err)
+		}
+		schema.pattern = pattern
+	}
+	for name, property := range schema.Properties {
+		if property == nil {
+			return fmt.Errorf("the schema of property %v.%v is empty", path, name)
+		}
+		if err := property.compile(path + "." + name); err != nil {
+			return err
+		}
+	}
+	if schema.Items != nil {
+		return schema.Items.compile(path + "[]")
+	}
+
+	return nil
+}
+
+func (schema *Schema) types() []string {
+	switch schemaType := schema.Type.(type) {
+	case string:
+		return []string{schemaType}
+	case []interface{}:
+		types := make([]string, 0, len(schemaType))
+		for _, entry := range schemaType {
+			if name, ok := entry.(string); ok {
+				types = append(types, name)
+			}
+		}
+		return types
+	}
+
+	return nil
+}
+
+// Validate checks a decoded JSON document against the schema and returns a message for every violation
+func (schema *Schema) Validate(value interface{}) []string {
+	violations := make([]string, 0)
+	schema.validate("$", value, &violations)
+
+	return violations
+}
+
+func (schema *Schema) validate(path string, value interface{}, violations *[]string) {
+	addViolation := func(format string, args ...interface{}) {
+		*violations = append(*violations, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if types := schema.types(); len(types) > 0 && !matchesType(types, value) {
+		addViolation("expected %v but got %v", strings.Join(types, " or "), jsonType(value))
+		return
+	}
+	if len(schema.Enum) > 0 {
+		allowed := false
+		for _, candidate := range schema.Enum {
+			if equalJson(candidate, value) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			addViolation("the value is not one of the allowed values")
+		}
+	}
+
+	switch typed := value.(type) {
+	case float64:
+		if schema.Minimum != nil && typed < *schema.Minimum {
+			addViolation("%v is lower than the minimum %v", typed, *schema.Minimum)
+		}
+		if schema.Maximum != nil && typed > *schema.Maximum {
+			addViolation("%v is greater than the maximum %v", typed, *schema.Maximum)
+		}
+	case string:
+		length := utf8.RuneCountInString(typed)
+		if schema.MinLength != nil && length < *schema.MinLength {
+			addViolation("the value is shorter than %v characters", *schema.MinLength)
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			addViolation("the value is longer than %v characters", *schema.MaxLength)
+		}
+		if schema.pattern != nil && !schema.pattern.MatchString(typed) {
+			addViolation("%v does not match the pattern %v", typed, *schema.Pattern)
+		}
+	case []interface{}:
+		if schema.MinItems != nil && len(typed) < *schema.MinItems {
+			addViolation("the array has less than %v items", *schema.MinItems)
+		}
+		if schema.MaxItems != nil && len(typed) > *schema.MaxItems {
+			addViolation("the array has more than %v items", *schema.MaxItems)
+		}
+		if schema.Items != nil {
+			for idx, item := range typed {
+				schema.Items.validate(fmt.Sprintf("%v[%d]", path, idx), item, violations)
+			}
+		}
+	case map[string]interface{}:
+		for _, required := range schema.Required {
+			if _, ok := typed[required]; !ok {
+				addViolation("the property %v is required", required)
+			}
+		}
+		names := make([]string, 0, len(typed))
+		for name := range typed {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property, ok := schema.Properties[name]
+			if !ok {
+				if schema.AdditionalProperties != nil && !*schema.AdditionalProperties {
+					addViolation("the property %v is not allowed", name)
+				}
+				continue
+			}
+			property.validate(path+"."+name, typed[name], violations)
+		}
+	}
+}
+
+func matchesType(types []string, value interface{}) bool {
+	actual := jsonType(value)
+	for _, expected := range types {
+		if expected == actual || (expected == "number" && actual == "integer") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func jsonType(value interface{}) string {
+	switch typed := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if typed == float64(int64(typed)) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+
+	return "unknown"
+}
+
+func equalJson(left, right interface{}) bool {
+	leftJson, leftErr := json.Marshal(left)
+	rightJson, rightErr := json.Marshal(right)
+
+	return leftErr == nil && rightErr == nil && string(leftJson) == string(rightJson)
+}
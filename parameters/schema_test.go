@@ -0,0 +1,52 @@
+package parameters
+
+import (
+	"strings"
+	"testing"
+
+	"orion.misc/structs"
+)
+
+func TestParseSchema(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		err    string
+	}{
+		{"supported keywords", `{"$schema": "x", "title": "t", "type": "object", "required": ["a"],
+			"properties": {"a": {"type": "array", "items": {"type": "string", "pattern": "^[a-z]+$"}}}}`, ""},
+		{"ref", `{"$ref": "#/definitions/a"}`, "keyword $ref at $ is not supported"},
+		{"one of", `{"oneOf": [{"type": "string"}]}`, "keyword oneOf at $ is not supported"},
+		{"nested format", `{"properties": {"a": {"type": "string", "format": "email"}}}`, "keyword format at $.a is not supported"},
+		{"exclusive minimum in items", `{"items": {"exclusiveMinimum": 1}}`, "keyword exclusiveMinimum at $[] is not supported"},
+		{"pattern properties", `{"patternProperties": {"^a": {}}}`, "keyword patternProperties at $ is not supported"},
+		{"unknown type", `{"type": "date"}`, "schema type date at $ is unknown"},
+		{"invalid pattern", `{"pattern": "("}`, "pattern at $ is invalid"},
+		{"no object", `[]`, "must be an object"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseSchema(test.schema)
+			if len(test.err) == 0 {
+				if err != nil {
+					t.Fatalf("ParseSchema failed: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("ParseSchema error = %v, want an error containing %q", err, test.err)
+			}
+		})
+	}
+}
+
+func TestTypedValueRejectsNonFiniteFloats(t *testing.T) {
+	for _, value := range []string{"NaN", "Inf", "-Inf", "+Infinity", "1e400"} {
+		if typed, err := TypedValue(structs.Parameter{Type: TypeFloat, Value: value}); err == nil {
+			t.Fatalf("TypedValue(%q) = %v, want an error", value, typed)
+		}
+	}
+	if typed, err := TypedValue(structs.Parameter{Type: TypeFloat, Value: "1.5"}); err != nil || typed != 1.5 {
+		t.Fatalf("TypedValue(1.5) = %v, %v", typed, err)
+	}
+}
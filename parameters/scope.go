@@ -0,0 +1,327 @@
+package parameters
+
+import (
+	"errors"
+	"fmt"
+	"orion.misc/structs"
+	"sort"
+	"strings"
+)
+
+const (
+	ScopeGlobal      = "GLOBAL"
+	ScopeApplication = "APPLICATION"
+	ScopeInstance    = "INSTANCE"
+	ScopeUser        = "USER"
+)
+
+const (
+	ErrorInvalidScope   = "INVALID_SCOPE"
+	ErrorDuplicateScope = "DUPLICATE_SCOPE"
+	ErrorTypeMismatch   = "TYPE_MISMATCH"
+	// ErrorConstraintMismatch is reported if the GLOBAL values of a parameter have different constraints
+	ErrorConstraintMismatch = "CONSTRAINT_MISMATCH"
+	// ErrorOverrideConstraints is reported if a value below GLOBAL has constraints other than the GLOBAL ones
+	ErrorOverrideConstraints = "OVERRIDE_CONSTRAINTS"
+)
+
+// scopePriorities ranks the scopes, the higher the priority the more specific the scope
+var scopePriorities = map[string]int{
+	ScopeGlobal:      0,
+	ScopeApplication: 1,
+	ScopeInstance:    2,
+	ScopeUser:        3,
+}
+
+// ScopeLevel returns the level of a scope in upper case, parameters without a scope are global
+func ScopeLevel(scope *structs.ParameterScope) string {
+	if scope == nil || len(strings.TrimSpace(scope.Level)) == 0 {
+		return ScopeGlobal
+	}
+
+	return strings.ToUpper(strings.TrimSpace(scope.Level))
+}
+
+// CheckScope makes sure that a scope has a known level and names everything its level needs
+func CheckScope(scope *structs.ParameterScope) error {
+	level := ScopeLevel(scope)
+	if _, ok := scopePriorities[level]; !ok {
+		return fmt.Errorf("the scope %v is unknown", scope.Level)
+	}
+	if scope == nil {
+		return nil
+	}
+	if (level == ScopeApplication || level == ScopeInstance) && isEmpty(scope.Application) {
+		return fmt.Errorf("the scope %v needs an application", level)
+	}
+	if level == ScopeInstance && scope.Instance == nil {
+		return errors.New("the scope INSTANCE needs an instance")
+	}
+	if level == ScopeUser && isEmpty(scope.User) {
+		return errors.New("the scope USER needs a user")
+	}
+
+	return nil
+}
+
+// NormalizeScope returns the scope in the form it is stored in: the level in upper case and only the fields the level
+// needs. Global values for every environment have no scope, so they are stored like the parameters from before scopes.
+func NormalizeScope(scope *structs.ParameterScope) *structs.ParameterScope {
+	level := ScopeLevel(scope)
+	if scope == nil || (level == ScopeGlobal && scope.Environment == nil) {
+		return nil
+	}
+	normalized := structs.ParameterScope{Level: level, Environment: scope.Environment}
+	switch level {
+	case ScopeApplication:
+		normalized.Application = scope.Application
+	case ScopeInstance:
+		normalized.Application = scope.Application
+		normalized.Instance = scope.Instance
+	case ScopeUser:
+		normalized.User = scope.User
+	}
+
+	return &normalized
+}
+
+// Applies reports whether a parameter applies to a context. Only the fields its scope level needs are compared,
+// so a user override applies to that user in every application.
+func Applies(parameter structs.Parameter, context structs.ParameterContext) bool {
+	scope := parameter.Scope
+	if scope == nil {
+		return true
+	}
+	if scope.Environment != nil && !equal(scope.Environment, context.Environment) {
+		return false
+	}
+	switch ScopeLevel(scope) {
+	case ScopeGlobal:
+		return true
+	case ScopeApplication:
+		return equal(scope.Application, context.Application)
+	case ScopeInstance:
+		return equal(scope.Application, context.Application) && context.Instance != nil &&
+			scope.Instance != nil && *scope.Instance == *context.Instance
+	case ScopeUser:
+		return equal(scope.User, context.User)
+	}
+
+	return false
+}
+
+// Resolve returns the effective value of a parameter for a context. The resolution order is
+// user > instance > application > global, on the same level values for an environment win over values for
+// every environment. Resolve returns nil if no value applies.
+func Resolve(name string, candidates []structs.Parameter, context structs.ParameterContext) (*structs.ParameterResolution, error) {
+	applying := make([]structs.Parameter, 0, len(candidates))
+	for _, candidate := range candidates {
+		if candidate.Info.Name == name && Applies(candidate, context) {
+			applying = append(applying, candidate)
+		}
+	}
+	if len(applying) == 0 {
+		return nil, nil
+	}
+	sort.SliceStable(applying, func(i, j int) bool {
+		return priority(applying[i]) > priority(applying[j])
+	})
+
+	chain := make([]structs.ParameterOverride, 0, len(applying))
+	for idx, parameter := range applying {
+		chain = append(chain, structs.ParameterOverride{
+			Level:     ScopeLevel(parameter.Scope),
+			Effective: idx == 0,
+			Parameter: parameter,
+		})
+	}
+	effective := applying[0]
+	typedValue, err := TypedValue(effective)
+	if err != nil {
+		return nil, fmt.Errorf("the value of parameter %v on level %v does not match its type: %v", name, ScopeLevel(effective.Scope), err)
+	}
+	chain[0].Parameter.TypedValue = typedValue
+
+	return &structs.ParameterResolution{
+		Name:       name,
+		Value:      effective.Value,
+		TypedValue: typedValue,
+		Level:      ScopeLevel(effective.Scope),
+		Chain:      chain,
+	}, nil
+}
+
+// CheckOverrides validates the updated values of parameters together with the stored values of the same parameters.
+// Every parameter has at most one value per scope and all of its values share its type. The constraints of a
+// parameter are defined by its GLOBAL values, values on the other levels inherit them and may only repeat them.
+// Updated values and the stored values of parameters whose GLOBAL values are updated are validated against the
+// constraints. Updated parameters replace the stored parameters with the same id.
+func CheckOverrides(updated, stored []structs.Parameter) []structs.ParameterValidationError {
+	validationErrors := make([]structs.ParameterValidationError, 0)
+	addError := func(parameter structs.Parameter, code, message string) {
+		validationErrors = append(validationErrors, structs.ParameterValidationError{
+			Parameter: parameter.Info.Name,
+			Value:     parameter.Value,
+			Code:      code,
+			Message:   message,
+		})
+	}
+	replaced := make(map[string]bool, len(updated))
+	for _, parameter := range updated {
+		if parameter.ID != nil && !parameter.ID.IsZero() {
+			replaced[parameter.ID.Hex()] = true
+		}
+	}
+	values := make(map[string]structs.Parameter)
+	types := make(map[string]string)
+	for _, parameter := range stored {
+		if parameter.ID != nil && replaced[parameter.ID.Hex()] {
+			continue
+		}
+		values[scopeKey(parameter)] = parameter
+		types[parameter.Info.Name] = NormalizeType(parameter.Type)
+	}
+
+	updatedKeys := make(map[string]bool, len(updated))
+	redefined := make(map[string]bool)
+	for _, parameter := range updated {
+		key := scopeKey(parameter)
+		if _, ok := values[key]; ok {
+			addError(parameter, ErrorDuplicateScope, fmt.Sprintf("the parameter %v already has a value for this %v scope", parameter.Info.Name, ScopeLevel(parameter.Scope)))
+		}
+		values[key] = parameter
+		updatedKeys[key] = true
+		if ScopeLevel(parameter.Scope) == ScopeGlobal {
+			redefined[parameter.Info.Name] = true
+		}
+		parameterType := NormalizeType(parameter.Type)
+		if known, ok := types[parameter.Info.Name]; ok && known != parameterType {
+			addError(parameter, ErrorTypeMismatch, fmt.Sprintf("the parameter %v is of type %v, not %v", parameter.Info.Name, known, parameterType))
+			continue
+		}
+		types[parameter.Info.Name] = parameterType
+	}
+	if len(validationErrors) > 0 {
+		return validationErrors
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	constraints := make(map[string]*structs.ParameterConstraints)
+	for _, key := range keys {
+		parameter := values[key]
+		if ScopeLevel(parameter.Scope) != ScopeGlobal {
+			continue
+		}
+		if defined, ok := constraints[parameter.Info.Name]; ok && !equalConstraints(defined, parameter.Constraints) {
+			addError(parameter, ErrorConstraintMismatch, fmt.Sprintf("all GLOBAL values of parameter %v need the same constraints", parameter.Info.Name))
+			continue
+		}
+		constraints[parameter.Info.Name] = parameter.Constraints
+	}
+	for _, key := range keys {
+		parameter := values[key]
+		if !updatedKeys[key] && !redefined[parameter.Info.Name] {
+			continue
+		}
+		level := ScopeLevel(parameter.Scope)
+		if level != ScopeGlobal {
+			if parameter.Constraints != nil && !equalConstraints(parameter.Constraints, constraints[parameter.Info.Name]) {
+				addError(parameter, ErrorOverrideConstraints, fmt.Sprintf("the constraints of parameter %v are defined by its GLOBAL value, the %v value can't change them",
+					parameter.Info.Name, level))
+				continue
+			}
+			parameter.Constraints = constraints[parameter.Info.Name]
+		}
+		for _, validationError := range Validate(parameter) {
+			if !updatedKeys[key] {
+				validationError.Message = fmt.Sprintf("the stored %v value violates the updated constraints: %v", level, validationError.Message)
+			}
+			validationErrors = append(validationErrors, validationError)
+		}
+	}
+
+	return validationErrors
+}
+
+// equalConstraints compares constraints, missing constraints equal empty ones
+func equalConstraints(left, right *structs.ParameterConstraints) bool {
+	if left == nil {
+		left = &structs.ParameterConstraints{}
+	}
+	if right == nil {
+		right = &structs.ParameterConstraints{}
+	}
+	if !equalNumber(left.Min, right.Min) || !equalNumber(left.Max, right.Max) ||
+		valueOf(left.Pattern) != valueOf(right.Pattern) || valueOf(left.Schema) != valueOf(right.Schema) ||
+		len(left.AllowedValues) != len(right.AllowedValues) {
+		return false
+	}
+	for idx := range left.AllowedValues {
+		if left.AllowedValues[idx] != right.AllowedValues[idx] {
+			return false
+		}
+	}
+
+	return (left.Pattern == nil) == (right.Pattern == nil) && (left.Schema == nil) == (right.Schema == nil)
+}
+
+func equalNumber(left, right *float64) bool {
+	if left == nil || right == nil {
+		return left == right
+	}
+
+	return *left == *right
+}
+
+// scopeKey identifies the scope of a parameter value, only the fields its level needs are part of the key
+func scopeKey(parameter structs.Parameter) string {
+	scope := parameter.Scope
+	level := ScopeLevel(scope)
+	parts := []string{parameter.Info.Name, level, "", "", ""}
+	if scope == nil {
+		return strings.Join(parts, "|")
+	}
+	if scope.Environment != nil {
+		parts[2] = *scope.Environment
+	}
+	switch level {
+	case ScopeApplication, ScopeInstance:
+		parts[3] = valueOf(scope.Application)
+		if level == ScopeInstance && scope.Instance != nil {
+			parts[4] = fmt.Sprint(*scope.Instance)
+		}
+	case ScopeUser:
+		parts[3] = valueOf(scope.User)
+	}
+
+	return strings.Join(parts, "|")
+}
+
+func valueOf(value *string) string {
+	if value == nil {
+		return ""
+	}
+
+	return *value
+}
+
+func priority(parameter structs.Parameter) int {
+	priority := scopePriorities[ScopeLevel(parameter.Scope)] * 2
+	if parameter.Scope != nil && parameter.Scope.Environment != nil {
+		priority++
+	}
+
+	return priority
+}
+
+func isEmpty(value *string) bool {
+	return value == nil || len(strings.TrimSpace(*value)) == 0
+}
+
+func equal(left, right *string) bool {
+	return left != nil && right != nil && *left == *right
+}
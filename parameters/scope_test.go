@@ -0,0 +1,65 @@
+package parameters
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"orion.misc/structs"
+)
+
+func parameter(level, value string, constraints *structs.ParameterConstraints) structs.Parameter {
+	id := primitive.NewObjectID()
+	user := "jdoe"
+	parameter := structs.Parameter{ID: &id, Value: value, Type: TypeInt, Constraints: constraints}
+	parameter.Info.Name = "timeout"
+	if level == ScopeUser {
+		parameter.Scope = &structs.ParameterScope{Level: ScopeUser, User: &user}
+	}
+
+	return parameter
+}
+
+func TestCheckOverrides(t *testing.T) {
+	max := 10.0
+	other := 20.0
+	limited := &structs.ParameterConstraints{Max: &max}
+	global := parameter(ScopeGlobal, "5", limited)
+	tests := []struct {
+		name    string
+		updated []structs.Parameter
+		stored  []structs.Parameter
+		codes   []string
+	}{
+		{"override inherits the constraints", []structs.Parameter{parameter(ScopeUser, "8", nil)}, []structs.Parameter{global}, nil},
+		{"override violates the inherited constraints", []structs.Parameter{parameter(ScopeUser, "12", nil)}, []structs.Parameter{global}, []string{ErrorValueOutOfRange}},
+		{"override repeats the constraints", []structs.Parameter{parameter(ScopeUser, "8", &structs.ParameterConstraints{Max: &max})}, []structs.Parameter{global}, nil},
+		{"override changes the constraints", []structs.Parameter{parameter(ScopeUser, "15", &structs.ParameterConstraints{Max: &other})}, []structs.Parameter{global}, []string{ErrorOverrideConstraints}},
+		{"stored override violates new global constraints", []structs.Parameter{parameter(ScopeGlobal, "5", limited)}, []structs.Parameter{parameter(ScopeUser, "12", nil)}, []string{ErrorValueOutOfRange}},
+		{"duplicate scope", []structs.Parameter{parameter(ScopeUser, "8", nil)}, []structs.Parameter{global, parameter(ScopeUser, "9", nil)}, []string{ErrorDuplicateScope}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			validationErrors := CheckOverrides(test.updated, test.stored)
+			if len(validationErrors) != len(test.codes) {
+				t.Fatalf("CheckOverrides = %+v, want the codes %v", validationErrors, test.codes)
+			}
+			for idx, code := range test.codes {
+				if validationErrors[idx].Code != code {
+					t.Fatalf("CheckOverrides = %+v, want the codes %v", validationErrors, test.codes)
+				}
+			}
+		})
+	}
+}
+
+func TestNormalizeScope(t *testing.T) {
+	application := "core"
+	user := "jdoe"
+	if scope := NormalizeScope(&structs.ParameterScope{Level: "global"}); scope != nil {
+		t.Fatalf("NormalizeScope of a global scope = %+v, want nil", scope)
+	}
+	scope := NormalizeScope(&structs.ParameterScope{Level: "user", Application: &application, User: &user})
+	if scope == nil || scope.Level != ScopeUser || scope.Application != nil || scope.User != &user {
+		t.Fatalf("NormalizeScope of a user scope = %+v", scope)
+	}
+}
@@ -0,0 +1,202 @@
+package parameters
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"orion.misc/structs"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	TypeInt      = "INT"
+	TypeFloat    = "FLOAT"
+	TypeBool     = "BOOL"
+	TypeDuration = "DURATION"
+	TypeString   = "STRING"
+	TypeEnum     = "ENUM"
+	TypeJson     = "JSON"
+)
+
+const (
+	ErrorUnknownType        = "UNKNOWN_TYPE"
+	ErrorInvalidConstraints = "INVALID_CONSTRAINTS"
+	ErrorInvalidValue       = "INVALID_VALUE"
+	ErrorValueOutOfRange    = "VALUE_OUT_OF_RANGE"
+	ErrorPatternMismatch    = "PATTERN_MISMATCH"
+	ErrorValueNotAllowed    = "VALUE_NOT_ALLOWED"
+	ErrorSchemaViolation    = "SCHEMA_VIOLATION"
+)
+
+var errUnknownType = errors.New("the type of the parameter is unknown")
+
+// NormalizeType returns the type in upper case, parameters without a type are strings
+func NormalizeType(parameterType string) string {
+	if len(strings.TrimSpace(parameterType)) == 0 {
+		return TypeString
+	}
+
+	return strings.ToUpper(strings.TrimSpace(parameterType))
+}
+
+// TypedValue converts the value of a parameter according to its type: int64 for integers, float64 for decimals,
+// booleans, milliseconds for durations and the decoded document for JSON
+func TypedValue(parameter structs.Parameter) (interface{}, error) {
+	value := parameter.Value
+	switch NormalizeType(parameter.Type) {
+	case TypeInt:
+		typed, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%v is not an integer", value)
+		}
+		return typed, nil
+	case TypeFloat:
+		typed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || math.IsNaN(typed) || math.IsInf(typed, 0) {
+			return nil, fmt.Errorf("%v is not a decimal", value)
+		}
+		return typed, nil
+	case TypeBool:
+		typed, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("%v is not a boolean", value)
+		}
+		return typed, nil
+	case TypeDuration:
+		typed, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("%v is not a duration like 90s or 1h30m", value)
+		}
+		return typed.Milliseconds(), nil
+	case TypeJson:
+		var typed interface{}
+		if err := json.Unmarshal([]byte(value), &typed); err != nil {
+			return nil, fmt.Errorf("the value is no valid JSON: %v", err)
+		}
+		return typed, nil
+	case TypeString, TypeEnum:
+		return value, nil
+	default:
+		return nil, errUnknownType
+	}
+}
+
+// Validate checks the scope and the constraints of a parameter and its value against its type and constraints and
+// returns an error for every check that failed
+func Validate(parameter structs.Parameter) []structs.ParameterValidationError {
+	validationErrors := make([]structs.ParameterValidationError, 0)
+	addError := func(code, message string) {
+		validationErrors = append(validationErrors, structs.ParameterValidationError{
+			Parameter: parameter.Info.Name,
+			Value:     parameter.Value,
+			Code:      code,
+			Message:   message,
+		})
+	}
+
+	if err := CheckScope(parameter.Scope); err != nil {
+		addError(ErrorInvalidScope, err.Error())
+
+		return validationErrors
+	}
+	parameterType := NormalizeType(parameter.Type)
+	typed, err := TypedValue(parameter)
+	if err == errUnknownType {
+		addError(ErrorUnknownType, fmt.Sprintf("the type %v is unknown", parameter.Type))
+
+		return validationErrors
+	}
+	constraints := structs.ParameterConstraints{}
+	if parameter.Constraints != nil {
+		constraints = *parameter.Constraints
+	}
+	if err := checkConstraints(parameterType, constraints); err != nil {
+		addError(ErrorInvalidConstraints, err.Error())
+
+		return validationErrors
+	}
+	if err != nil {
+		addError(ErrorInvalidValue, err.Error())
+
+		return validationErrors
+	}
+
+	var number *float64
+	switch value := typed.(type) {
+	case int64:
+		converted := float64(value)
+		number = &converted
+	case float64:
+		number = &value
+	}
+	if number != nil {
+		if constraints.Min != nil && *number < *constraints.Min {
+			addError(ErrorValueOutOfRange, fmt.Sprintf("%v is lower than the minimum %v", parameter.Value, *constraints.Min))
+		}
+		if constraints.Max != nil && *number > *constraints.Max {
+			addError(ErrorValueOutOfRange, fmt.Sprintf("%v is greater than the maximum %v", parameter.Value, *constraints.Max))
+		}
+	}
+	if constraints.Pattern != nil && !regexp.MustCompile(*constraints.Pattern).MatchString(parameter.Value) {
+		addError(ErrorPatternMismatch, fmt.Sprintf("%v does not match the pattern %v", parameter.Value, *constraints.Pattern))
+	}
+	if len(constraints.AllowedValues) > 0 && !contains(constraints.AllowedValues, parameter.Value) {
+		addError(ErrorValueNotAllowed, fmt.Sprintf("%v is not one of the allowed values", parameter.Value))
+	}
+	if constraints.Schema != nil {
+		schema, _ := ParseSchema(*constraints.Schema)
+		for _, violation := range schema.Validate(typed) {
+			addError(ErrorSchemaViolation, violation)
+		}
+	}
+
+	return validationErrors
+}
+
+// checkConstraints makes sure that the constraints can be applied to the type of the parameter
+func checkConstraints(parameterType string, constraints structs.ParameterConstraints) error {
+	if constraints.Min != nil && constraints.Max != nil && *constraints.Min > *constraints.Max {
+		return fmt.Errorf("the minimum %v is greater than the maximum %v", *constraints.Min, *constraints.Max)
+	}
+	if (constraints.Min != nil || constraints.Max != nil) && parameterType != TypeInt && parameterType != TypeFloat && parameterType != TypeDuration {
+		return fmt.Errorf("a range can't be applied to parameters of type %v", parameterType)
+	}
+	if constraints.Pattern != nil {
+		if parameterType != TypeString {
+			return fmt.Errorf("a pattern can't be applied to parameters of type %v", parameterType)
+		}
+		if _, err := regexp.Compile(*constraints.Pattern); err != nil {
+			return fmt.Errorf("the pattern is invalid: %v", err)
+		}
+	}
+	if parameterType == TypeEnum && len(constraints.AllowedValues) == 0 {
+		return errors.New("enum parameters need allowed values")
+	}
+	if len(constraints.AllowedValues) > 0 && parameterType == TypeJson {
+		return errors.New("allowed values can't be applied to JSON parameters, use a schema instead")
+	}
+	if constraints.Schema != nil {
+		if parameterType != TypeJson {
+			return fmt.Errorf("a schema can't be applied to parameters of type %v", parameterType)
+		}
+		if _, err := ParseSchema(*constraints.Schema); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,173 @@
+package statemachine
+
+import (
+	"fmt"
+	"orion.misc/structs"
+)
+
+const (
+	FindingUnreachableState      = "UNREACHABLE_STATE"
+	FindingUnmarkedTerminalState = "UNMARKED_TERMINAL_STATE"
+	FindingDanglingSourceState   = "DANGLING_SOURCE_STATE"
+	FindingDanglingTargetState   = "DANGLING_TARGET_STATE"
+	FindingNoDefaultState        = "NO_DEFAULT_STATE"
+	FindingMultipleDefaultStates = "MULTIPLE_DEFAULT_STATES"
+)
+
+// Analyze checks the definition for states nobody can reach, dead ends that were not declared terminal,
+// rules that point at missing states and a missing or ambiguous default state
+func (definition Definition) Analyze() structs.StateMachineAnalysis {
+	analysis := structs.StateMachineAnalysis{
+		ReferencedType: definition.ReferencedType,
+		Findings:       make([]structs.StateMachineFinding, 0),
+	}
+
+	defaultStates := make([]string, 0)
+	for _, state := range definition.States {
+		if state.DefaultState {
+			defaultStates = append(defaultStates, definition.stateReference(state))
+		}
+	}
+	switch {
+	case len(defaultStates) == 0:
+		analysis.Findings = append(analysis.Findings, definition.finding(FindingNoDefaultState, nil, nil, nil,
+			fmt.Sprintf("%v has no default state", definition.ReferencedType)))
+	case len(defaultStates) > 1:
+		analysis.Findings = append(analysis.Findings, definition.finding(FindingMultipleDefaultStates, nil, nil, nil,
+			fmt.Sprintf("%v has %d default states", definition.ReferencedType, len(defaultStates))))
+	}
+
+	for _, rule := range definition.Rules {
+		source := rule.SourceState
+		ruleId := ruleIdOf(rule)
+		if definition.FindState(source) == nil {
+			analysis.Findings = append(analysis.Findings, definition.finding(FindingDanglingSourceState, &source, nil, ruleId,
+				fmt.Sprintf("rule uses the source state %v which does not exist", source)))
+		}
+		for _, target := range rule.AllowedTargetStates {
+			target := target
+			if definition.FindState(target) == nil {
+				analysis.Findings = append(analysis.Findings, definition.finding(FindingDanglingTargetState, &source, &target, ruleId,
+					fmt.Sprintf("rule for %v allows the target state %v which does not exist", definition.StateName(source), target)))
+			}
+		}
+	}
+
+	if len(defaultStates) > 0 {
+		reachable := definition.reachableFrom(defaultStates)
+		for _, state := range definition.States {
+			reference := definition.stateReference(state)
+			if !reachable[definition.StateKey(reference)] {
+				analysis.Findings = append(analysis.Findings, definition.finding(FindingUnreachableState, &reference, nil, nil,
+					fmt.Sprintf("state %v cannot be reached from the default state", definition.StateName(reference))))
+			}
+		}
+	}
+
+	for _, state := range definition.States {
+		reference := definition.stateReference(state)
+		if !definition.hasOutgoingTransitions(reference) && !definition.IsTerminal(reference) {
+			analysis.Findings = append(analysis.Findings, definition.finding(FindingUnmarkedTerminalState, &reference, nil, nil,
+				fmt.Sprintf("state %v has no outgoing transitions but is not marked as terminal", definition.StateName(reference))))
+		}
+	}
+
+	analysis.Valid = len(analysis.Findings) == 0
+
+	return analysis
+}
+
+// IsTerminal reports whether a rule declares the state as a terminal state
+func (definition Definition) IsTerminal(reference string) bool {
+	for _, rule := range definition.RulesForSource(reference) {
+		if rule.Terminal {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NewFindings returns the findings of after that were not already present in before
+func NewFindings(before, after structs.StateMachineAnalysis) []structs.StateMachineFinding {
+	known := make(map[string]bool, len(before.Findings))
+	for _, finding := range before.Findings {
+		known[findingKey(finding)] = true
+	}
+	added := make([]structs.StateMachineFinding, 0)
+	for _, finding := range after.Findings {
+		if !known[findingKey(finding)] {
+			added = append(added, finding)
+		}
+	}
+
+	return added
+}
+
+func (definition Definition) reachableFrom(startStates []string) map[string]bool {
+	reachable := make(map[string]bool)
+	queue := make([]string, 0, len(startStates))
+	for _, start := range startStates {
+		key := definition.StateKey(start)
+		if !reachable[key] {
+			reachable[key] = true
+			queue = append(queue, key)
+		}
+	}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, rule := range definition.RulesForSource(current) {
+			for _, target := range rule.AllowedTargetStates {
+				key := definition.StateKey(target)
+				if !reachable[key] {
+					reachable[key] = true
+					queue = append(queue, key)
+				}
+			}
+		}
+	}
+
+	return reachable
+}
+
+func (definition Definition) hasOutgoingTransitions(reference string) bool {
+	for _, rule := range definition.RulesForSource(reference) {
+		if len(rule.AllowedTargetStates) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (definition Definition) stateReference(state structs.StateDefinition) string {
+	if state.ID != nil {
+		return state.ID.Hex()
+	}
+
+	return state.Info.Name
+}
+
+func (definition Definition) finding(code string, state, target, ruleId *string, message string) structs.StateMachineFinding {
+	return structs.StateMachineFinding{
+		Code:           code,
+		ReferencedType: definition.ReferencedType,
+		State:          state,
+		TargetState:    target,
+		RuleId:         ruleId,
+		Message:        message,
+	}
+}
+
+func findingKey(finding structs.StateMachineFinding) string {
+	key := finding.Code + "|" + finding.ReferencedType
+	if finding.State != nil {
+		key += "|" + *finding.State
+	}
+	if finding.TargetState != nil {
+		key += "|" + *finding.TargetState
+	}
+
+	return key
+}
@@ -0,0 +1,138 @@
+package statemachine
+
+import (
+	"orion.misc/structs"
+)
+
+// Definition holds the states and the transition rules of one referenced type
+type Definition struct {
+	ReferencedType string
+	// Version is the published version the definition was built from or 0 for the live rules
+	Version int
+	States  []structs.StateDefinition
+	Rules   []structs.StateTransitionRule
+}
+
+// NewDefinition builds the state machine of a referenced type. Rules without a referenced type are
+// assigned by their source state for backwards compatibility with rules saved before the field existed.
+func NewDefinition(referencedType string, states []structs.StateDefinition, rules []structs.StateTransitionRule) Definition {
+	definition := Definition{
+		ReferencedType: referencedType,
+		States:         make([]structs.StateDefinition, 0),
+		Rules:          make([]structs.StateTransitionRule, 0),
+	}
+	for _, state := range states {
+		if state.ReferencedType == referencedType {
+			definition.States = append(definition.States, state)
+		}
+	}
+	for _, rule := range rules {
+		if rule.ReferencedType == referencedType ||
+			(len(rule.ReferencedType) == 0 && definition.FindState(rule.SourceState) != nil) {
+			definition.Rules = append(definition.Rules, rule)
+		}
+	}
+
+	return definition
+}
+
+// FindState returns the state a rule refers to, either by its id or by its name
+func (definition Definition) FindState(reference string) *structs.StateDefinition {
+	for idx, state := range definition.States {
+		if state.ID != nil && state.ID.Hex() == reference {
+			return &definition.States[idx]
+		}
+	}
+	for idx, state := range definition.States {
+		if state.Info.Name == reference {
+			return &definition.States[idx]
+		}
+	}
+
+	return nil
+}
+
+// StateKey returns the canonical key of a state reference so that references by id and by name can be compared
+func (definition Definition) StateKey(reference string) string {
+	state := definition.FindState(reference)
+	if state != nil && state.ID != nil {
+		return state.ID.Hex()
+	}
+
+	return reference
+}
+
+// StateName returns a human readable name for a state reference
+func (definition Definition) StateName(reference string) string {
+	state := definition.FindState(reference)
+	if state != nil && len(state.Info.Name) > 0 {
+		return state.Info.Name
+	}
+
+	return reference
+}
+
+// RulesForSource returns all rules whose source state is the given state
+func (definition Definition) RulesForSource(reference string) []structs.StateTransitionRule {
+	key := definition.StateKey(reference)
+	rules := make([]structs.StateTransitionRule, 0)
+	for _, rule := range definition.Rules {
+		if definition.StateKey(rule.SourceState) == key {
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules
+}
+
+// NewDefinitions builds the state machines of all referenced types that have states or typed rules
+func NewDefinitions(states []structs.StateDefinition, rules []structs.StateTransitionRule) []Definition {
+	referencedTypes := make([]string, 0)
+	known := make(map[string]bool)
+	addType := func(referencedType string) {
+		if len(referencedType) > 0 && !known[referencedType] {
+			known[referencedType] = true
+			referencedTypes = append(referencedTypes, referencedType)
+		}
+	}
+	for _, state := range states {
+		addType(state.ReferencedType)
+	}
+	for _, rule := range rules {
+		addType(rule.ReferencedType)
+	}
+
+	definitions := make([]Definition, 0, len(referencedTypes))
+	for _, referencedType := range referencedTypes {
+		definitions = append(definitions, NewDefinition(referencedType, states, rules))
+	}
+
+	return definitions
+}
+
+// WithRules returns a copy of the definition in which the given rules replace the stored rules with the same id.
+// Rules without an id are added.
+func (definition Definition) WithRules(rules []structs.StateTransitionRule) Definition {
+	merged := NewDefinition(definition.ReferencedType, definition.States, MergeRules(definition.Rules, rules))
+	merged.Version = definition.Version
+
+	return merged
+}
+
+// MergeRules replaces the stored rules with the updated rules of the same id and appends updated rules without an id
+func MergeRules(stored, updated []structs.StateTransitionRule) []structs.StateTransitionRule {
+	merged := make([]structs.StateTransitionRule, 0, len(stored)+len(updated))
+	replaced := make(map[string]bool)
+	for _, rule := range updated {
+		if rule.ID != nil && !rule.ID.IsZero() {
+			replaced[rule.ID.Hex()] = true
+		}
+	}
+	for _, rule := range stored {
+		if rule.ID == nil || !replaced[rule.ID.Hex()] {
+			merged = append(merged, rule)
+		}
+	}
+
+	return append(merged, updated...)
+}
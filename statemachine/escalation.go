@@ -0,0 +1,60 @@
+package statemachine
+
+import (
+	"orion.misc/structs"
+)
+
+// Escalation is an object that stayed longer in its state than the rule of that state allows
+type Escalation struct {
+	ObjectState structs.ObjectState
+	Rule        structs.StateTransitionRule
+}
+
+// DueEscalations returns the tracked objects of the definition that exceeded the max dwell time of their current
+// state at the given time (in milliseconds). Objects that were already escalated in their current state are skipped.
+func (definition Definition) DueEscalations(objectStates []structs.ObjectState, now int64) []Escalation {
+	escalations := make([]Escalation, 0)
+	for _, objectState := range objectStates {
+		if objectState.ObjectType != definition.ReferencedType || objectState.EscalatedDate != nil {
+			continue
+		}
+		for _, rule := range definition.RulesForSource(objectState.CurrentState) {
+			if rule.MaxDwellSeconds == nil || *rule.MaxDwellSeconds <= 0 {
+				continue
+			}
+			if now-objectState.EnteredDate > *rule.MaxDwellSeconds*1000 {
+				escalations = append(escalations, Escalation{ObjectState: objectState, Rule: rule})
+				break
+			}
+		}
+	}
+
+	return escalations
+}
+
+// DwellLimitedStates returns the references of the states that have a max dwell time. Each state is returned by the
+// reference of its rule as well as by its id and name, tracked objects may refer to it either way.
+func (definition Definition) DwellLimitedStates() []string {
+	references := make([]string, 0)
+	known := make(map[string]bool)
+	addReference := func(reference string) {
+		if len(reference) > 0 && !known[reference] {
+			known[reference] = true
+			references = append(references, reference)
+		}
+	}
+	for _, rule := range definition.Rules {
+		if rule.MaxDwellSeconds == nil || *rule.MaxDwellSeconds <= 0 {
+			continue
+		}
+		addReference(rule.SourceState)
+		if state := definition.FindState(rule.SourceState); state != nil {
+			if state.ID != nil {
+				addReference(state.ID.Hex())
+			}
+			addReference(state.Info.Name)
+		}
+	}
+
+	return references
+}
@@ -0,0 +1,59 @@
+package statemachine
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"orion.misc/structs"
+)
+
+func TestDueEscalations(t *testing.T) {
+	openId := primitive.NewObjectID()
+	open := structs.StateDefinition{ID: &openId, ReferencedType: "TICKET"}
+	open.Info.Name = "OPEN"
+	maxDwell := int64(60)
+	definition := NewDefinition("TICKET", []structs.StateDefinition{open}, []structs.StateTransitionRule{
+		{ReferencedType: "TICKET", SourceState: "OPEN", AllowedTargetStates: []string{"CLOSED"}, MaxDwellSeconds: &maxDwell},
+	})
+	escalated := int64(1)
+
+	tests := []struct {
+		name        string
+		objectState structs.ObjectState
+		now         int64
+		due         bool
+	}{
+		{"within the dwell time", structs.ObjectState{ObjectType: "TICKET", CurrentState: "OPEN", EnteredDate: 1000}, 61000, false},
+		{"exceeded the dwell time", structs.ObjectState{ObjectType: "TICKET", CurrentState: "OPEN", EnteredDate: 1000}, 61001, true},
+		{"state referenced by id", structs.ObjectState{ObjectType: "TICKET", CurrentState: openId.Hex(), EnteredDate: 0}, 120000, true},
+		{"already escalated", structs.ObjectState{ObjectType: "TICKET", CurrentState: "OPEN", EscalatedDate: &escalated}, 120000, false},
+		{"other object type", structs.ObjectState{ObjectType: "ORDER", CurrentState: "OPEN"}, 120000, false},
+		{"state without limit", structs.ObjectState{ObjectType: "TICKET", CurrentState: "CLOSED"}, 120000, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			escalations := definition.DueEscalations([]structs.ObjectState{test.objectState}, test.now)
+			if due := len(escalations) == 1; due != test.due {
+				t.Fatalf("due = %v, want %v", due, test.due)
+			}
+		})
+	}
+}
+
+func TestDwellLimitedStates(t *testing.T) {
+	openId := primitive.NewObjectID()
+	open := structs.StateDefinition{ID: &openId, ReferencedType: "TICKET"}
+	open.Info.Name = "OPEN"
+	maxDwell := int64(60)
+	noDwell := int64(0)
+	definition := NewDefinition("TICKET", []structs.StateDefinition{open}, []structs.StateTransitionRule{
+		{ReferencedType: "TICKET", SourceState: "OPEN", AllowedTargetStates: []string{"CLOSED"}, MaxDwellSeconds: &maxDwell},
+		{ReferencedType: "TICKET", SourceState: "CLOSED", AllowedTargetStates: []string{"OPEN"}, MaxDwellSeconds: &noDwell},
+		{ReferencedType: "TICKET", SourceState: "ARCHIVED", AllowedTargetStates: []string{"OPEN"}},
+	})
+
+	references := definition.DwellLimitedStates()
+	if len(references) != 2 || references[0] != "OPEN" || references[1] != openId.Hex() {
+		t.Fatalf("DwellLimitedStates() = %v, want [OPEN %v]", references, openId.Hex())
+	}
+}
@@ -0,0 +1,126 @@
+package statemachine
+
+import (
+	"fmt"
+	"orion.misc/structs"
+	"strings"
+)
+
+const (
+	ReasonAllowed           = "TRANSITION_ALLOWED"
+	ReasonUnknownState      = "UNKNOWN_STATE"
+	ReasonNoRuleForSource   = "NO_RULE_FOR_SOURCE_STATE"
+	ReasonTargetNotAllowed  = "TARGET_STATE_NOT_ALLOWED"
+	ReasonGuardFailed       = "GUARD_FAILED"
+	ReasonMissingParameters = "MISSING_PARAMETERS"
+	ReasonNotDefaultState   = "NOT_A_DEFAULT_STATE"
+)
+
+// Decision is the outcome of evaluating a single transition
+type Decision struct {
+	Allowed      bool
+	ReasonCode   string
+	Reason       string
+	MatchingRule *structs.StateTransitionRule
+	FailedGuards []structs.TransitionGuard
+}
+
+// Evaluate checks whether an object of the definition's referenced type may move from the current to the target state.
+// This is the single place where transition rules are interpreted so every caller enforces the workflow the same way.
+func (definition Definition) Evaluate(input TransitionInput) Decision {
+	currentState := input.CurrentState
+	targetState := input.TargetState
+	if len(currentState) == 0 || len(targetState) == 0 {
+		return denied(ReasonMissingParameters, "current state and target state must both be provided", nil)
+	}
+	if len(definition.States) > 0 {
+		if definition.FindState(currentState) == nil {
+			return denied(ReasonUnknownState, fmt.Sprintf("state %v is not defined for %v", currentState, definition.ReferencedType), nil)
+		}
+		if definition.FindState(targetState) == nil {
+			return denied(ReasonUnknownState, fmt.Sprintf("state %v is not defined for %v", targetState, definition.ReferencedType), nil)
+		}
+	}
+
+	rules := definition.RulesForSource(currentState)
+	if len(rules) == 0 {
+		return denied(ReasonNoRuleForSource, fmt.Sprintf("no transition rule exists for source state %v of %v",
+			definition.StateName(currentState), definition.ReferencedType), nil)
+	}
+
+	targetKey := definition.StateKey(targetState)
+	var guardedDecision *Decision
+	for idx, rule := range rules {
+		if !definition.allowsTarget(rule, targetKey) {
+			continue
+		}
+		failedGuards := make([]structs.TransitionGuard, 0)
+		reasons := make([]string, 0)
+		for _, guard := range definition.guardsForTarget(rule, targetState) {
+			if reason := checkGuard(guard, input); len(reason) > 0 {
+				failedGuards = append(failedGuards, guard)
+				reasons = append(reasons, reason)
+			}
+		}
+		if len(failedGuards) == 0 {
+			return Decision{
+				Allowed:      true,
+				ReasonCode:   ReasonAllowed,
+				Reason:       fmt.Sprintf("transition from %v to %v is allowed", definition.StateName(currentState), definition.StateName(targetState)),
+				MatchingRule: &rules[idx],
+				FailedGuards: failedGuards,
+			}
+		}
+		if guardedDecision == nil {
+			decision := denied(ReasonGuardFailed, fmt.Sprintf("transition from %v to %v is blocked: %v",
+				definition.StateName(currentState), definition.StateName(targetState), strings.Join(reasons, "; ")), &rules[idx])
+			decision.FailedGuards = failedGuards
+			guardedDecision = &decision
+		}
+	}
+	if guardedDecision != nil {
+		return *guardedDecision
+	}
+
+	return denied(ReasonTargetNotAllowed, fmt.Sprintf("transition from %v to %v is not allowed",
+		definition.StateName(currentState), definition.StateName(targetState)), &rules[0])
+}
+
+// EvaluateInitialState checks the first state of an object that is not tracked yet which has to be a default state
+func (definition Definition) EvaluateInitialState(targetState string) Decision {
+	state := definition.FindState(targetState)
+	if state == nil {
+		return denied(ReasonUnknownState, fmt.Sprintf("state %v is not defined for %v", targetState, definition.ReferencedType), nil)
+	}
+	if !state.DefaultState {
+		return denied(ReasonNotDefaultState, fmt.Sprintf("objects of %v have to start in a default state and %v is none",
+			definition.ReferencedType, definition.StateName(targetState)), nil)
+	}
+
+	return Decision{
+		Allowed:      true,
+		ReasonCode:   ReasonAllowed,
+		Reason:       fmt.Sprintf("%v is a default state of %v", definition.StateName(targetState), definition.ReferencedType),
+		FailedGuards: make([]structs.TransitionGuard, 0),
+	}
+}
+
+func (definition Definition) allowsTarget(rule structs.StateTransitionRule, targetKey string) bool {
+	for _, allowedTarget := range rule.AllowedTargetStates {
+		if definition.StateKey(allowedTarget) == targetKey {
+			return true
+		}
+	}
+
+	return false
+}
+
+func denied(code, reason string, rule *structs.StateTransitionRule) Decision {
+	return Decision{
+		Allowed:      false,
+		ReasonCode:   code,
+		Reason:       reason,
+		MatchingRule: rule,
+		FailedGuards: make([]structs.TransitionGuard, 0),
+	}
+}
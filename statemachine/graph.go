@@ -0,0 +1,120 @@
+package statemachine
+
+import (
+	"fmt"
+	"orion.misc/structs"
+	"strings"
+)
+
+const (
+	GraphFormatDot     = "DOT"
+	GraphFormatMermaid = "MERMAID"
+	GraphFormatJson    = "JSON"
+)
+
+// BuildGraph turns the definition into a directed graph. Targets of rules that do not exist as states
+// are added as undefined nodes so they stay visible.
+func (definition Definition) BuildGraph() structs.StateMachineGraph {
+	graph := structs.StateMachineGraph{
+		ReferencedType: definition.ReferencedType,
+		Nodes:          make([]structs.StateMachineGraphNode, 0),
+		Edges:          make([]structs.StateMachineGraphEdge, 0),
+	}
+	known := make(map[string]bool)
+	addNode := func(reference string) {
+		key := definition.StateKey(reference)
+		if known[key] {
+			return
+		}
+		known[key] = true
+		node := structs.StateMachineGraphNode{ID: key, Name: definition.StateName(reference)}
+		if state := definition.FindState(reference); state != nil {
+			node.Defined = true
+			node.DefaultState = state.DefaultState
+			node.Substate = state.Substate
+		}
+		graph.Nodes = append(graph.Nodes, node)
+	}
+
+	for _, state := range definition.States {
+		addNode(definition.stateReference(state))
+	}
+	for _, rule := range definition.Rules {
+		addNode(rule.SourceState)
+		ruleId := ruleIdOf(rule)
+		for _, target := range rule.AllowedTargetStates {
+			addNode(target)
+			graph.Edges = append(graph.Edges, structs.StateMachineGraphEdge{
+				Source: definition.StateKey(rule.SourceState),
+				Target: definition.StateKey(target),
+				RuleId: ruleId,
+			})
+		}
+	}
+
+	return graph
+}
+
+// RenderDot renders the graph in the Graphviz DOT language
+func RenderDot(graph structs.StateMachineGraph) string {
+	aliases := nodeAliases(graph)
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("digraph %v {\n", quote(graph.ReferencedType)))
+	builder.WriteString("\trankdir=LR;\n")
+	for _, node := range graph.Nodes {
+		attributes := []string{fmt.Sprintf("label=%v", quote(node.Name))}
+		if node.DefaultState {
+			attributes = append(attributes, "peripheries=2")
+		}
+		if node.Substate {
+			attributes = append(attributes, "style=dashed")
+		}
+		if !node.Defined {
+			attributes = append(attributes, "color=red")
+		}
+		builder.WriteString(fmt.Sprintf("\t%v [%v];\n", aliases[node.ID], strings.Join(attributes, ", ")))
+	}
+	for _, edge := range graph.Edges {
+		builder.WriteString(fmt.Sprintf("\t%v -> %v;\n", aliases[edge.Source], aliases[edge.Target]))
+	}
+	builder.WriteString("}\n")
+
+	return builder.String()
+}
+
+// RenderMermaid renders the graph as a Mermaid state diagram
+func RenderMermaid(graph structs.StateMachineGraph) string {
+	aliases := nodeAliases(graph)
+	var builder strings.Builder
+	builder.WriteString("stateDiagram-v2\n")
+	for _, node := range graph.Nodes {
+		builder.WriteString(fmt.Sprintf("\t%v : %v\n", aliases[node.ID], strings.ReplaceAll(node.Name, ":", " ")))
+		if node.DefaultState {
+			builder.WriteString(fmt.Sprintf("\t[*] --> %v\n", aliases[node.ID]))
+		}
+		if node.Substate {
+			builder.WriteString(fmt.Sprintf("\tnote right of %v : substate\n", aliases[node.ID]))
+		}
+		if !node.Defined {
+			builder.WriteString(fmt.Sprintf("\tnote right of %v : undefined state\n", aliases[node.ID]))
+		}
+	}
+	for _, edge := range graph.Edges {
+		builder.WriteString(fmt.Sprintf("\t%v --> %v\n", aliases[edge.Source], aliases[edge.Target]))
+	}
+
+	return builder.String()
+}
+
+func nodeAliases(graph structs.StateMachineGraph) map[string]string {
+	aliases := make(map[string]string, len(graph.Nodes))
+	for idx, node := range graph.Nodes {
+		aliases[node.ID] = fmt.Sprintf("s%d", idx)
+	}
+
+	return aliases
+}
+
+func quote(value string) string {
+	return "\"" + strings.ReplaceAll(value, "\"", "\\\"") + "\""
+}
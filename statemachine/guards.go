@@ -0,0 +1,104 @@
+package statemachine
+
+import (
+	"fmt"
+	"orion.misc/structs"
+	"strings"
+)
+
+const (
+	GuardAttributeSet    = "ATTRIBUTE_SET"
+	GuardAttributeEquals = "ATTRIBUTE_EQUALS"
+	GuardCommentRequired = "COMMENT_REQUIRED"
+	GuardUserRole        = "USER_ROLE"
+	GuardUser            = "USER"
+)
+
+// TransitionInput is everything the evaluator needs to decide about a transition
+type TransitionInput struct {
+	CurrentState string
+	TargetState  string
+	Object       map[string]interface{}
+	User         string
+	// UserRoles are looked up on the server for User, they are never taken from the request
+	UserRoles []string
+	Comment   string
+}
+
+func (definition Definition) guardsForTarget(rule structs.StateTransitionRule, targetState string) []structs.TransitionGuard {
+	targetKey := definition.StateKey(targetState)
+	guards := make([]structs.TransitionGuard, 0)
+	for _, guard := range rule.Guards {
+		if len(guard.TargetState) == 0 || definition.StateKey(guard.TargetState) == targetKey {
+			guards = append(guards, guard)
+		}
+	}
+
+	return guards
+}
+
+// checkGuard returns an empty string if the guard holds and the reason why it does not otherwise
+func checkGuard(guard structs.TransitionGuard, input TransitionInput) string {
+	switch strings.ToUpper(guard.Type) {
+	case GuardAttributeSet:
+		value, ok := lookupAttribute(input.Object, guard.Attribute)
+		if !ok || value == nil || len(fmt.Sprintf("%v", value)) == 0 {
+			return fmt.Sprintf("attribute %v must be set", guard.Attribute)
+		}
+	case GuardAttributeEquals:
+		value, ok := lookupAttribute(input.Object, guard.Attribute)
+		if guard.Value == nil {
+			if ok && value != nil {
+				return fmt.Sprintf("attribute %v must not be set", guard.Attribute)
+			}
+			return ""
+		}
+		if !ok || value == nil || fmt.Sprintf("%v", value) != *guard.Value {
+			return fmt.Sprintf("attribute %v must be %v", guard.Attribute, *guard.Value)
+		}
+	case GuardCommentRequired:
+		if len(strings.TrimSpace(input.Comment)) == 0 {
+			return "a comment must be provided"
+		}
+	case GuardUserRole:
+		for _, role := range guard.Roles {
+			for _, userRole := range input.UserRoles {
+				if strings.EqualFold(role, userRole) {
+					return ""
+				}
+			}
+		}
+		return fmt.Sprintf("user %v needs one of the roles %v", input.User, strings.Join(guard.Roles, ", "))
+	case GuardUser:
+		for _, user := range guard.Users {
+			if user == input.User {
+				return ""
+			}
+		}
+		return fmt.Sprintf("user %v is not allowed to perform this transition", input.User)
+	default:
+		return fmt.Sprintf("unknown guard type %v", guard.Type)
+	}
+
+	return ""
+}
+
+// lookupAttribute resolves a possibly dotted attribute path in the object snapshot
+func lookupAttribute(object map[string]interface{}, path string) (interface{}, bool) {
+	if object == nil || len(path) == 0 {
+		return nil, false
+	}
+	var current interface{} = object
+	for _, part := range strings.Split(path, ".") {
+		asMap, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = asMap[part]
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return current, true
+}
@@ -0,0 +1,36 @@
+package statemachine
+
+import (
+	"testing"
+
+	"orion.misc/structs"
+)
+
+func TestEvaluateUserRoleGuard(t *testing.T) {
+	definition := NewDefinition("TICKET", nil, []structs.StateTransitionRule{
+		{ReferencedType: "TICKET", SourceState: "OPEN", AllowedTargetStates: []string{"APPROVED"},
+			Guards: []structs.TransitionGuard{{Type: GuardUserRole, Roles: []string{"APPROVER", "ADMIN"}}}},
+	})
+
+	tests := []struct {
+		name    string
+		roles   []string
+		allowed bool
+	}{
+		{"user with the role", []string{"APPROVER"}, true},
+		{"roles are compared case insensitively", []string{"editor", "admin"}, true},
+		{"user without the role", []string{"EDITOR"}, false},
+		{"user without roles", []string{}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			decision := definition.Evaluate(TransitionInput{CurrentState: "OPEN", TargetState: "APPROVED", User: "jdoe", UserRoles: test.roles})
+			if decision.Allowed != test.allowed {
+				t.Fatalf("Allowed = %v, want %v: %v", decision.Allowed, test.allowed, decision.Reason)
+			}
+			if !decision.Allowed && decision.ReasonCode != ReasonGuardFailed {
+				t.Fatalf("ReasonCode = %v, want %v", decision.ReasonCode, ReasonGuardFailed)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package statemachine
+
+import (
+	"fmt"
+	"orion.misc/structs"
+)
+
+const (
+	ReferenceRuleSource       = "TRANSITION_RULE_SOURCE"
+	ReferenceRuleTarget       = "TRANSITION_RULE_TARGET"
+	ReferenceGuardTarget      = "TRANSITION_GUARD_TARGET"
+	ReferenceOnlyDefaultState = "ONLY_DEFAULT_STATE"
+)
+
+// ReferencesTo lists everything in the definition that still depends on the state. References to transition rules
+// can be resolved by cascading, being the only default state of a referenced type that has other states cannot.
+func (definition Definition) ReferencesTo(reference string) []structs.StateReference {
+	key := definition.StateKey(reference)
+	name := definition.StateName(reference)
+	references := make([]structs.StateReference, 0)
+
+	for _, rule := range definition.Rules {
+		ruleId := ruleIdOf(rule)
+		if definition.StateKey(rule.SourceState) == key {
+			references = append(references, definition.reference(ReferenceRuleSource, ruleId, true,
+				fmt.Sprintf("state %v is the source state of a transition rule", name)))
+		}
+		if definition.allowsTarget(rule, key) {
+			references = append(references, definition.reference(ReferenceRuleTarget, ruleId, true,
+				fmt.Sprintf("state %v is an allowed target of the rule for %v", name, definition.StateName(rule.SourceState))))
+		}
+		for _, guard := range rule.Guards {
+			if len(guard.TargetState) > 0 && definition.StateKey(guard.TargetState) == key {
+				references = append(references, definition.reference(ReferenceGuardTarget, ruleId, true,
+					fmt.Sprintf("state %v is the target of a %v guard of the rule for %v", name, guard.Type, definition.StateName(rule.SourceState))))
+			}
+		}
+	}
+
+	state := definition.FindState(reference)
+	if state != nil && state.DefaultState && len(definition.States) > 1 {
+		defaultStates := 0
+		for _, other := range definition.States {
+			if other.DefaultState {
+				defaultStates++
+			}
+		}
+		if defaultStates == 1 {
+			references = append(references, definition.reference(ReferenceOnlyDefaultState, nil, false,
+				fmt.Sprintf("state %v is the only default state of %v", name, definition.ReferencedType)))
+		}
+	}
+
+	return references
+}
+
+// RemoveState returns the rules that have to be deleted because the state is their source and the rules that have
+// to be updated because the state is one of their targets
+func (definition Definition) RemoveState(reference string) ([]structs.StateTransitionRule, []structs.StateTransitionRule) {
+	key := definition.StateKey(reference)
+	deleted := make([]structs.StateTransitionRule, 0)
+	updated := make([]structs.StateTransitionRule, 0)
+
+	for _, rule := range definition.Rules {
+		if definition.StateKey(rule.SourceState) == key {
+			deleted = append(deleted, rule)
+			continue
+		}
+		changed := false
+		targets := make([]string, 0, len(rule.AllowedTargetStates))
+		for _, target := range rule.AllowedTargetStates {
+			if definition.StateKey(target) == key {
+				changed = true
+				continue
+			}
+			targets = append(targets, target)
+		}
+		guards := make([]structs.TransitionGuard, 0, len(rule.Guards))
+		for _, guard := range rule.Guards {
+			if len(guard.TargetState) > 0 && definition.StateKey(guard.TargetState) == key {
+				changed = true
+				continue
+			}
+			guards = append(guards, guard)
+		}
+		if changed {
+			rule.AllowedTargetStates = targets
+			rule.Guards = guards
+			updated = append(updated, rule)
+		}
+	}
+
+	return deleted, updated
+}
+
+func (definition Definition) reference(referenceType string, ruleId *string, cascadable bool, message string) structs.StateReference {
+	return structs.StateReference{
+		Type:           referenceType,
+		ReferencedType: definition.ReferencedType,
+		RuleId:         ruleId,
+		Message:        message,
+		Cascadable:     cascadable,
+	}
+}
+
+func ruleIdOf(rule structs.StateTransitionRule) *string {
+	if rule.ID == nil {
+		return nil
+	}
+	id := rule.ID.Hex()
+
+	return &id
+}
@@ -0,0 +1,50 @@
+package statemachine
+
+import (
+	"orion.misc/structs"
+)
+
+// Simulate replays state sequences against the definition with the same evaluator that is used for live
+// transitions. Every step is evaluated, even after a rejected one, so all problems of a path are reported.
+func (definition Definition) Simulate(paths []structs.SimulationPath, user string, userRoles []string, validateInitialState bool) []structs.SimulationResult {
+	results := make([]structs.SimulationResult, 0, len(paths))
+	for idx, path := range paths {
+		result := structs.SimulationResult{
+			Index:    idx,
+			ObjectId: path.ObjectId,
+			Accepted: true,
+			Steps:    make([]structs.SimulationStep, 0, len(path.States)),
+		}
+		if validateInitialState && len(path.States) > 0 {
+			addSimulationStep(&result, "", path.States[0], definition.EvaluateInitialState(path.States[0]))
+		}
+		for step := 1; step < len(path.States); step++ {
+			decision := definition.Evaluate(TransitionInput{
+				CurrentState: path.States[step-1],
+				TargetState:  path.States[step],
+				Object:       path.Object,
+				User:         user,
+				UserRoles:    userRoles,
+				Comment:      path.Comment,
+			})
+			addSimulationStep(&result, path.States[step-1], path.States[step], decision)
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
+func addSimulationStep(result *structs.SimulationResult, source, target string, decision Decision) {
+	result.Steps = append(result.Steps, structs.SimulationStep{
+		SourceState:  source,
+		TargetState:  target,
+		Allowed:      decision.Allowed,
+		ReasonCode:   decision.ReasonCode,
+		Reason:       decision.Reason,
+		FailedGuards: decision.FailedGuards,
+	})
+	if !decision.Allowed {
+		result.Accepted = false
+	}
+}
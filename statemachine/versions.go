@@ -0,0 +1,38 @@
+package statemachine
+
+import (
+	"orion.misc/structs"
+)
+
+// SelectVersion returns the version that was effective at the given time (in milliseconds). Objects that are
+// older than the first version fall back to the first version. It returns nil if no version was published.
+func SelectVersion(versions []structs.StateMachineVersion, at int64) *structs.StateMachineVersion {
+	var selected *structs.StateMachineVersion
+	var earliest *structs.StateMachineVersion
+	for idx, version := range versions {
+		if earliest == nil || version.EffectiveFrom < earliest.EffectiveFrom ||
+			(version.EffectiveFrom == earliest.EffectiveFrom && version.Version < earliest.Version) {
+			earliest = &versions[idx]
+		}
+		if version.EffectiveFrom > at {
+			continue
+		}
+		if selected == nil || version.EffectiveFrom > selected.EffectiveFrom ||
+			(version.EffectiveFrom == selected.EffectiveFrom && version.Version > selected.Version) {
+			selected = &versions[idx]
+		}
+	}
+	if selected == nil {
+		return earliest
+	}
+
+	return selected
+}
+
+// NewDefinitionFromVersion builds the state machine of a published version
+func NewDefinitionFromVersion(version structs.StateMachineVersion) Definition {
+	definition := NewDefinition(version.ReferencedType, version.States, version.Rules)
+	definition.Version = version.Version
+
+	return definition
+}
@@ -8,19 +8,187 @@ import (
 type StateTransitionRule struct {
 	ID                  *primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
 	Info                structs.BaseInfo    `bson:"info" json:"info"`
+	ReferencedType      string              `bson:"referenced_type" json:"referenced_type"`
 	SourceState         string              `bson:"source_state" json:"source_state"`
 	AllowedTargetStates []string            `bson:"allowed_target_states" json:"allowed_target_states"`
+	Terminal            bool                `bson:"terminal" json:"terminal"`
+	Guards              []TransitionGuard   `bson:"guards" json:"guards"`
+	// MaxDwellSeconds limits how long an object may stay in the source state before it is escalated
+	MaxDwellSeconds       *int64  `bson:"max_dwell_seconds" json:"max_dwell_seconds"`
+	EscalationTargetState *string `bson:"escalation_target_state" json:"escalation_target_state"`
+	AutoTransition        bool    `bson:"auto_transition" json:"auto_transition"`
 }
 
+// TransitionGuard is a condition that must hold before the transition to TargetState is allowed.
+// A guard without a target state applies to all allowed target states of its rule.
+type TransitionGuard struct {
+	TargetState string   `bson:"target_state" json:"target_state"`
+	Type        string   `bson:"type" json:"type"`
+	Attribute   string   `bson:"attribute" json:"attribute"`
+	Value       *string  `bson:"value" json:"value"`
+	Roles       []string `bson:"roles" json:"roles"`
+	Users       []string `bson:"users" json:"users"`
+	Description string   `bson:"description" json:"description"`
+}
+
+// StateDefinition is the part of a state document that is needed to build the state machine of a referenced type
+type StateDefinition struct {
+	ID             *primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	Info           structs.BaseInfo    `bson:"info" json:"info"`
+	ReferencedType string              `bson:"referenced_type" json:"referenced_type"`
+	Substate       bool                `bson:"substate" json:"substate"`
+	DefaultState   bool                `bson:"default_state" json:"default_state"`
+}
+
+// AttributeDefinition is an attribute definition document of the attribute_definitions collection. It embeds the
+// definition of orion.commons inline, so documents and JSON keep every field of it, and adds the fields that only
+// this module uses.
+type AttributeDefinition struct {
+	structs.AttributeDefinition `bson:",inline"`
+	// Kind is either STORED (the default) or COMPUTED, computed attributes are calculated from Expression on read
+	Kind       string  `bson:"kind" json:"kind"`
+	Expression *string `bson:"expression" json:"expression"`
+	// CreationQuery is an expression over the fields of a created object that yields the value assigned during its
+	// creation, e.g. concat(info.name, "-1"). Query of orion.commons keeps its former meaning.
+	CreationQuery *string `bson:"creation_query" json:"creation_query"`
+	// DependsOn restricts the list of values depending on the value of another attribute of the same object
+	DependsOn *ValueDependency `bson:"depends_on" json:"depends_on"`
+	// Cardinality is either SINGLE (the default) or MULTIPLE, multi-valued attributes hold their values in Values
+	Cardinality string `bson:"cardinality" json:"cardinality"`
+	// Localizations holds the names, descriptions and list of values labels by locale, e.g. "de" or "en"
+	Localizations map[string]AttributeLocalization `bson:"localizations" json:"localizations"`
+	// Labels are resolved for the locale of a request and are never stored
+	Labels *AttributeLabels `bson:"-" json:"labels,omitempty"`
+}
+
+type AttributeLocalization struct {
+	Name        string  `bson:"name" json:"name"`
+	Description *string `bson:"description" json:"description"`
+	// ListOfValues maps the values of the list of values to their labels
+	ListOfValues map[string]string `bson:"list_of_values" json:"list_of_values"`
+}
+
+type AttributeLabels struct {
+	Locale       string             `json:"locale"`
+	Name         string             `json:"name"`
+	Description  *string            `json:"description"`
+	ListOfValues []ListOfValueLabel `json:"list_of_values"`
+}
+
+type ListOfValueLabel struct {
+	Value string `json:"value"`
+	Label string `json:"label"`
+}
+
+// ValueDependency maps the values of a parent attribute, referenced by id or name, to the values that are allowed
+// for the dependent attribute
+type ValueDependency struct {
+	ParentAttribute string            `bson:"parent_attribute" json:"parent_attribute"`
+	Values          []DependentValues `bson:"values" json:"values"`
+}
+
+type DependentValues struct {
+	ParentValue string   `bson:"parent_value" json:"parent_value"`
+	Values      []string `bson:"values" json:"values"`
+}
+
+// AttributeValue is the value of an attribute that was set on a single object
+type AttributeValue struct {
+	ID          *primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	AttributeId string              `bson:"attribute_id" json:"attribute_id"`
+	ObjectType  string              `bson:"object_type" json:"object_type"`
+	ObjectId    string              `bson:"object_id" json:"object_id"`
+	Value       string              `bson:"value" json:"value"`
+	Version     int                 `bson:"version" json:"version"`
+	CreatedDate int64               `bson:"created_date" json:"created_date"`
+	ChangeDate  *int64              `bson:"change_date" json:"change_date"`
+	User        *string             `bson:"user" json:"user"`
+	UserComment *string             `bson:"user_comment" json:"user_comment"`
+	// Values holds the values of multi-valued attributes, Value then contains them as a JSON array
+	Values []string `bson:"values,omitempty" json:"values,omitempty"`
+	// TypedValue is the value converted according to the datatype of the attribute, it is used for searching
+	TypedValue interface{} `bson:"typed_value,omitempty" json:"-"`
+}
+
+// AttributeValidationError describes why a value was rejected by the validation of its attribute definition
+type AttributeValidationError struct {
+	AttributeId string `json:"attribute_id"`
+	ObjectType  string `json:"object_type"`
+	ObjectId    string `json:"object_id"`
+	Value       string `json:"value"`
+	Code        string `json:"code"`
+	Message     string `json:"message"`
+}
+
+// AttributeSearchCondition combines predicates and nested conditions either with AND or with OR
+type AttributeSearchCondition struct {
+	Combinator string                     `json:"combinator"`
+	Predicates []AttributeSearchPredicate `json:"predicates"`
+	Conditions []AttributeSearchCondition `json:"conditions"`
+}
+
+// AttributeSearchPredicate matches objects by the value of an attribute. Value is used by EQ and PREFIX,
+// From and To by RANGE (both bounds are inclusive and optional) and Values by IN.
+type AttributeSearchPredicate struct {
+	AttributeId string   `json:"attribute_id"`
+	Operator    string   `json:"operator"`
+	Value       *string  `json:"value"`
+	From        *string  `json:"from"`
+	To          *string  `json:"to"`
+	Values      []string `json:"values"`
+}
+
+type AttributeSearchResult struct {
+	ObjectId string           `json:"object_id"`
+	Values   []AttributeValue `json:"values"`
+}
+
+// AttributeImportRow is the result of a single row of an attribute value import
+type AttributeImportRow struct {
+	// Row is the number of the record in the CSV data, starting with 1
+	Row              int                        `json:"row"`
+	ObjectType       string                     `json:"object_type"`
+	ObjectId         string                     `json:"object_id"`
+	Attribute        string                     `json:"attribute"`
+	Value            string                     `json:"value"`
+	Status           string                     `json:"status"`
+	Message          *string                    `json:"message"`
+	ValidationErrors []AttributeValidationError `json:"validation_errors"`
+}
+
+// HierarchyLevel identifies the object on a level of a hierarchy that supplied an evaluated attribute value
+type HierarchyLevel struct {
+	HierarchyId string `json:"hierarchy_id,omitempty"`
+	Index       int    `json:"index"`
+	ObjectType  string `json:"object_type"`
+	ObjectId    string `json:"object_id"`
+}
+
+// AttributeChange is sent with AttributeValueChangedEvent. AttributeId is the hex id of the attribute definition and
+// ObjectId the id of the object as a string, both were numbers while the values were stored in SQL.
 type AttributeChange struct {
-	AttributeId   uint64
+	AttributeId   string
 	ObjectType    string
-	ObjectId      uint64
+	ObjectId      string
 	ObjectVersion int
 	OriginalValue string
 	NewValue      string
 }
 
+// AttributeValueChange is the archived record of a change of an attribute value. NewValue is nil if the value was deleted.
+type AttributeValueChange struct {
+	ID            *primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	AttributeId   string              `bson:"attribute_id" json:"attribute_id"`
+	ObjectType    string              `bson:"object_type" json:"object_type"`
+	ObjectId      string              `bson:"object_id" json:"object_id"`
+	ObjectVersion int                 `bson:"object_version" json:"object_version"`
+	OriginalValue *string             `bson:"original_value" json:"original_value"`
+	NewValue      *string             `bson:"new_value" json:"new_value"`
+	User          string              `bson:"user" json:"user"`
+	Comment       string              `bson:"comment" json:"comment"`
+	Timestamp     int64               `bson:"timestamp" json:"timestamp"`
+}
+
 type Hierarchy struct {
 	ID      *primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
 	Info    structs.BaseInfo    `bson:"info" json:"info"`
@@ -39,10 +207,150 @@ type HierarchyEntry struct {
 	ObjectType string              `bson:"object_type" json:"object_type"`
 }
 
+// HierarchyResolution is the position of an object type within a hierarchy. Ancestors are ordered from the direct
+// parent up to the root, descendants from the direct child down to the leaf.
+type HierarchyResolution struct {
+	HierarchyId string           `json:"hierarchy_id"`
+	Name        string           `json:"name"`
+	Index       int              `json:"index"`
+	Ancestors   []HierarchyEntry `json:"ancestors"`
+	Descendants []HierarchyEntry `json:"descendants"`
+}
+
+// HierarchyValidationError describes an entry of a hierarchy that was rejected, Entry is the position of the entry
+// within the entries of the saved hierarchy
+type HierarchyValidationError struct {
+	HierarchyId   string `json:"hierarchy_id,omitempty"`
+	HierarchyName string `json:"hierarchy_name"`
+	Entry         int    `json:"entry"`
+	ObjectType    string `json:"object_type"`
+	Index         int    `json:"index"`
+	Code          string `json:"code"`
+	Message       string `json:"message"`
+}
+
+// HierarchyNode links an object into a tree of object instances. Path holds the ids of the ancestor nodes from
+// the root down to the parent, so that subtrees can be read with a single query.
+type HierarchyNode struct {
+	ID           *primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	HierarchyId  string              `bson:"hierarchy_id" json:"hierarchy_id"`
+	ObjectType   string              `bson:"object_type" json:"object_type"`
+	ObjectId     string              `bson:"object_id" json:"object_id"`
+	ParentId     *string             `bson:"parent_id" json:"parent_id"`
+	Path         []string            `bson:"path" json:"path"`
+	Depth        int                 `bson:"depth" json:"depth"`
+	CreatedDate  int64               `bson:"created_date" json:"created_date"`
+	ChangeDate   *int64              `bson:"change_date" json:"change_date"`
+	DeletionDate *int64              `bson:"deletion_date,omitempty" json:"deletion_date,omitempty"`
+	User         *string             `bson:"user" json:"user"`
+	UserComment  *string             `bson:"user_comment" json:"user_comment"`
+}
+
+// HierarchyNodeReference identifies the node of an object within a tree
+type HierarchyNodeReference struct {
+	ObjectType string `json:"object_type"`
+	ObjectId   string `json:"object_id"`
+}
+
+type HierarchyTreeNode struct {
+	Node     HierarchyNode       `json:"node"`
+	Children []HierarchyTreeNode `json:"children"`
+}
+
 type Parameter struct {
 	ID    *primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
 	Info  structs.BaseInfo    `bson:"info" json:"info"`
 	Value string              `bson:"value" json:"value"`
+	// Type is one of INT, FLOAT, BOOL, DURATION, STRING (the default), ENUM or JSON
+	Type        string                `bson:"type" json:"type"`
+	Constraints *ParameterConstraints `bson:"constraints" json:"constraints"`
+	// TypedValue is the value converted according to the type, it is resolved on read and never stored
+	TypedValue interface{} `bson:"-" json:"typed_value"`
+	// Scope restricts the value to an application, an instance or a user. Parameters without a scope are global.
+	Scope *ParameterScope `bson:"scope" json:"scope"`
+}
+
+// ParameterScope tells where the value of a parameter applies. Level is one of GLOBAL, APPLICATION, INSTANCE or USER.
+// Application is required for APPLICATION and INSTANCE, Instance (the general.applicationId of a module) for INSTANCE
+// and User for USER. A scope with an environment only applies to that environment.
+type ParameterScope struct {
+	Level       string  `bson:"level" json:"level"`
+	Environment *string `bson:"environment" json:"environment"`
+	Application *string `bson:"application" json:"application"`
+	Instance    *int    `bson:"instance" json:"instance"`
+	User        *string `bson:"user" json:"user"`
+}
+
+// ParameterContext describes for whom a parameter is resolved
+type ParameterContext struct {
+	Environment *string `json:"environment"`
+	Application *string `json:"application"`
+	Instance    *int    `json:"instance"`
+	User        *string `json:"user"`
+}
+
+// ParameterOverride is a value of a parameter that applies to a context, Effective marks the one that won
+type ParameterOverride struct {
+	Level     string    `json:"level"`
+	Effective bool      `json:"effective"`
+	Parameter Parameter `json:"parameter"`
+}
+
+// ParameterResolution holds the effective value of a parameter and every override that applies, the most specific first
+type ParameterResolution struct {
+	Name       string              `json:"name"`
+	Value      string              `json:"value"`
+	TypedValue interface{}         `json:"typed_value"`
+	Level      string              `json:"level"`
+	Chain      []ParameterOverride `json:"chain"`
+}
+
+// ParameterConstraints restrict the values of a parameter. Min and Max apply to numbers and to durations in
+// milliseconds, Pattern to strings, AllowedValues to every type but JSON and Schema, a JSON schema, to JSON values.
+type ParameterConstraints struct {
+	Min           *float64 `bson:"min" json:"min"`
+	Max           *float64 `bson:"max" json:"max"`
+	Pattern       *string  `bson:"pattern" json:"pattern"`
+	AllowedValues []string `bson:"allowed_values" json:"allowed_values"`
+	Schema        *string  `bson:"schema" json:"schema"`
+}
+
+// ParameterValidationError describes why the value or the constraints of a parameter were rejected
+type ParameterValidationError struct {
+	Parameter string `json:"parameter"`
+	Value     string `json:"value"`
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+}
+
+// AttributeGroup arranges attribute definitions for display. Groups without object types apply to all object types.
+type AttributeGroup struct {
+	ID   *primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	Info structs.BaseInfo    `bson:"info" json:"info"`
+	// Attributes holds the ids of the attribute definitions in the order they are displayed
+	Attributes   []string `bson:"attributes" json:"attributes"`
+	DisplayOrder int      `bson:"display_order" json:"display_order"`
+	Collapsible  bool     `bson:"collapsible" json:"collapsible"`
+	// Collapsed tells whether a collapsible group is initially displayed collapsed
+	Collapsed   bool     `bson:"collapsed" json:"collapsed"`
+	ObjectTypes []string `bson:"object_types" json:"object_types"`
+}
+
+// AttributeLayout is the resolved display layout of the attributes of an object type
+type AttributeLayout struct {
+	ObjectType string                 `json:"object_type"`
+	Groups     []AttributeLayoutGroup `json:"groups"`
+	// Ungrouped holds the attributes of the object type that are not member of any of its groups
+	Ungrouped []AttributeDefinition `json:"ungrouped"`
+}
+
+type AttributeLayoutGroup struct {
+	GroupId      string                `json:"group_id"`
+	Name         string                `json:"name"`
+	DisplayOrder int                   `json:"display_order"`
+	Collapsible  bool                  `json:"collapsible"`
+	Collapsed    bool                  `json:"collapsed"`
+	Attributes   []AttributeDefinition `json:"attributes"`
 }
 
 type Category struct {
@@ -64,3 +372,113 @@ type ObjectTypeCustomization struct {
 	User              *string             `bson:"user" json:"user"`
 	ChangeDate        *int64              `bson:"change_date" json:"change_date"`
 }
+
+type StateMachineGraph struct {
+	ReferencedType string                  `json:"referenced_type"`
+	Nodes          []StateMachineGraphNode `json:"nodes"`
+	Edges          []StateMachineGraphEdge `json:"edges"`
+}
+
+type StateMachineGraphNode struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	DefaultState bool   `json:"default_state"`
+	Substate     bool   `json:"substate"`
+	Defined      bool   `json:"defined"`
+}
+
+type StateMachineGraphEdge struct {
+	Source string  `json:"source"`
+	Target string  `json:"target"`
+	RuleId *string `json:"rule_id"`
+}
+
+type StateMachineFinding struct {
+	Code           string  `json:"code"`
+	ReferencedType string  `json:"referenced_type"`
+	State          *string `json:"state"`
+	TargetState    *string `json:"target_state"`
+	RuleId         *string `json:"rule_id"`
+	Message        string  `json:"message"`
+}
+
+type StateMachineAnalysis struct {
+	ReferencedType string                `json:"referenced_type"`
+	Valid          bool                  `json:"valid"`
+	Findings       []StateMachineFinding `json:"findings"`
+}
+
+type StateReference struct {
+	Type           string  `json:"type"`
+	ReferencedType string  `json:"referenced_type"`
+	RuleId         *string `json:"rule_id"`
+	Message        string  `json:"message"`
+	Cascadable     bool    `json:"cascadable"`
+}
+
+type ObjectState struct {
+	ID            *primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	ObjectType    string              `bson:"object_type" json:"object_type"`
+	ObjectId      string              `bson:"object_id" json:"object_id"`
+	CurrentState  string              `bson:"current_state" json:"current_state"`
+	EnteredDate   int64               `bson:"entered_date" json:"entered_date"`
+	User          string              `bson:"user" json:"user"`
+	EscalatedDate *int64              `bson:"escalated_date,omitempty" json:"escalated_date,omitempty"`
+	CreatedDate   int64               `bson:"created_date" json:"created_date"`
+}
+
+type ObjectStateTransition struct {
+	ID          *primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	ObjectType  string              `bson:"object_type" json:"object_type"`
+	ObjectId    string              `bson:"object_id" json:"object_id"`
+	SourceState string              `bson:"source_state" json:"source_state"`
+	TargetState string              `bson:"target_state" json:"target_state"`
+	RuleId      *string             `bson:"rule_id" json:"rule_id"`
+	User        string              `bson:"user" json:"user"`
+	Comment     string              `bson:"comment" json:"comment"`
+	Timestamp   int64               `bson:"timestamp" json:"timestamp"`
+}
+
+// StateMachineVersion is an immutable snapshot of the states and transition rules of a referenced type
+type StateMachineVersion struct {
+	ID             *primitive.ObjectID   `bson:"_id,omitempty" json:"_id,omitempty"`
+	Info           structs.BaseInfo      `bson:"info" json:"info"`
+	ReferencedType string                `bson:"referenced_type" json:"referenced_type"`
+	Version        int                   `bson:"version" json:"version"`
+	EffectiveFrom  int64                 `bson:"effective_from" json:"effective_from"`
+	States         []StateDefinition     `bson:"states" json:"states"`
+	Rules          []StateTransitionRule `bson:"rules" json:"rules"`
+}
+
+// UserRoles holds the roles of a user that USER_ROLE guards are checked against, they are maintained with the
+// SaveUserRolesAction in the user_roles collection
+type UserRoles struct {
+	User        string   `bson:"user" json:"user"`
+	Roles       []string `bson:"roles" json:"roles"`
+	ChangeDate  *int64   `bson:"change_date" json:"change_date"`
+	ChangedBy   *string  `bson:"changed_by" json:"changed_by"`
+	UserComment *string  `bson:"user_comment" json:"user_comment"`
+}
+
+type SimulationPath struct {
+	ObjectId string                 `json:"object_id"`
+	States   []string               `json:"states"`
+	Object   map[string]interface{} `json:"object"`
+	Comment  string                 `json:"comment"`
+}
+
+type SimulationStep struct {
+	SourceState  string            `json:"source_state"`
+	TargetState  string            `json:"target_state"`
+	Allowed      bool              `json:"allowed"`
+	ReasonCode   string            `json:"reason_code"`
+	Reason       string            `json:"reason"`
+	FailedGuards []TransitionGuard `json:"failed_guards"`
+}
+
+type SimulationResult struct {
+	Index    int              `json:"index"`
+	ObjectId string           `json:"object_id"`
+	Accepted bool             `json:"accepted"`
+	Steps    []SimulationStep `json:"steps"`
+}
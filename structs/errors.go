@@ -0,0 +1,7 @@
+package structs
+
+// Error codes of ORION.Misc that are not covered by the error codes of orion.commons
+const (
+	ValidationError = 4000 + iota
+	ReferentialIntegrityError
+)
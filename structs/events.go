@@ -38,6 +38,7 @@ func (event SavedStateTransitionRulesEvent) GetHeader() micro.EventHeader {
 	return event.Header
 }
 
+// AttributeDefinitionSavedEvent carries the definitions of orion.commons, other modules read it
 type AttributeDefinitionSavedEvent struct {
 	Header               micro.EventHeader             `json:"event_header"`
 	ObjectType           string                        `json:"object_type"`
@@ -69,10 +70,13 @@ func (event AttributeValueChangedEvent) GetHeader() micro.EventHeader {
 	return event.Header
 }
 
+// AttributeValueDeletedEvent identifies the deleted value by the hex id of its attribute definition and the id and type
+// of its object. The ids were numbers while the values were stored in SQL.
 type AttributeValueDeletedEvent struct {
 	Header      micro.EventHeader `json:"event_header"`
-	AttributeId uint64            `json:"attribute_id"`
-	ObjectId    uint64            `json:"object_id"`
+	AttributeId string            `json:"attribute_id"`
+	ObjectType  string            `json:"object_type"`
+	ObjectId    string            `json:"object_id"`
 }
 
 func (event AttributeValueDeletedEvent) ToJsonString() (string, error) {
@@ -163,3 +167,117 @@ func (event ObjectTypeCustomizationsSavedEvent) ToJsonString() (string, error) {
 func (event ObjectTypeCustomizationsSavedEvent) GetHeader() micro.EventHeader {
 	return event.Header
 }
+
+type StateChangedEvent struct {
+	Header      micro.EventHeader `json:"event_header"`
+	ObjectType  string            `json:"object_type"`
+	ObjectId    string            `json:"object_id"`
+	SourceState string            `json:"source_state"`
+	TargetState string            `json:"target_state"`
+	User        string            `json:"user"`
+	Comment     string            `json:"comment"`
+	Timestamp   int64             `json:"timestamp"`
+}
+
+func (event StateChangedEvent) ToJsonString() (string, error) {
+	byteWurst, err := json.Marshal(event)
+
+	return string(byteWurst), err
+}
+
+func (event StateChangedEvent) GetHeader() micro.EventHeader {
+	return event.Header
+}
+
+type StateEscalationEvent struct {
+	Header                micro.EventHeader `json:"event_header"`
+	ObjectType            string            `json:"object_type"`
+	ObjectId              string            `json:"object_id"`
+	State                 string            `json:"state"`
+	EnteredDate           int64             `json:"entered_date"`
+	MaxDwellSeconds       int64             `json:"max_dwell_seconds"`
+	EscalationTargetState *string           `json:"escalation_target_state"`
+	AutoTransitioned      bool              `json:"auto_transitioned"`
+	Reason                string            `json:"reason"`
+}
+
+func (event StateEscalationEvent) ToJsonString() (string, error) {
+	byteWurst, err := json.Marshal(event)
+
+	return string(byteWurst), err
+}
+
+func (event StateEscalationEvent) GetHeader() micro.EventHeader {
+	return event.Header
+}
+
+type StateMachineVersionPublishedEvent struct {
+	Header         micro.EventHeader `json:"event_header"`
+	ReferencedType string            `json:"referenced_type"`
+	Version        int               `json:"version"`
+	EffectiveFrom  int64             `json:"effective_from"`
+}
+
+func (event StateMachineVersionPublishedEvent) ToJsonString() (string, error) {
+	byteWurst, err := json.Marshal(event)
+
+	return string(byteWurst), err
+}
+
+func (event StateMachineVersionPublishedEvent) GetHeader() micro.EventHeader {
+	return event.Header
+}
+
+type AttributeGroupSavedEvent struct {
+	Header          micro.EventHeader `json:"event_header"`
+	ObjectType      string            `json:"object_type"`
+	AttributeGroups []AttributeGroup  `json:"attribute_groups"`
+}
+
+func (event AttributeGroupSavedEvent) ToJsonString() (string, error) {
+	byteWurst, err := json.Marshal(event)
+
+	return string(byteWurst), err
+}
+
+func (event AttributeGroupSavedEvent) GetHeader() micro.EventHeader {
+	return event.Header
+}
+
+type UserRolesSavedEvent struct {
+	Header    micro.EventHeader `json:"event_header"`
+	UserRoles []UserRoles       `json:"user_roles"`
+}
+
+func (event UserRolesSavedEvent) ToJsonString() (string, error) {
+	byteWurst, err := json.Marshal(event)
+
+	return string(byteWurst), err
+}
+
+func (event UserRolesSavedEvent) GetHeader() micro.EventHeader {
+	return event.Header
+}
+
+// HierarchyNodeChangedEvent is published for every structural change of a tree of objects. Nodes holds the nodes
+// that were attached, detached or moved, including the descendants of the node.
+type HierarchyNodeChangedEvent struct {
+	Header           micro.EventHeader `json:"event_header"`
+	Change           string            `json:"change"`
+	HierarchyId      string            `json:"hierarchy_id"`
+	ObjectType       string            `json:"object_type"`
+	ObjectId         string            `json:"object_id"`
+	OriginalParentId *string           `json:"original_parent_id"`
+	NewParentId      *string           `json:"new_parent_id"`
+	Nodes            []HierarchyNode   `json:"nodes"`
+}
+
+func (event HierarchyNodeChangedEvent) ToJsonString() (string, error) {
+	byteWurst, err := json.Marshal(event)
+
+	return string(byteWurst), err
+}
+
+func (event HierarchyNodeChangedEvent) GetHeader() micro.EventHeader {
+	return event.Header
+}
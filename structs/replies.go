@@ -34,8 +34,8 @@ func (reply GetStatesReply) GetHeader() *micro.ReplyHeader {
 }
 
 type GetAttributeDefinitionsReply struct {
-	Header               micro.ReplyHeader             `json:"header"`
-	AttributeDefinitions []structs.AttributeDefinition `json:"data"`
+	Header               micro.ReplyHeader     `json:"header"`
+	AttributeDefinitions []AttributeDefinition `json:"data"`
 }
 
 func (reply GetAttributeDefinitionsReply) MarshalJSON() (string, error) {
@@ -61,8 +61,8 @@ func (reply GetAttributeDefinitionsReply) GetHeader() *micro.ReplyHeader {
 }
 
 type GetAttributeValuesReply struct {
-	Header     micro.ReplyHeader   `json:"header"`
-	Attributes []structs.Attribute `json:"data"`
+	Header     micro.ReplyHeader `json:"header"`
+	Attributes []AttributeValue  `json:"data"`
 }
 
 func (reply GetAttributeValuesReply) MarshalJSON() (string, error) {
@@ -117,6 +117,8 @@ func (reply GetHierarchiesReply) GetHeader() *micro.ReplyHeader {
 type EvaluateAttributeReply struct {
 	Header micro.ReplyHeader `json:"header"`
 	Value  *string           `json:"value"`
+	Source string            `json:"source"`
+	Level  *HierarchyLevel   `json:"level"`
 }
 
 func (reply EvaluateAttributeReply) MarshalJSON() (string, error) {
@@ -275,3 +277,631 @@ func (reply GetStateTransitionRulesReply) Error() string {
 func (reply GetStateTransitionRulesReply) GetHeader() *micro.ReplyHeader {
 	return &reply.Header
 }
+
+type ValidateStateTransitionReply struct {
+	Header       micro.ReplyHeader    `json:"header"`
+	Allowed      bool                 `json:"allowed"`
+	ReasonCode   string               `json:"reason_code"`
+	Reason       string               `json:"reason"`
+	MatchingRule *StateTransitionRule `json:"matching_rule"`
+	FailedGuards []TransitionGuard    `json:"failed_guards"`
+	// StateMachineVersion is the published version that was used or 0 if the live rules were used
+	StateMachineVersion int `json:"state_machine_version"`
+}
+
+func (reply ValidateStateTransitionReply) MarshalJSON() (string, error) {
+	bytes, err := json.Marshal(reply)
+
+	return string(bytes), err
+}
+
+func (reply ValidateStateTransitionReply) Successful() bool {
+	return reply.Header.Success
+}
+
+func (reply ValidateStateTransitionReply) Error() string {
+	if reply.Header.ErrorMessage != nil {
+		return *reply.Header.ErrorMessage
+	}
+
+	return ""
+}
+
+func (reply ValidateStateTransitionReply) GetHeader() *micro.ReplyHeader {
+	return &reply.Header
+}
+
+type GetStateMachineGraphReply struct {
+	Header  micro.ReplyHeader  `json:"header"`
+	Graph   *StateMachineGraph `json:"graph"`
+	Dot     *string            `json:"dot"`
+	Mermaid *string            `json:"mermaid"`
+}
+
+func (reply GetStateMachineGraphReply) MarshalJSON() (string, error) {
+	bytes, err := json.Marshal(reply)
+
+	return string(bytes), err
+}
+
+func (reply GetStateMachineGraphReply) Successful() bool {
+	return reply.Header.Success
+}
+
+func (reply GetStateMachineGraphReply) Error() string {
+	if reply.Header.ErrorMessage != nil {
+		return *reply.Header.ErrorMessage
+	}
+
+	return ""
+}
+
+func (reply GetStateMachineGraphReply) GetHeader() *micro.ReplyHeader {
+	return &reply.Header
+}
+
+type AnalyzeStateMachinesReply struct {
+	Header   micro.ReplyHeader      `json:"header"`
+	Analyses []StateMachineAnalysis `json:"data"`
+}
+
+func (reply AnalyzeStateMachinesReply) MarshalJSON() (string, error) {
+	bytes, err := json.Marshal(reply)
+
+	return string(bytes), err
+}
+
+func (reply AnalyzeStateMachinesReply) Successful() bool {
+	return reply.Header.Success
+}
+
+func (reply AnalyzeStateMachinesReply) Error() string {
+	if reply.Header.ErrorMessage != nil {
+		return *reply.Header.ErrorMessage
+	}
+
+	return ""
+}
+
+func (reply AnalyzeStateMachinesReply) GetHeader() *micro.ReplyHeader {
+	return &reply.Header
+}
+
+type DeleteStateReply struct {
+	Header             micro.ReplyHeader `json:"header"`
+	BlockingReferences []StateReference  `json:"blocking_references"`
+	CascadedRules      []string          `json:"cascaded_rules"`
+}
+
+func (reply DeleteStateReply) MarshalJSON() (string, error) {
+	bytes, err := json.Marshal(reply)
+
+	return string(bytes), err
+}
+
+func (reply DeleteStateReply) Successful() bool {
+	return reply.Header.Success
+}
+
+func (reply DeleteStateReply) Error() string {
+	if reply.Header.ErrorMessage != nil {
+		return *reply.Header.ErrorMessage
+	}
+
+	return ""
+}
+
+func (reply DeleteStateReply) GetHeader() *micro.ReplyHeader {
+	return &reply.Header
+}
+
+type ApplyStateTransitionReply struct {
+	Header       micro.ReplyHeader      `json:"header"`
+	Allowed      bool                   `json:"allowed"`
+	ReasonCode   string                 `json:"reason_code"`
+	Reason       string                 `json:"reason"`
+	FailedGuards []TransitionGuard      `json:"failed_guards"`
+	Transition   *ObjectStateTransition `json:"transition"`
+}
+
+func (reply ApplyStateTransitionReply) MarshalJSON() (string, error) {
+	bytes, err := json.Marshal(reply)
+
+	return string(bytes), err
+}
+
+func (reply ApplyStateTransitionReply) Successful() bool {
+	return reply.Header.Success
+}
+
+func (reply ApplyStateTransitionReply) Error() string {
+	if reply.Header.ErrorMessage != nil {
+		return *reply.Header.ErrorMessage
+	}
+
+	return ""
+}
+
+func (reply ApplyStateTransitionReply) GetHeader() *micro.ReplyHeader {
+	return &reply.Header
+}
+
+type GetObjectStateReply struct {
+	Header       micro.ReplyHeader       `json:"header"`
+	CurrentState *ObjectState            `json:"current_state"`
+	History      []ObjectStateTransition `json:"history"`
+}
+
+func (reply GetObjectStateReply) MarshalJSON() (string, error) {
+	bytes, err := json.Marshal(reply)
+
+	return string(bytes), err
+}
+
+func (reply GetObjectStateReply) Successful() bool {
+	return reply.Header.Success
+}
+
+func (reply GetObjectStateReply) Error() string {
+	if reply.Header.ErrorMessage != nil {
+		return *reply.Header.ErrorMessage
+	}
+
+	return ""
+}
+
+func (reply GetObjectStateReply) GetHeader() *micro.ReplyHeader {
+	return &reply.Header
+}
+
+type GetStateMachineVersionsReply struct {
+	Header   micro.ReplyHeader     `json:"header"`
+	Versions []StateMachineVersion `json:"data"`
+}
+
+func (reply GetStateMachineVersionsReply) MarshalJSON() (string, error) {
+	bytes, err := json.Marshal(reply)
+
+	return string(bytes), err
+}
+
+func (reply GetStateMachineVersionsReply) Successful() bool {
+	return reply.Header.Success
+}
+
+func (reply GetStateMachineVersionsReply) Error() string {
+	if reply.Header.ErrorMessage != nil {
+		return *reply.Header.ErrorMessage
+	}
+
+	return ""
+}
+
+func (reply GetStateMachineVersionsReply) GetHeader() *micro.ReplyHeader {
+	return &reply.Header
+}
+
+type SimulateStateTransitionsReply struct {
+	Header        micro.ReplyHeader `json:"header"`
+	AcceptedPaths int               `json:"accepted_paths"`
+	RejectedPaths int               `json:"rejected_paths"`
+	// StateMachineVersion is the published version that was simulated, 0 for the live or draft rules
+	StateMachineVersion int                `json:"state_machine_version"`
+	Results             []SimulationResult `json:"data"`
+}
+
+func (reply SimulateStateTransitionsReply) MarshalJSON() (string, error) {
+	bytes, err := json.Marshal(reply)
+
+	return string(bytes), err
+}
+
+func (reply SimulateStateTransitionsReply) Successful() bool {
+	return reply.Header.Success
+}
+
+func (reply SimulateStateTransitionsReply) Error() string {
+	if reply.Header.ErrorMessage != nil {
+		return *reply.Header.ErrorMessage
+	}
+
+	return ""
+}
+
+func (reply SimulateStateTransitionsReply) GetHeader() *micro.ReplyHeader {
+	return &reply.Header
+}
+
+type GetAttributeValueChangeHistoryReply struct {
+	Header  micro.ReplyHeader      `json:"header"`
+	Changes []AttributeValueChange `json:"data"`
+}
+
+func (reply GetAttributeValueChangeHistoryReply) MarshalJSON() (string, error) {
+	bytes, err := json.Marshal(reply)
+
+	return string(bytes), err
+}
+
+func (reply GetAttributeValueChangeHistoryReply) Successful() bool {
+	return reply.Header.Success
+}
+
+func (reply GetAttributeValueChangeHistoryReply) Error() string {
+	if reply.Header.ErrorMessage != nil {
+		return *reply.Header.ErrorMessage
+	}
+
+	return ""
+}
+
+func (reply GetAttributeValueChangeHistoryReply) GetHeader() *micro.ReplyHeader {
+	return &reply.Header
+}
+
+type SetAttributeValueReply struct {
+	Header           micro.ReplyHeader          `json:"header"`
+	ValidationErrors []AttributeValidationError `json:"validation_errors"`
+}
+
+func (reply SetAttributeValueReply) MarshalJSON() (string, error) {
+	bytes, err := json.Marshal(reply)
+
+	return string(bytes), err
+}
+
+func (reply SetAttributeValueReply) Successful() bool {
+	return reply.Header.Success
+}
+
+func (reply SetAttributeValueReply) Error() string {
+	if reply.Header.ErrorMessage != nil {
+		return *reply.Header.ErrorMessage
+	}
+
+	return ""
+}
+
+func (reply SetAttributeValueReply) GetHeader() *micro.ReplyHeader {
+	return &reply.Header
+}
+
+type GetEffectiveListOfValuesReply struct {
+	Header          micro.ReplyHeader `json:"header"`
+	Values          []string          `json:"data"`
+	Restricted      bool              `json:"restricted"`
+	ParentAttribute *string           `json:"parent_attribute"`
+	ParentValue     *string           `json:"parent_value"`
+}
+
+func (reply GetEffectiveListOfValuesReply) MarshalJSON() (string, error) {
+	bytes, err := json.Marshal(reply)
+
+	return string(bytes), err
+}
+
+func (reply GetEffectiveListOfValuesReply) Successful() bool {
+	return reply.Header.Success
+}
+
+func (reply GetEffectiveListOfValuesReply) Error() string {
+	if reply.Header.ErrorMessage != nil {
+		return *reply.Header.ErrorMessage
+	}
+
+	return ""
+}
+
+func (reply GetEffectiveListOfValuesReply) GetHeader() *micro.ReplyHeader {
+	return &reply.Header
+}
+
+type SearchObjectsByAttributesReply struct {
+	Header     micro.ReplyHeader       `json:"header"`
+	Objects    []AttributeSearchResult `json:"data"`
+	Page       int                     `json:"page"`
+	PageSize   int                     `json:"page_size"`
+	TotalCount int                     `json:"total_count"`
+}
+
+func (reply SearchObjectsByAttributesReply) MarshalJSON() (string, error) {
+	bytes, err := json.Marshal(reply)
+
+	return string(bytes), err
+}
+
+func (reply SearchObjectsByAttributesReply) Successful() bool {
+	return reply.Header.Success
+}
+
+func (reply SearchObjectsByAttributesReply) Error() string {
+	if reply.Header.ErrorMessage != nil {
+		return *reply.Header.ErrorMessage
+	}
+
+	return ""
+}
+
+func (reply SearchObjectsByAttributesReply) GetHeader() *micro.ReplyHeader {
+	return &reply.Header
+}
+
+type GetAttributeGroupsReply struct {
+	Header          micro.ReplyHeader `json:"header"`
+	AttributeGroups []AttributeGroup  `json:"data"`
+}
+
+func (reply GetAttributeGroupsReply) MarshalJSON() (string, error) {
+	bytes, err := json.Marshal(reply)
+
+	return string(bytes), err
+}
+
+func (reply GetAttributeGroupsReply) Successful() bool {
+	return reply.Header.Success
+}
+
+func (reply GetAttributeGroupsReply) Error() string {
+	if reply.Header.ErrorMessage != nil {
+		return *reply.Header.ErrorMessage
+	}
+
+	return ""
+}
+
+func (reply GetAttributeGroupsReply) GetHeader() *micro.ReplyHeader {
+	return &reply.Header
+}
+
+type GetAttributeLayoutReply struct {
+	Header micro.ReplyHeader `json:"header"`
+	Layout AttributeLayout   `json:"data"`
+}
+
+func (reply GetAttributeLayoutReply) MarshalJSON() (string, error) {
+	bytes, err := json.Marshal(reply)
+
+	return string(bytes), err
+}
+
+func (reply GetAttributeLayoutReply) Successful() bool {
+	return reply.Header.Success
+}
+
+func (reply GetAttributeLayoutReply) Error() string {
+	if reply.Header.ErrorMessage != nil {
+		return *reply.Header.ErrorMessage
+	}
+
+	return ""
+}
+
+func (reply GetAttributeLayoutReply) GetHeader() *micro.ReplyHeader {
+	return &reply.Header
+}
+
+type ImportAttributeValuesReply struct {
+	Header       micro.ReplyHeader    `json:"header"`
+	Rows         []AttributeImportRow `json:"data"`
+	ValidateOnly bool                 `json:"validate_only"`
+	ValidRows    int                  `json:"valid_rows"`
+	InvalidRows  int                  `json:"invalid_rows"`
+	AppliedRows  int                  `json:"applied_rows"`
+	FailedRows   int                  `json:"failed_rows"`
+}
+
+func (reply ImportAttributeValuesReply) MarshalJSON() (string, error) {
+	bytes, err := json.Marshal(reply)
+
+	return string(bytes), err
+}
+
+func (reply ImportAttributeValuesReply) Successful() bool {
+	return reply.Header.Success
+}
+
+func (reply ImportAttributeValuesReply) Error() string {
+	if reply.Header.ErrorMessage != nil {
+		return *reply.Header.ErrorMessage
+	}
+
+	return ""
+}
+
+func (reply ImportAttributeValuesReply) GetHeader() *micro.ReplyHeader {
+	return &reply.Header
+}
+
+type ResolveHierarchiesReply struct {
+	Header      micro.ReplyHeader     `json:"header"`
+	Hierarchies []HierarchyResolution `json:"data"`
+}
+
+func (reply ResolveHierarchiesReply) MarshalJSON() (string, error) {
+	bytes, err := json.Marshal(reply)
+
+	return string(bytes), err
+}
+
+func (reply ResolveHierarchiesReply) Successful() bool {
+	return reply.Header.Success
+}
+
+func (reply ResolveHierarchiesReply) Error() string {
+	if reply.Header.ErrorMessage != nil {
+		return *reply.Header.ErrorMessage
+	}
+
+	return ""
+}
+
+func (reply ResolveHierarchiesReply) GetHeader() *micro.ReplyHeader {
+	return &reply.Header
+}
+
+// SaveHierarchiesErrorReply is sent on the error topic if hierarchy entries failed the validation, successful saves
+// are still answered with a plain reply header
+type SaveHierarchiesErrorReply struct {
+	Header           micro.ReplyHeader          `json:"header"`
+	ValidationErrors []HierarchyValidationError `json:"validation_errors"`
+}
+
+func (reply SaveHierarchiesErrorReply) MarshalJSON() (string, error) {
+	bytes, err := json.Marshal(reply)
+
+	return string(bytes), err
+}
+
+func (reply SaveHierarchiesErrorReply) Successful() bool {
+	return reply.Header.Success
+}
+
+func (reply SaveHierarchiesErrorReply) Error() string {
+	if reply.Header.ErrorMessage != nil {
+		return *reply.Header.ErrorMessage
+	}
+
+	return ""
+}
+
+func (reply SaveHierarchiesErrorReply) GetHeader() *micro.ReplyHeader {
+	return &reply.Header
+}
+
+type AttachHierarchyNodeReply struct {
+	Header micro.ReplyHeader `json:"header"`
+	Node   HierarchyNode     `json:"data"`
+}
+
+func (reply AttachHierarchyNodeReply) MarshalJSON() (string, error) {
+	bytes, err := json.Marshal(reply)
+
+	return string(bytes), err
+}
+
+func (reply AttachHierarchyNodeReply) Successful() bool {
+	return reply.Header.Success
+}
+
+func (reply AttachHierarchyNodeReply) Error() string {
+	if reply.Header.ErrorMessage != nil {
+		return *reply.Header.ErrorMessage
+	}
+
+	return ""
+}
+
+func (reply AttachHierarchyNodeReply) GetHeader() *micro.ReplyHeader {
+	return &reply.Header
+}
+
+type GetHierarchySubtreeReply struct {
+	Header micro.ReplyHeader `json:"header"`
+	Tree   HierarchyTreeNode `json:"data"`
+}
+
+func (reply GetHierarchySubtreeReply) MarshalJSON() (string, error) {
+	bytes, err := json.Marshal(reply)
+
+	return string(bytes), err
+}
+
+func (reply GetHierarchySubtreeReply) Successful() bool {
+	return reply.Header.Success
+}
+
+func (reply GetHierarchySubtreeReply) Error() string {
+	if reply.Header.ErrorMessage != nil {
+		return *reply.Header.ErrorMessage
+	}
+
+	return ""
+}
+
+func (reply GetHierarchySubtreeReply) GetHeader() *micro.ReplyHeader {
+	return &reply.Header
+}
+
+type GetHierarchyAncestorsReply struct {
+	Header micro.ReplyHeader `json:"header"`
+	// Ancestors are ordered from the root down to the parent of the object
+	Ancestors []HierarchyNode `json:"data"`
+}
+
+func (reply GetHierarchyAncestorsReply) MarshalJSON() (string, error) {
+	bytes, err := json.Marshal(reply)
+
+	return string(bytes), err
+}
+
+func (reply GetHierarchyAncestorsReply) Successful() bool {
+	return reply.Header.Success
+}
+
+func (reply GetHierarchyAncestorsReply) Error() string {
+	if reply.Header.ErrorMessage != nil {
+		return *reply.Header.ErrorMessage
+	}
+
+	return ""
+}
+
+func (reply GetHierarchyAncestorsReply) GetHeader() *micro.ReplyHeader {
+	return &reply.Header
+}
+
+// SaveParametersErrorReply is sent on the error topic if parameter values failed the validation, successful saves
+// are still answered with a plain reply header
+type SaveParametersErrorReply struct {
+	Header           micro.ReplyHeader          `json:"header"`
+	ValidationErrors []ParameterValidationError `json:"validation_errors"`
+}
+
+func (reply SaveParametersErrorReply) MarshalJSON() (string, error) {
+	bytes, err := json.Marshal(reply)
+
+	return string(bytes), err
+}
+
+func (reply SaveParametersErrorReply) Successful() bool {
+	return reply.Header.Success
+}
+
+func (reply SaveParametersErrorReply) Error() string {
+	if reply.Header.ErrorMessage != nil {
+		return *reply.Header.ErrorMessage
+	}
+
+	return ""
+}
+
+func (reply SaveParametersErrorReply) GetHeader() *micro.ReplyHeader {
+	return &reply.Header
+}
+
+type ResolveParameterReply struct {
+	Header     micro.ReplyHeader   `json:"header"`
+	Resolution ParameterResolution `json:"data"`
+}
+
+func (reply ResolveParameterReply) MarshalJSON() (string, error) {
+	bytes, err := json.Marshal(reply)
+
+	return string(bytes), err
+}
+
+func (reply ResolveParameterReply) Successful() bool {
+	return reply.Header.Success
+}
+
+func (reply ResolveParameterReply) Error() string {
+	if reply.Header.ErrorMessage != nil {
+		return *reply.Header.ErrorMessage
+	}
+
+	return ""
+}
+
+func (reply ResolveParameterReply) GetHeader() *micro.ReplyHeader {
+	return &reply.Header
+}
@@ -71,6 +71,7 @@ func (request GetStatesRequest) GetHeader() *micro.RequestHeader {
 type SaveStateTransitionRulesRequest struct {
 	Header                      micro.RequestHeader   `json:"header"`
 	UpdatedStateTransitionRules []StateTransitionRule `json:"updated_state_transition_rules"`
+	RejectNewFindings           bool                  `json:"reject_new_findings"`
 }
 
 func (request *SaveStateTransitionRulesRequest) UpdateHeader(header *micro.RequestHeader) {
@@ -100,8 +101,8 @@ func (request SaveStateTransitionRulesRequest) ToString() (string, error) {
 }
 
 type DefineAttributeRequest struct {
-	Header                      micro.RequestHeader           `json:"header"`
-	UpdatedAttributeDefinitions []structs.AttributeDefinition `json:"updated_attribute_definitions"`
+	Header                      micro.RequestHeader   `json:"header"`
+	UpdatedAttributeDefinitions []AttributeDefinition `json:"updated_attribute_definitions"`
 }
 
 func (request *DefineAttributeRequest) UpdateHeader(header *micro.RequestHeader) {
@@ -133,6 +134,8 @@ func (request DefineAttributeRequest) ToString() (string, error) {
 type GetAttributeDefinitionsRequest struct {
 	Header      micro.RequestHeader `json:"header"`
 	WhereClause *string             `json:"where_clause"`
+	// Locale, e.g. "de" or "de-AT", for which the labels of the definitions are resolved
+	Locale *string `json:"locale"`
 }
 
 func (request *GetAttributeDefinitionsRequest) UpdateHeader(header *micro.RequestHeader) {
@@ -161,9 +164,11 @@ func (request GetAttributeDefinitionsRequest) GetHeader() *micro.RequestHeader {
 	return &request.Header
 }
 
+// SetAttributeValueRequest carries AttributeValue documents instead of the orion.commons attributes it carried while
+// the values were stored in SQL, the request is not compatible with clients of that version
 type SetAttributeValueRequest struct {
 	Header     micro.RequestHeader `json:"header"`
-	Attributes []structs.Attribute `json:"attributes"`
+	Attributes []AttributeValue    `json:"attributes"`
 }
 
 func (request *SetAttributeValueRequest) UpdateHeader(header *micro.RequestHeader) {
@@ -192,10 +197,13 @@ func (request SetAttributeValueRequest) GetHeader() *micro.RequestHeader {
 	return &request.Header
 }
 
+// DeleteAttributeValueRequest references attribute definitions by their hex id and objects by their type and id.
+// Both ids were numbers while the values were stored in SQL, the request is not compatible with clients of that version.
 type DeleteAttributeValueRequest struct {
 	Header      micro.RequestHeader `json:"header"`
-	AttributeId uint64              `json:"attribute_id"`
-	ObjectId    uint64              `json:"object_id"`
+	AttributeId string              `json:"attribute_id"`
+	ObjectType  string              `json:"object_type"`
+	ObjectId    string              `json:"object_id"`
 }
 
 func (request *DeleteAttributeValueRequest) UpdateHeader(header *micro.RequestHeader) {
@@ -224,10 +232,13 @@ func (request DeleteAttributeValueRequest) GetHeader() *micro.RequestHeader {
 	return &request.Header
 }
 
+// GetAttributeValuesRequest references attribute definitions by their hex id and objects by their type and id.
+// Both ids were numbers while the values were stored in SQL, the request is not compatible with clients of that version.
 type GetAttributeValuesRequest struct {
 	Header      micro.RequestHeader `json:"header"`
-	AttributeId *uint64             `json:"attribute_id"`
-	ObjectId    *uint64             `json:"object_id"`
+	AttributeId *string             `json:"attribute_id"`
+	ObjectType  *string             `json:"object_type"`
+	ObjectId    *string             `json:"object_id"`
 }
 
 func (request *GetAttributeValuesRequest) UpdateHeader(header *micro.RequestHeader) {
@@ -257,8 +268,13 @@ func (request GetAttributeValuesRequest) GetHeader() *micro.RequestHeader {
 }
 
 type GetAttributeValueChangeHistoryRequest struct {
-	Header   micro.RequestHeader `json:"header"`
-	ObjectId uint64              `json:"object_id"`
+	Header      micro.RequestHeader `json:"header"`
+	ObjectType  string              `json:"object_type"`
+	ObjectId    string              `json:"object_id"`
+	AttributeId *string             `json:"attribute_id"`
+	// From and To limit the changes to a time range in milliseconds, both bounds are inclusive
+	From *int64 `json:"from"`
+	To   *int64 `json:"to"`
 }
 
 func (request *GetAttributeValueChangeHistoryRequest) UpdateHeader(header *micro.RequestHeader) {
@@ -351,8 +367,12 @@ func (request GetHierarchiesRequest) GetHeader() *micro.RequestHeader {
 
 type EvaluateAttributeRequest struct {
 	Header      micro.RequestHeader `json:"header"`
-	ObjectId    int64               `json:"object_id"`
-	AttributeId int64               `json:"attribute_id"`
+	ObjectType  string              `json:"object_type"`
+	ObjectId    string              `json:"object_id"`
+	AttributeId string              `json:"attribute_id"`
+	HierarchyId *string             `json:"hierarchy_id"`
+	// Ancestors maps the object types of the upper hierarchy levels to the ids of the object's ancestors
+	Ancestors map[string]string `json:"ancestors"`
 }
 
 func (request *EvaluateAttributeRequest) UpdateHeader(header *micro.RequestHeader) {
@@ -415,6 +435,8 @@ func (request SaveParametersRequest) ToString() (string, error) {
 type GetParametersRequest struct {
 	Header      micro.RequestHeader `json:"header"`
 	WhereClause *string             `json:"where_clause"`
+	// IncludeOverrides returns the values of every scope, by default only the GLOBAL values are returned
+	IncludeOverrides bool `json:"include_overrides"`
 }
 
 func (request *GetParametersRequest) UpdateHeader(header *micro.RequestHeader) {
@@ -659,3 +681,746 @@ func (request *GetStateTransitionRulesRequest) HandleResult(reply micro.IReply)
 func (request GetStateTransitionRulesRequest) GetHeader() *micro.RequestHeader {
 	return &request.Header
 }
+
+type ValidateStateTransitionRequest struct {
+	Header       micro.RequestHeader    `json:"header"`
+	ObjectType   string                 `json:"object_type"`
+	CurrentState string                 `json:"current_state"`
+	TargetState  string                 `json:"target_state"`
+	Object       map[string]interface{} `json:"object"`
+	// ObjectCreatedDate selects the published state machine version that applies to the object
+	ObjectCreatedDate *int64 `json:"object_created_date"`
+}
+
+func (request *ValidateStateTransitionRequest) UpdateHeader(header *micro.RequestHeader) {
+	request.Header = *header
+}
+
+func (request ValidateStateTransitionRequest) ToString() (string, error) {
+	byteWurst, err := json.Marshal(request)
+
+	return string(byteWurst), err
+}
+
+func (request *ValidateStateTransitionRequest) HandleResult(reply micro.IReply) micro.IRequest {
+	header := request.Header
+	header.WasExecutedSuccessfully = reply.Successful()
+	if len(reply.Error()) > 0 {
+		err := reply.Error()
+		header.ExecutionError = &err
+	}
+	request.Header = header
+
+	return request
+}
+
+func (request ValidateStateTransitionRequest) GetHeader() *micro.RequestHeader {
+	return &request.Header
+}
+
+type GetStateMachineGraphRequest struct {
+	Header         micro.RequestHeader `json:"header"`
+	ReferencedType string              `json:"referenced_type"`
+	Formats        []string            `json:"formats"`
+}
+
+func (request *GetStateMachineGraphRequest) UpdateHeader(header *micro.RequestHeader) {
+	request.Header = *header
+}
+
+func (request GetStateMachineGraphRequest) ToString() (string, error) {
+	byteWurst, err := json.Marshal(request)
+
+	return string(byteWurst), err
+}
+
+func (request *GetStateMachineGraphRequest) HandleResult(reply micro.IReply) micro.IRequest {
+	header := request.Header
+	header.WasExecutedSuccessfully = reply.Successful()
+	if len(reply.Error()) > 0 {
+		err := reply.Error()
+		header.ExecutionError = &err
+	}
+	request.Header = header
+
+	return request
+}
+
+func (request GetStateMachineGraphRequest) GetHeader() *micro.RequestHeader {
+	return &request.Header
+}
+
+type AnalyzeStateMachinesRequest struct {
+	Header          micro.RequestHeader `json:"header"`
+	ReferencedTypes []string            `json:"referenced_types"`
+}
+
+func (request *AnalyzeStateMachinesRequest) UpdateHeader(header *micro.RequestHeader) {
+	request.Header = *header
+}
+
+func (request AnalyzeStateMachinesRequest) ToString() (string, error) {
+	byteWurst, err := json.Marshal(request)
+
+	return string(byteWurst), err
+}
+
+func (request *AnalyzeStateMachinesRequest) HandleResult(reply micro.IReply) micro.IRequest {
+	header := request.Header
+	header.WasExecutedSuccessfully = reply.Successful()
+	if len(reply.Error()) > 0 {
+		err := reply.Error()
+		header.ExecutionError = &err
+	}
+	request.Header = header
+
+	return request
+}
+
+func (request AnalyzeStateMachinesRequest) GetHeader() *micro.RequestHeader {
+	return &request.Header
+}
+
+type DeleteStateRequest struct {
+	structs.DeleteRequest
+	Cascade bool `json:"cascade"`
+}
+
+func (request DeleteStateRequest) ToString() (string, error) {
+	byteWurst, err := json.Marshal(request)
+
+	return string(byteWurst), err
+}
+
+type ApplyStateTransitionRequest struct {
+	Header      micro.RequestHeader    `json:"header"`
+	ObjectType  string                 `json:"object_type"`
+	ObjectId    string                 `json:"object_id"`
+	TargetState string                 `json:"target_state"`
+	Object      map[string]interface{} `json:"object"`
+}
+
+func (request *ApplyStateTransitionRequest) UpdateHeader(header *micro.RequestHeader) {
+	request.Header = *header
+}
+
+func (request ApplyStateTransitionRequest) ToString() (string, error) {
+	byteWurst, err := json.Marshal(request)
+
+	return string(byteWurst), err
+}
+
+func (request *ApplyStateTransitionRequest) HandleResult(reply micro.IReply) micro.IRequest {
+	header := request.Header
+	header.WasExecutedSuccessfully = reply.Successful()
+	if len(reply.Error()) > 0 {
+		err := reply.Error()
+		header.ExecutionError = &err
+	}
+	request.Header = header
+
+	return request
+}
+
+func (request ApplyStateTransitionRequest) GetHeader() *micro.RequestHeader {
+	return &request.Header
+}
+
+type GetObjectStateRequest struct {
+	Header         micro.RequestHeader `json:"header"`
+	ObjectType     string              `json:"object_type"`
+	ObjectId       string              `json:"object_id"`
+	IncludeHistory bool                `json:"include_history"`
+}
+
+func (request *GetObjectStateRequest) UpdateHeader(header *micro.RequestHeader) {
+	request.Header = *header
+}
+
+func (request GetObjectStateRequest) ToString() (string, error) {
+	byteWurst, err := json.Marshal(request)
+
+	return string(byteWurst), err
+}
+
+func (request *GetObjectStateRequest) HandleResult(reply micro.IReply) micro.IRequest {
+	header := request.Header
+	header.WasExecutedSuccessfully = reply.Successful()
+	if len(reply.Error()) > 0 {
+		err := reply.Error()
+		header.ExecutionError = &err
+	}
+	request.Header = header
+
+	return request
+}
+
+func (request GetObjectStateRequest) GetHeader() *micro.RequestHeader {
+	return &request.Header
+}
+
+type PublishStateMachineVersionRequest struct {
+	Header         micro.RequestHeader `json:"header"`
+	ReferencedType string              `json:"referenced_type"`
+	EffectiveFrom  *int64              `json:"effective_from"`
+}
+
+func (request *PublishStateMachineVersionRequest) UpdateHeader(header *micro.RequestHeader) {
+	request.Header = *header
+}
+
+func (request PublishStateMachineVersionRequest) ToString() (string, error) {
+	byteWurst, err := json.Marshal(request)
+
+	return string(byteWurst), err
+}
+
+func (request *PublishStateMachineVersionRequest) HandleResult(reply micro.IReply) micro.IRequest {
+	header := request.Header
+	header.WasExecutedSuccessfully = reply.Successful()
+	if len(reply.Error()) > 0 {
+		err := reply.Error()
+		header.ExecutionError = &err
+	}
+	request.Header = header
+
+	return request
+}
+
+func (request PublishStateMachineVersionRequest) GetHeader() *micro.RequestHeader {
+	return &request.Header
+}
+
+type GetStateMachineVersionsRequest struct {
+	Header         micro.RequestHeader `json:"header"`
+	ReferencedType string              `json:"referenced_type"`
+}
+
+func (request *GetStateMachineVersionsRequest) UpdateHeader(header *micro.RequestHeader) {
+	request.Header = *header
+}
+
+func (request GetStateMachineVersionsRequest) ToString() (string, error) {
+	byteWurst, err := json.Marshal(request)
+
+	return string(byteWurst), err
+}
+
+func (request *GetStateMachineVersionsRequest) HandleResult(reply micro.IReply) micro.IRequest {
+	header := request.Header
+	header.WasExecutedSuccessfully = reply.Successful()
+	if len(reply.Error()) > 0 {
+		err := reply.Error()
+		header.ExecutionError = &err
+	}
+	request.Header = header
+
+	return request
+}
+
+func (request GetStateMachineVersionsRequest) GetHeader() *micro.RequestHeader {
+	return &request.Header
+}
+
+type SimulateStateTransitionsRequest struct {
+	Header               micro.RequestHeader   `json:"header"`
+	ReferencedType       string                `json:"referenced_type"`
+	Paths                []SimulationPath      `json:"paths"`
+	DraftRules           []StateTransitionRule `json:"draft_rules"`
+	DraftReplacesRules   bool                  `json:"draft_replaces_rules"`
+	ValidateInitialState bool                  `json:"validate_initial_state"`
+	// ObjectCreatedDate selects the published state machine version that is simulated if there are no draft rules
+	ObjectCreatedDate *int64 `json:"object_created_date"`
+}
+
+func (request *SimulateStateTransitionsRequest) UpdateHeader(header *micro.RequestHeader) {
+	request.Header = *header
+}
+
+func (request SimulateStateTransitionsRequest) ToString() (string, error) {
+	byteWurst, err := json.Marshal(request)
+
+	return string(byteWurst), err
+}
+
+func (request *SimulateStateTransitionsRequest) HandleResult(reply micro.IReply) micro.IRequest {
+	header := request.Header
+	header.WasExecutedSuccessfully = reply.Successful()
+	if len(reply.Error()) > 0 {
+		err := reply.Error()
+		header.ExecutionError = &err
+	}
+	request.Header = header
+
+	return request
+}
+
+func (request SimulateStateTransitionsRequest) GetHeader() *micro.RequestHeader {
+	return &request.Header
+}
+
+type GetEffectiveListOfValuesRequest struct {
+	Header      micro.RequestHeader `json:"header"`
+	AttributeId string              `json:"attribute_id"`
+	ObjectType  *string             `json:"object_type"`
+	ObjectId    *string             `json:"object_id"`
+	// Object holds the values chosen so far by attribute id or name, they take precedence over the stored values
+	Object map[string]string `json:"object"`
+}
+
+func (request *GetEffectiveListOfValuesRequest) UpdateHeader(header *micro.RequestHeader) {
+	request.Header = *header
+}
+
+func (request GetEffectiveListOfValuesRequest) ToString() (string, error) {
+	byteWurst, err := json.Marshal(request)
+
+	return string(byteWurst), err
+}
+
+func (request *GetEffectiveListOfValuesRequest) HandleResult(reply micro.IReply) micro.IRequest {
+	header := request.Header
+	header.WasExecutedSuccessfully = reply.Successful()
+	if len(reply.Error()) > 0 {
+		err := reply.Error()
+		header.ExecutionError = &err
+	}
+	request.Header = header
+
+	return request
+}
+
+func (request GetEffectiveListOfValuesRequest) GetHeader() *micro.RequestHeader {
+	return &request.Header
+}
+
+type SearchObjectsByAttributesRequest struct {
+	Header     micro.RequestHeader      `json:"header"`
+	ObjectType string                   `json:"object_type"`
+	Condition  AttributeSearchCondition `json:"condition"`
+	// Page starts with 1, PageSize defaults to 50
+	Page     int `json:"page"`
+	PageSize int `json:"page_size"`
+}
+
+func (request *SearchObjectsByAttributesRequest) UpdateHeader(header *micro.RequestHeader) {
+	request.Header = *header
+}
+
+func (request SearchObjectsByAttributesRequest) ToString() (string, error) {
+	byteWurst, err := json.Marshal(request)
+
+	return string(byteWurst), err
+}
+
+func (request *SearchObjectsByAttributesRequest) HandleResult(reply micro.IReply) micro.IRequest {
+	header := request.Header
+	header.WasExecutedSuccessfully = reply.Successful()
+	if len(reply.Error()) > 0 {
+		err := reply.Error()
+		header.ExecutionError = &err
+	}
+	request.Header = header
+
+	return request
+}
+
+func (request SearchObjectsByAttributesRequest) GetHeader() *micro.RequestHeader {
+	return &request.Header
+}
+
+type SaveAttributeGroupsRequest struct {
+	Header                 micro.RequestHeader `json:"header"`
+	UpdatedAttributeGroups []AttributeGroup    `json:"updated_attribute_groups"`
+}
+
+func (request *SaveAttributeGroupsRequest) UpdateHeader(header *micro.RequestHeader) {
+	request.Header = *header
+}
+
+func (request SaveAttributeGroupsRequest) ToString() (string, error) {
+	byteWurst, err := json.Marshal(request)
+
+	return string(byteWurst), err
+}
+
+func (request *SaveAttributeGroupsRequest) HandleResult(reply micro.IReply) micro.IRequest {
+	header := request.Header
+	header.WasExecutedSuccessfully = reply.Successful()
+	if len(reply.Error()) > 0 {
+		err := reply.Error()
+		header.ExecutionError = &err
+	}
+	request.Header = header
+
+	return request
+}
+
+func (request SaveAttributeGroupsRequest) GetHeader() *micro.RequestHeader {
+	return &request.Header
+}
+
+type GetAttributeGroupsRequest struct {
+	Header micro.RequestHeader `json:"header"`
+	// ObjectType restricts the groups to the ones that apply to an object type
+	ObjectType *string `json:"object_type"`
+}
+
+func (request *GetAttributeGroupsRequest) UpdateHeader(header *micro.RequestHeader) {
+	request.Header = *header
+}
+
+func (request GetAttributeGroupsRequest) ToString() (string, error) {
+	byteWurst, err := json.Marshal(request)
+
+	return string(byteWurst), err
+}
+
+func (request *GetAttributeGroupsRequest) HandleResult(reply micro.IReply) micro.IRequest {
+	header := request.Header
+	header.WasExecutedSuccessfully = reply.Successful()
+	if len(reply.Error()) > 0 {
+		err := reply.Error()
+		header.ExecutionError = &err
+	}
+	request.Header = header
+
+	return request
+}
+
+func (request GetAttributeGroupsRequest) GetHeader() *micro.RequestHeader {
+	return &request.Header
+}
+
+type GetAttributeLayoutRequest struct {
+	Header     micro.RequestHeader `json:"header"`
+	ObjectType string              `json:"object_type"`
+	// Locale for which the labels of the attributes are resolved
+	Locale *string `json:"locale"`
+}
+
+func (request *GetAttributeLayoutRequest) UpdateHeader(header *micro.RequestHeader) {
+	request.Header = *header
+}
+
+func (request GetAttributeLayoutRequest) ToString() (string, error) {
+	byteWurst, err := json.Marshal(request)
+
+	return string(byteWurst), err
+}
+
+func (request *GetAttributeLayoutRequest) HandleResult(reply micro.IReply) micro.IRequest {
+	header := request.Header
+	header.WasExecutedSuccessfully = reply.Successful()
+	if len(reply.Error()) > 0 {
+		err := reply.Error()
+		header.ExecutionError = &err
+	}
+	request.Header = header
+
+	return request
+}
+
+func (request GetAttributeLayoutRequest) GetHeader() *micro.RequestHeader {
+	return &request.Header
+}
+
+type ImportAttributeValuesRequest struct {
+	Header micro.RequestHeader `json:"header"`
+	// Csv holds rows of object type, object id, attribute name or id and value, a header row is optional
+	Csv string `json:"csv"`
+	// ValidateOnly only validates the rows without applying any of them
+	ValidateOnly bool `json:"validate_only"`
+}
+
+func (request *ImportAttributeValuesRequest) UpdateHeader(header *micro.RequestHeader) {
+	request.Header = *header
+}
+
+func (request ImportAttributeValuesRequest) ToString() (string, error) {
+	byteWurst, err := json.Marshal(request)
+
+	return string(byteWurst), err
+}
+
+func (request *ImportAttributeValuesRequest) HandleResult(reply micro.IReply) micro.IRequest {
+	header := request.Header
+	header.WasExecutedSuccessfully = reply.Successful()
+	if len(reply.Error()) > 0 {
+		err := reply.Error()
+		header.ExecutionError = &err
+	}
+	request.Header = header
+
+	return request
+}
+
+func (request ImportAttributeValuesRequest) GetHeader() *micro.RequestHeader {
+	return &request.Header
+}
+
+type ResolveHierarchiesRequest struct {
+	Header     micro.RequestHeader `json:"header"`
+	ObjectType string              `json:"object_type"`
+}
+
+func (request *ResolveHierarchiesRequest) UpdateHeader(header *micro.RequestHeader) {
+	request.Header = *header
+}
+
+func (request ResolveHierarchiesRequest) ToString() (string, error) {
+	byteWurst, err := json.Marshal(request)
+
+	return string(byteWurst), err
+}
+
+func (request *ResolveHierarchiesRequest) HandleResult(reply micro.IReply) micro.IRequest {
+	header := request.Header
+	header.WasExecutedSuccessfully = reply.Successful()
+	if len(reply.Error()) > 0 {
+		err := reply.Error()
+		header.ExecutionError = &err
+	}
+	request.Header = header
+
+	return request
+}
+
+func (request ResolveHierarchiesRequest) GetHeader() *micro.RequestHeader {
+	return &request.Header
+}
+
+type AttachHierarchyNodeRequest struct {
+	Header      micro.RequestHeader `json:"header"`
+	HierarchyId string              `json:"hierarchy_id"`
+	ObjectType  string              `json:"object_type"`
+	ObjectId    string              `json:"object_id"`
+	// Parent is the node the object is attached to, without parent the object becomes a root of the tree
+	Parent *HierarchyNodeReference `json:"parent"`
+}
+
+func (request *AttachHierarchyNodeRequest) UpdateHeader(header *micro.RequestHeader) {
+	request.Header = *header
+}
+
+func (request AttachHierarchyNodeRequest) ToString() (string, error) {
+	byteWurst, err := json.Marshal(request)
+
+	return string(byteWurst), err
+}
+
+func (request *AttachHierarchyNodeRequest) HandleResult(reply micro.IReply) micro.IRequest {
+	header := request.Header
+	header.WasExecutedSuccessfully = reply.Successful()
+	if len(reply.Error()) > 0 {
+		err := reply.Error()
+		header.ExecutionError = &err
+	}
+	request.Header = header
+
+	return request
+}
+
+func (request AttachHierarchyNodeRequest) GetHeader() *micro.RequestHeader {
+	return &request.Header
+}
+
+type DetachHierarchyNodeRequest struct {
+	Header      micro.RequestHeader `json:"header"`
+	HierarchyId string              `json:"hierarchy_id"`
+	ObjectType  string              `json:"object_type"`
+	ObjectId    string              `json:"object_id"`
+}
+
+func (request *DetachHierarchyNodeRequest) UpdateHeader(header *micro.RequestHeader) {
+	request.Header = *header
+}
+
+func (request DetachHierarchyNodeRequest) ToString() (string, error) {
+	byteWurst, err := json.Marshal(request)
+
+	return string(byteWurst), err
+}
+
+func (request *DetachHierarchyNodeRequest) HandleResult(reply micro.IReply) micro.IRequest {
+	header := request.Header
+	header.WasExecutedSuccessfully = reply.Successful()
+	if len(reply.Error()) > 0 {
+		err := reply.Error()
+		header.ExecutionError = &err
+	}
+	request.Header = header
+
+	return request
+}
+
+func (request DetachHierarchyNodeRequest) GetHeader() *micro.RequestHeader {
+	return &request.Header
+}
+
+type MoveHierarchyNodeRequest struct {
+	Header      micro.RequestHeader `json:"header"`
+	HierarchyId string              `json:"hierarchy_id"`
+	ObjectType  string              `json:"object_type"`
+	ObjectId    string              `json:"object_id"`
+	// NewParent is the node the object is moved to, without parent the object becomes a root of the tree
+	NewParent *HierarchyNodeReference `json:"new_parent"`
+}
+
+func (request *MoveHierarchyNodeRequest) UpdateHeader(header *micro.RequestHeader) {
+	request.Header = *header
+}
+
+func (request MoveHierarchyNodeRequest) ToString() (string, error) {
+	byteWurst, err := json.Marshal(request)
+
+	return string(byteWurst), err
+}
+
+func (request *MoveHierarchyNodeRequest) HandleResult(reply micro.IReply) micro.IRequest {
+	header := request.Header
+	header.WasExecutedSuccessfully = reply.Successful()
+	if len(reply.Error()) > 0 {
+		err := reply.Error()
+		header.ExecutionError = &err
+	}
+	request.Header = header
+
+	return request
+}
+
+func (request MoveHierarchyNodeRequest) GetHeader() *micro.RequestHeader {
+	return &request.Header
+}
+
+type GetHierarchySubtreeRequest struct {
+	Header      micro.RequestHeader `json:"header"`
+	HierarchyId string              `json:"hierarchy_id"`
+	ObjectType  string              `json:"object_type"`
+	ObjectId    string              `json:"object_id"`
+	// MaxDepth limits the number of levels below the object that are returned
+	MaxDepth int `json:"max_depth"`
+}
+
+func (request *GetHierarchySubtreeRequest) UpdateHeader(header *micro.RequestHeader) {
+	request.Header = *header
+}
+
+func (request GetHierarchySubtreeRequest) ToString() (string, error) {
+	byteWurst, err := json.Marshal(request)
+
+	return string(byteWurst), err
+}
+
+func (request *GetHierarchySubtreeRequest) HandleResult(reply micro.IReply) micro.IRequest {
+	header := request.Header
+	header.WasExecutedSuccessfully = reply.Successful()
+	if len(reply.Error()) > 0 {
+		err := reply.Error()
+		header.ExecutionError = &err
+	}
+	request.Header = header
+
+	return request
+}
+
+func (request GetHierarchySubtreeRequest) GetHeader() *micro.RequestHeader {
+	return &request.Header
+}
+
+type GetHierarchyAncestorsRequest struct {
+	Header      micro.RequestHeader `json:"header"`
+	HierarchyId string              `json:"hierarchy_id"`
+	ObjectType  string              `json:"object_type"`
+	ObjectId    string              `json:"object_id"`
+}
+
+func (request *GetHierarchyAncestorsRequest) UpdateHeader(header *micro.RequestHeader) {
+	request.Header = *header
+}
+
+func (request GetHierarchyAncestorsRequest) ToString() (string, error) {
+	byteWurst, err := json.Marshal(request)
+
+	return string(byteWurst), err
+}
+
+func (request *GetHierarchyAncestorsRequest) HandleResult(reply micro.IReply) micro.IRequest {
+	header := request.Header
+	header.WasExecutedSuccessfully = reply.Successful()
+	if len(reply.Error()) > 0 {
+		err := reply.Error()
+		header.ExecutionError = &err
+	}
+	request.Header = header
+
+	return request
+}
+
+func (request GetHierarchyAncestorsRequest) GetHeader() *micro.RequestHeader {
+	return &request.Header
+}
+
+type ResolveParameterRequest struct {
+	Header  micro.RequestHeader `json:"header"`
+	Name    string              `json:"name"`
+	Context ParameterContext    `json:"context"`
+}
+
+func (request *ResolveParameterRequest) UpdateHeader(header *micro.RequestHeader) {
+	request.Header = *header
+}
+
+func (request ResolveParameterRequest) ToString() (string, error) {
+	byteWurst, err := json.Marshal(request)
+
+	return string(byteWurst), err
+}
+
+func (request *ResolveParameterRequest) HandleResult(reply micro.IReply) micro.IRequest {
+	header := request.Header
+	header.WasExecutedSuccessfully = reply.Successful()
+	if len(reply.Error()) > 0 {
+		err := reply.Error()
+		header.ExecutionError = &err
+	}
+	request.Header = header
+
+	return request
+}
+
+func (request ResolveParameterRequest) GetHeader() *micro.RequestHeader {
+	return &request.Header
+}
+
+type SaveUserRolesRequest struct {
+	Header micro.RequestHeader `json:"header"`
+	// UserRoles replaces the roles of every listed user, an empty list of roles removes all roles of a user
+	UserRoles []UserRoles `json:"user_roles"`
+}
+
+func (request *SaveUserRolesRequest) UpdateHeader(header *micro.RequestHeader) {
+	request.Header = *header
+}
+
+func (request SaveUserRolesRequest) ToString() (string, error) {
+	byteWurst, err := json.Marshal(request)
+
+	return string(byteWurst), err
+}
+
+func (request *SaveUserRolesRequest) HandleResult(reply micro.IReply) micro.IRequest {
+	header := request.Header
+	header.WasExecutedSuccessfully = reply.Successful()
+	if len(reply.Error()) > 0 {
+		err := reply.Error()
+		header.ExecutionError = &err
+	}
+	request.Header = header
+
+	return request
+}
+
+func (request SaveUserRolesRequest) GetHeader() *micro.RequestHeader {
+	return &request.Header
+}